@@ -2,8 +2,10 @@ package data
 
 import (
 	"fmt"
+	"image"
 	"io"
 	"math"
+	"time"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -24,6 +26,14 @@ type (
 	LineCap     int
 	LineJoin    int
 	FillRule    int
+	Spread      int
+	Coordinates int
+	TextMethod  int
+	Composite   int
+	BlendMode   int
+	ImageFormat int
+	ImageFit    int
+	TextSpacing int
 	FontVariant uint32
 	Writer      int
 )
@@ -38,10 +48,37 @@ type Canvas interface {
 	Origin() Point
 	Size() Size
 
+	// Set the view box to the bounding box of the elements on the
+	// canvas, with a margin
+	FitViewBox(margin float32) error
+
+	// Return the elements drawn at a point, with the topmost first
+	ElementsAt(Point) []CanvasElement
+
 	// Set canvas properties
 	Title(string) Canvas
+
+	// Set metadata for the canvas from nodes created with the canvas
+	// document, usually in another namespace
+	Metadata(...Node) Canvas
 	Version(string) Canvas
 
+	// Set the style sheet at the head of the canvas, and return the
+	// style sheet rules which match a tag, class, id and state
+	StyleSheet(StyleSheet) Canvas
+	Rules(tag, class, id, state string) []StyleRule
+
+	// Return the properties of an element after applying presentation
+	// attributes, style sheet rules, the style attribute and inheritance
+	ComputedStyle(CanvasElement) map[string]interface{}
+
+	// Return the image embedded in an image element as a data URI
+	DecodeImage(CanvasElement) (image.Image, error)
+
+	// Set the number of decimal places for co-ordinates when
+	// writing with the Optimise flag
+	Precision(uint) Canvas
+
 	// Return canvas as an XML document
 	DOM() Document
 
@@ -57,9 +94,33 @@ type Canvas interface {
 	// Create a group and attach elements to group
 	Group(...CanvasElement) CanvasGroup
 
+	// Create a hyperlink to a URL and attach elements to the link,
+	// with an optional target such as "_blank"
+	Link(href, target string, children ...CanvasElement) CanvasGroup
+
 	// Define a marker and attach elements to marker
 	Marker(Point, Size, ...CanvasElement) CanvasGroup
 
+	// Define a pattern tile and attach elements to the pattern
+	Pattern(Point, Size, ...CanvasElement) CanvasGroup
+
+	// Define a clipping path and attach elements to the clipping path
+	ClipPath(...CanvasElement) CanvasGroup
+
+	// Define a mask and attach elements to the mask
+	Mask(...CanvasElement) CanvasGroup
+
+	// Define a filter and attach filter primitives to the filter
+	Filter(...CanvasFilter) CanvasGroup
+
+	// Define a symbol with a view box and attach elements to the symbol
+	Symbol(Point, Size, ...CanvasElement) CanvasGroup
+
+	// Define gradients with an identifier, which are added to the
+	// canvas definitions
+	LinearGradient(id string, p1, p2 Point) CanvasGradient
+	RadialGradient(id string, centre Point, radius float32) CanvasGradient
+
 	// Drawing primitives
 	Circle(Point, float32) CanvasElement
 	Ellipse(Point, Size) CanvasElement
@@ -69,15 +130,33 @@ type Canvas interface {
 	Polyline(...Point) CanvasElement
 	Polygon(...Point) CanvasElement
 	Text(Point, bool, ...CanvasText) CanvasElement
-	Image(Point, Size, string) CanvasElement
+	Image(Point, Size, string) CanvasImage
+	ImageData(Point, Size, image.Image, ImageFormat) CanvasImage
+	Use(id string, pt Point) CanvasElement
 
 	// Path primitives
 	MoveTo(Point) CanvasPath
 	LineTo(Point) CanvasPath
+	HorizontalTo(x float32) CanvasPath
+	VerticalTo(y float32) CanvasPath
 	QuadraticTo(pt, c Point) CanvasPath
+	SmoothQuadraticTo(pt Point) CanvasPath
 	CubicTo(pt, c1, c2 Point) CanvasPath
+	SmoothCubicTo(pt, c2 Point) CanvasPath
+	ArcTo(pt Point, r Size, angle float32, large, sweep bool) CanvasPath
 	ClosePath() CanvasPath
 
+	// Path primitives relative to the current point
+	MoveToRel(Point) CanvasPath
+	LineToRel(Point) CanvasPath
+	HorizontalToRel(dx float32) CanvasPath
+	VerticalToRel(dy float32) CanvasPath
+	QuadraticToRel(pt, c Point) CanvasPath
+	SmoothQuadraticToRel(pt Point) CanvasPath
+	CubicToRel(pt, c1, c2 Point) CanvasPath
+	SmoothCubicToRel(pt, c2 Point) CanvasPath
+	ArcToRel(pt Point, r Size, angle float32, large, sweep bool) CanvasPath
+
 	// Transform primitives
 	Scale(Size) CanvasTransform
 	Translate(Point) CanvasTransform
@@ -85,20 +164,26 @@ type Canvas interface {
 	RotateAround(float32, Point) CanvasTransform
 	SkewX(float32) CanvasTransform
 	SkewY(float32) CanvasTransform
+	Matrix([6]float32) CanvasTransform
 
 	// Fill styles
 	NoFill() CanvasStyle
 	Fill(Color, float32) CanvasStyle
-	// TODO FillPattern(string) CanvasStyle
+	FillGradient(id string) CanvasStyle
+	FillPattern(id string) CanvasStyle
 	FillRule(FillRule) CanvasStyle
 
 	// Stroke styles
 	NoStroke() CanvasStyle
 	Stroke(Color, float32) CanvasStyle
+	StrokeGradient(id string) CanvasStyle
+	StrokePattern(id string) CanvasStyle
 	StrokeWidth(float32) CanvasStyle
 	LineCap(LineCap) CanvasStyle
 	LineJoin(LineJoin) CanvasStyle
 	MiterLimit(float32) CanvasStyle
+	StrokeDash(...float32) CanvasStyle
+	StrokeDashOffset(float32) CanvasStyle
 
 	// Text styles
 	FontSize(float32, Unit) CanvasStyle
@@ -108,10 +193,50 @@ type Canvas interface {
 
 	// Other styles
 	UseMarker(Align, string) CanvasStyle
+	Clip(id string) CanvasStyle
+	MaskWith(id string) CanvasStyle
+	FilterWith(id string) CanvasStyle
+	DropShadow(offset Point, blur float32, color Color) CanvasStyle
+
+	// Element styles for the opacity of an element and its children,
+	// how the element is blended with elements beneath it, whether the
+	// element is visible and whether the element is displayed at all
+	Opacity(float32) CanvasStyle
+	Blend(BlendMode) CanvasStyle
+	Visibility(bool) CanvasStyle
+	Display(bool) CanvasStyle
+
+	// Animation primitives, which are attached to an element. A
+	// repeat count of zero repeats the animation indefinitely
+	Animate(attr, from, to string, dur time.Duration, repeat uint) CanvasAnimation
+	AnimateTransform(from, to CanvasTransform, dur time.Duration, repeat uint) CanvasAnimation
+	AnimateMotion(dur time.Duration, repeat uint, path ...CanvasPath) CanvasAnimation
+	AnimateSet(attr, to string) CanvasAnimation
+
+	// Filter primitives, where an empty input is the result of the
+	// previous primitive or the source graphic for the first primitive,
+	// and "SourceAlpha" is the alpha channel of the source graphic
+	GaussianBlur(in string, deviation float32) CanvasFilter
+	Offset(in string, delta Point) CanvasFilter
+	ColorMatrix(in string, matrix [20]float32) CanvasFilter
+	Saturate(in string, value float32) CanvasFilter
+	Flood(Color, float32) CanvasFilter
+	Composite(in, in2 string, op Composite) CanvasFilter
+	Merge(in ...string) CanvasFilter
 
 	// Text primitives
 	TextSpan(string) CanvasText
-	TextPath(string) CanvasText // TODO
+	TextPath(id string, value string) CanvasText
+
+	// Register a font, and return the font which best matches a font
+	// family and variant
+	AddFont(Font) error
+	Font(family string, variant FontVariant) Font
+
+	// Measure a string with a font and font size, and return the
+	// bounding box of a text element
+	MeasureText(Font, float32, string) Size
+	TextBounds(CanvasElement) (Point, Size)
 }
 
 type CanvasGroup interface {
@@ -123,20 +248,89 @@ type CanvasGroup interface {
 	// Marker orientation, when not set or zero, uses "auto"
 	OrientationAngle(float32) CanvasGroup
 
+	// Pattern co-ordinate system for the pattern tile
+	PatternUnits(Coordinates) CanvasGroup
+
 	// Add additional elements to group
 	Append(...CanvasElement) CanvasGroup
 }
 
+type CanvasGradient interface {
+	CanvasElement
+
+	// Add a color stop, with offset between 0 and 1
+	Stop(offset float32, color Color, opacity float32) CanvasGradient
+
+	// Set how the gradient is painted outside of the gradient vector
+	SpreadMethod(Spread) CanvasGradient
+
+	// Set the co-ordinate system for the gradient vector
+	GradientUnits(Coordinates) CanvasGradient
+}
+
+type CanvasFilter interface {
+	CanvasElement
+
+	// Name the result of the primitive, so it can be used as an input
+	// to later primitives
+	Result(string) CanvasFilter
+}
+
+type CanvasImage interface {
+	CanvasElement
+
+	// Set how the image is fitted within its width and height, and
+	// aligned when the aspect ratio is preserved
+	PreserveAspectRatio(x, y Align, fit ImageFit) CanvasImage
+}
+
 type CanvasElement interface {
 	Id(string) CanvasElement
 	Class(string) CanvasElement
 	Style(...CanvasStyle) CanvasElement
 	Transform(...CanvasTransform) CanvasElement
+
+	// Attach animations to the element
+	Animation(...CanvasAnimation) CanvasElement
+
+	// Set a title for the element, which is displayed as a tooltip
+	Title(string) CanvasElement
+
+	// Set metadata for the element from nodes created with the
+	// canvas document, usually in another namespace
+	Metadata(...Node) CanvasElement
+
+	// Return the bounding box of the element in canvas co-ordinates
+	Bounds() (Point, Size)
+}
+
+type CanvasAnimation interface {
+	CanvasElement
+
+	// Set the time at which the animation begins and ends, from the
+	// time the document is loaded
+	Begin(time.Duration) CanvasAnimation
+	End(time.Duration) CanvasAnimation
+
+	// Ease between values with cubic bezier control points x1, y1,
+	// x2, y2 between 0 and 1, with one spline for each interval
+	KeySplines(...[4]float32) CanvasAnimation
+
+	// Retain the final value when the animation ends
+	Freeze() CanvasAnimation
 }
 
 type CanvasText interface {
 	Offset(Point) CanvasText
 	Length(float32, Adjust) CanvasText
+
+	// Distance along the path at which text on a path starts, in user
+	// units or as a percentage of the path length
+	StartOffset(float32, bool) CanvasText
+
+	// How glyphs are rendered and spaced along a path
+	PathMethod(TextMethod) CanvasText
+	PathSpacing(TextSpacing) CanvasText
 }
 
 type CanvasPath interface{}
@@ -159,9 +353,12 @@ const (
 )
 
 const (
-	SVG    Writer = 0
-	Minify Writer = (1 << iota) // Do not indent output
-	// TODO: PDF, PNG, etc
+	SVG      Writer = 0
+	Minify   Writer = (1 << iota) // Do not indent output
+	PNG                           // Render as a PNG bitmap
+	PDF                           // Render as a single page PDF document
+	Optimise                      // Reduce the size of SVG output
+	Compress                      // Compress PDF content streams
 )
 
 const (
@@ -175,6 +372,16 @@ const (
 	SpacingAndGlyphs
 )
 
+const (
+	MethodAlign TextMethod = iota
+	MethodStretch
+)
+
+const (
+	SpacingExact TextSpacing = iota
+	SpacingAuto
+)
+
 const (
 	Thin       FontVariant = (1 << iota) // 100
 	ExtraLight                           // 200
@@ -197,6 +404,51 @@ const (
 	EvenOdd
 )
 
+const (
+	SpreadPad Spread = iota
+	SpreadReflect
+	SpreadRepeat
+)
+
+const (
+	ObjectBoundingBox Coordinates = iota
+	UserSpaceOnUse
+)
+
+const (
+	CompositeOver Composite = iota
+	CompositeIn
+	CompositeOut
+	CompositeAtop
+	CompositeXor
+)
+
+const (
+	BlendNormal BlendMode = iota
+	BlendMultiply
+	BlendScreen
+	BlendOverlay
+	BlendDarken
+	BlendLighten
+	BlendColorDodge
+	BlendColorBurn
+	BlendHardLight
+	BlendSoftLight
+	BlendDifference
+	BlendExclusion
+)
+
+const (
+	ImagePNG ImageFormat = iota
+	ImageJPEG
+)
+
+const (
+	FitMeet  ImageFit = iota // Fit the image within the viewport
+	FitSlice                 // Fill the viewport, clipping the image
+	FitNone                  // Stretch the image to fill the viewport
+)
+
 const (
 	CapButt LineCap = iota
 	CapRound
@@ -259,6 +511,28 @@ func (a Adjust) String() string {
 	}
 }
 
+func (m TextMethod) String() string {
+	switch m {
+	case MethodStretch:
+		return "stretch"
+	case MethodAlign:
+		fallthrough
+	default:
+		return "align"
+	}
+}
+
+func (s TextSpacing) String() string {
+	switch s {
+	case SpacingAuto:
+		return "auto"
+	case SpacingExact:
+		fallthrough
+	default:
+		return "exact"
+	}
+}
+
 func (v FontVariant) String() string {
 	switch v {
 	case Thin:
@@ -355,3 +629,99 @@ func (r FillRule) String() string {
 		return "nonzero"
 	}
 }
+
+func (c Composite) String() string {
+	switch c {
+	case CompositeIn:
+		return "in"
+	case CompositeOut:
+		return "out"
+	case CompositeAtop:
+		return "atop"
+	case CompositeXor:
+		return "xor"
+	case CompositeOver:
+		fallthrough
+	default:
+		return "over"
+	}
+}
+
+func (m BlendMode) String() string {
+	switch m {
+	case BlendMultiply:
+		return "multiply"
+	case BlendScreen:
+		return "screen"
+	case BlendOverlay:
+		return "overlay"
+	case BlendDarken:
+		return "darken"
+	case BlendLighten:
+		return "lighten"
+	case BlendColorDodge:
+		return "color-dodge"
+	case BlendColorBurn:
+		return "color-burn"
+	case BlendHardLight:
+		return "hard-light"
+	case BlendSoftLight:
+		return "soft-light"
+	case BlendDifference:
+		return "difference"
+	case BlendExclusion:
+		return "exclusion"
+	case BlendNormal:
+		fallthrough
+	default:
+		return "normal"
+	}
+}
+
+func (f ImageFormat) String() string {
+	switch f {
+	case ImageJPEG:
+		return "image/jpeg"
+	case ImagePNG:
+		fallthrough
+	default:
+		return "image/png"
+	}
+}
+
+func (f ImageFit) String() string {
+	switch f {
+	case FitSlice:
+		return "slice"
+	case FitNone:
+		return "none"
+	case FitMeet:
+		fallthrough
+	default:
+		return "meet"
+	}
+}
+
+func (s Spread) String() string {
+	switch s {
+	case SpreadReflect:
+		return "reflect"
+	case SpreadRepeat:
+		return "repeat"
+	case SpreadPad:
+		fallthrough
+	default:
+		return "pad"
+	}
+}
+
+func (c Coordinates) String() string {
+	switch c {
+	case UserSpaceOnUse:
+		return "userSpaceOnUse"
+	case ObjectBoundingBox:
+		fallthrough
+	default:
+		return "objectBoundingBox"
+	}
+}
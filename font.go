@@ -0,0 +1,28 @@
+package data
+
+/////////////////////////////////////////////////////////////////////
+// INTERFACES
+
+// Font provides the metrics of a font, either loaded from a font file
+// or bundled for generic font families
+type Font interface {
+	// Return the family name and variant of the font
+	Family() string
+	Variant() FontVariant
+
+	// Return the vertical metrics of the font for a font size
+	Metrics(size float32) FontMetrics
+
+	// Return the advance width of a string for a font size
+	Width(size float32, value string) float32
+}
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// FontMetrics are distances from the baseline of a font for a font
+// size, where the descent is positive below the baseline
+type FontMetrics struct {
+	Ascent, Descent, LineGap float32
+	CapHeight, XHeight       float32
+}
@@ -0,0 +1,337 @@
+package canvas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+var (
+	// Transform types which can be animated, and the identity value
+	// for each type
+	animateTransformTypes = map[string]string{
+		"translate": "0",
+		"scale":     "1",
+		"rotate":    "0",
+		"skewX":     "0",
+		"skewY":     "0",
+	}
+)
+
+/////////////////////////////////////////////////////////////////////
+// ANIMATION PRIMITIVES
+
+// Animate returns an animation of an attribute or property from one
+// value to another over a duration
+func (this *Canvas) Animate(attr, from, to string, dur time.Duration, repeat uint) data.CanvasAnimation {
+	attr = strings.TrimSpace(attr)
+	if attr == "" {
+		return nil
+	}
+	elem, err := this.newAnimation("animate", dur, repeat)
+	if err != nil {
+		return nil
+	}
+
+	elem.SetAttr("attributeName", attr)
+	elem.SetAttr("from", strings.TrimSpace(from))
+	elem.SetAttr("to", strings.TrimSpace(to))
+	return elem
+}
+
+// AnimateTransform returns an animation of the transform of an element
+// from one transform to another of the same type, where either can be
+// the identity transform or nil. Matrix transforms cannot be animated
+func (this *Canvas) AnimateTransform(from, to data.CanvasTransform, dur time.Duration, repeat uint) data.CanvasAnimation {
+	fromType, fromValue, ok := animateTransformValue(from)
+	if ok == false {
+		return nil
+	}
+	toType, toValue, ok := animateTransformValue(to)
+	if ok == false {
+		return nil
+	}
+	switch {
+	case fromType == "" && toType == "":
+		return nil
+	case fromType == "":
+		fromType, fromValue = toType, animateTransformTypes[toType]
+	case toType == "":
+		toType, toValue = fromType, animateTransformTypes[fromType]
+	case fromType != toType:
+		return nil
+	}
+	elem, err := this.newAnimation("animateTransform", dur, repeat)
+	if err != nil {
+		return nil
+	}
+
+	elem.SetAttr("attributeName", "transform")
+	elem.SetAttr("type", fromType)
+	elem.SetAttr("from", fromValue)
+	elem.SetAttr("to", toValue)
+	return elem
+}
+
+// AnimateMotion returns an animation which moves an element along a
+// path over a duration
+func (this *Canvas) AnimateMotion(dur time.Duration, repeat uint, paths ...data.CanvasPath) data.CanvasAnimation {
+	d := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if segment, ok := path.(PathSegment); ok == false {
+			return nil
+		} else if segment != "" {
+			d = append(d, string(segment))
+		}
+	}
+	if len(d) == 0 {
+		return nil
+	}
+	elem, err := this.newAnimation("animateMotion", dur, repeat)
+	if err != nil {
+		return nil
+	}
+
+	elem.SetAttr("path", strings.Join(d, " "))
+	return elem
+}
+
+// AnimateSet returns an animation which sets the value of an attribute
+// or property when the animation begins
+func (this *Canvas) AnimateSet(attr, to string) data.CanvasAnimation {
+	attr = strings.TrimSpace(attr)
+	if attr == "" {
+		return nil
+	}
+	elem, err := this.NewElement("set")
+	if err != nil {
+		return nil
+	}
+
+	elem.SetAttr("attributeName", attr)
+	elem.SetAttr("to", strings.TrimSpace(to))
+	return elem
+}
+
+/////////////////////////////////////////////////////////////////////
+// ANIMATION METHODS
+
+// Animation attaches animations to an element, where an animated
+// transform applies to the transform attribute of the element
+func (this *Element) Animation(animations ...data.CanvasAnimation) data.CanvasElement {
+	for _, animation := range animations {
+		if animation == nil {
+			return nil
+		} else if elem, ok := animation.(*Element); ok == false || elem.isAnimation() == false {
+			return nil
+		} else if err := this.AddChild(elem.Node); err != nil {
+			return nil
+		} else if elem.isElement("animateTransform") {
+			elem.SetAttr("attributeName", this.transformAttr())
+		}
+	}
+	return this
+}
+
+func (this *Element) Begin(offset time.Duration) data.CanvasAnimation {
+	if this.isAnimation() == false {
+		return nil
+	} else if err := this.SetAttr("begin", clockString(offset)); err != nil {
+		return nil
+	}
+
+	// Return animation
+	return this
+}
+
+func (this *Element) End(offset time.Duration) data.CanvasAnimation {
+	if this.isAnimation() == false {
+		return nil
+	} else if err := this.SetAttr("end", clockString(offset)); err != nil {
+		return nil
+	}
+
+	// Return animation
+	return this
+}
+
+// KeySplines sets the easing for each interval of an animation, where
+// the intervals are of equal duration. An animation from one value to
+// another has one interval, and an animation with a list of values has
+// an interval between each value, so the number of splines must match.
+// Without any arguments, values are interpolated linearly
+func (this *Element) KeySplines(splines ...[4]float32) data.CanvasAnimation {
+	if this.isElement("animate", "animateTransform", "animateMotion") == false {
+		return nil
+	}
+	if len(splines) == 0 {
+		for _, name := range []string{"calcMode", "keySplines", "keyTimes", "keyPoints"} {
+			this.RemoveAttr(name)
+		}
+		return this
+	}
+	if n := this.intervals(); n > 0 && n != len(splines) {
+		return nil
+	}
+
+	// Set splines and equally spaced key times
+	values := make([]string, len(splines))
+	times := make([]string, len(splines)+1)
+	for i, spline := range splines {
+		for _, v := range spline {
+			if v < 0 || v > 1 {
+				return nil
+			}
+		}
+		values[i] = f32.Join(spline[:], " ")
+		times[i] = f32.String(float32(i) / float32(len(splines)))
+	}
+	times[len(splines)] = "1"
+	this.SetAttr("calcMode", "spline")
+	this.SetAttr("keySplines", strings.Join(values, ";"))
+	this.SetAttr("keyTimes", strings.Join(times, ";"))
+	if this.isElement("animateMotion") {
+		this.SetAttr("keyPoints", strings.Join(times, ";"))
+	}
+
+	// Return animation
+	return this
+}
+
+func (this *Element) Freeze() data.CanvasAnimation {
+	if this.isAnimation() == false {
+		return nil
+	} else if err := this.SetAttr("fill", "freeze"); err != nil {
+		return nil
+	}
+
+	// Return animation
+	return this
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// newAnimation returns an animation element with a duration and
+// repeat count
+func (this *Canvas) newAnimation(name string, dur time.Duration, repeat uint) (*Element, error) {
+	if dur <= 0 {
+		return nil, data.ErrBadParameter.WithPrefix(name, ": Invalid duration")
+	}
+	elem, err := this.NewElement(name)
+	if err != nil {
+		return nil, err
+	}
+	elem.SetAttr("dur", clockString(dur))
+	if repeat == 0 {
+		elem.SetAttr("repeatCount", "indefinite")
+	} else if repeat > 1 {
+		elem.SetAttr("repeatCount", fmt.Sprint(repeat))
+	}
+
+	// Return success
+	return elem, nil
+}
+
+// isAnimation returns true if the element is an animation
+func (this *Element) isAnimation() bool {
+	return this.isElement("animate", "animateTransform", "animateMotion", "set")
+}
+
+// intervals returns the number of intervals between the values of an
+// animation, or zero if any number of intervals is possible, as for
+// motion along a path
+func (this *Element) intervals() int {
+	if attr, exists := this.Attr("values"); exists {
+		n := 0
+		for _, value := range strings.Split(attr.Value, ";") {
+			if strings.TrimSpace(value) != "" {
+				n++
+			}
+		}
+		return n - 1
+	} else if this.isElement("animate", "animateTransform") {
+		return 1
+	}
+	return 0
+}
+
+// animateTransformValue returns the type and value of a transform for
+// an animation, or an empty type for the identity transform. Returns
+// false if the transform cannot be animated
+func animateTransformValue(transform data.CanvasTransform) (string, string, bool) {
+	if transform == nil {
+		return "", "", true
+	}
+	op, ok := transform.(TransformOperation)
+	if ok == false {
+		return "", "", false
+	} else if op == NilTransformOperation {
+		return "", "", true
+	}
+	value := string(op)
+	open, close := strings.Index(value, "("), strings.LastIndex(value, ")")
+	if open < 0 || close < open {
+		return "", "", false
+	}
+	name := value[:open]
+	switch name {
+	case "skewx":
+		name = "skewX"
+	case "skewy":
+		name = "skewY"
+	}
+	if _, exists := animateTransformTypes[name]; exists == false {
+		return "", "", false
+	}
+	return name, strings.Join(strings.FieldsFunc(value[open+1:close], isListSeparator), " "), true
+}
+
+// clockString returns a duration as a clock value in seconds
+func clockString(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// parseClock returns a duration from a clock value, which is either a
+// number with an optional unit of h, min, s or ms, or hours, minutes and
+// seconds separated by colons
+func parseClock(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, ":") {
+		parts := strings.Split(value, ":")
+		if len(parts) > 3 {
+			return 0, data.ErrBadParameter.WithPrefix("Invalid clock value: ", strconv.Quote(value))
+		}
+		seconds := float64(0)
+		for _, part := range parts {
+			if v, err := strconv.ParseFloat(part, 64); err != nil || v < 0 {
+				return 0, data.ErrBadParameter.WithPrefix("Invalid clock value: ", strconv.Quote(value))
+			} else {
+				seconds = seconds*60 + v
+			}
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	scale := float64(time.Second)
+	for _, unit := range []struct {
+		suffix string
+		scale  time.Duration
+	}{{"ms", time.Millisecond}, {"min", time.Minute}, {"h", time.Hour}, {"s", time.Second}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value, scale = strings.TrimSuffix(value, unit.suffix), float64(unit.scale)
+			break
+		}
+	}
+	if v, err := strconv.ParseFloat(value, 64); err != nil {
+		return 0, data.ErrBadParameter.WithPrefix("Invalid clock value: ", strconv.Quote(value))
+	} else {
+		return time.Duration(v * scale), nil
+	}
+}
@@ -0,0 +1,113 @@
+package canvas_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+)
+
+func Test_Animate_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	pulse := c.Animate("opacity", "1", "0.2", 500*time.Millisecond, 0).KeySplines([4]float32{0.42, 0, 0.58, 1})
+	grow := c.AnimateTransform(c.Scale(data.Size{1, 0}), nil, 2*time.Second, 1).Begin(time.Second).Freeze()
+	if pulse == nil || grow == nil {
+		t.Fatal("Unexpected nil animation")
+	}
+	c.Circle(data.Point{50, 50}, 10).Animation(pulse)
+	c.Rect(data.Point{10, 10}, data.Size{10, 80}).Animation(grow)
+
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Contains(str, `<circle cx="50" cy="50" r="10"><animate dur="0.5s" repeatCount="indefinite" attributeName="opacity" from="1" to="0.2" calcMode="spline" keySplines="0.420000 0 0.580000 1" keyTimes="0;1"></animate></circle>`) == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+	if strings.Contains(str, `<animateTransform dur="2s" attributeName="transform" type="scale" from="1 0" to="1" begin="1s" fill="freeze"></animateTransform></rect>`) == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+
+	// Animations are read back
+	if _, err := canvas.Read(data.SVG, strings.NewReader(str)); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Animate_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	motion := c.AnimateMotion(3*time.Second, 2, c.MoveTo(data.Point{10, 10}), c.LineTo(data.Point{90, 90})).End(10 * time.Second)
+	set := c.AnimateSet("fill", "red").Begin(1500 * time.Millisecond)
+	g := c.Group(c.Circle(data.ZeroPoint, 5)).Animation(motion, set)
+	if g == nil {
+		t.Fatal("Unexpected nil group")
+	}
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify, b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); strings.Contains(str, `<animateMotion dur="3s" repeatCount="2" path="M 10 10 L 90 90" end="10s"></animateMotion><set attributeName="fill" to="red" begin="1.5s"></set></g>`) == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+
+	// Invalid animations
+	if c.Animate("", "0", "1", time.Second, 1) != nil {
+		t.Error("Expected nil for missing attribute")
+	} else if c.Animate("x", "0", "1", 0, 1) != nil {
+		t.Error("Expected nil for zero duration")
+	} else if c.AnimateTransform(c.Scale(data.Size{2, 2}), c.Rotate(45), time.Second, 1) != nil {
+		t.Error("Expected nil for different transform types")
+	} else if c.AnimateTransform(nil, c.Matrix([6]float32{1, 0, 0, 2, 0, 0}), time.Second, 1) != nil {
+		t.Error("Expected nil for matrix transform")
+	} else if c.AnimateMotion(time.Second, 1) != nil {
+		t.Error("Expected nil for missing path")
+	} else if c.Animate("x", "0", "1", time.Second, 1).KeySplines([4]float32{0, 0, 2, 1}) != nil {
+		t.Error("Expected nil for invalid spline")
+	} else if c.Animate("x", "0", "1", time.Second, 1).KeySplines([4]float32{0, 0, 1, 1}, [4]float32{0, 0, 1, 1}) != nil {
+		t.Error("Expected nil for more splines than intervals")
+	} else if c.AnimateTransform(nil, c.Rotate(45), time.Second, 1).KeySplines([4]float32{0, 0, 1, 1}, [4]float32{0, 0, 1, 1}) != nil {
+		t.Error("Expected nil for more splines than intervals")
+	} else if c.Circle(data.ZeroPoint, 1).Animation(c.Circle(data.ZeroPoint, 1).(data.CanvasAnimation)) != nil {
+		t.Error("Expected nil for element which is not an animation")
+	}
+}
+
+func Test_Animate_003(t *testing.T) {
+	for _, svg := range []string{
+		`<animate attributeName="x" dur="1x" />`,
+		`<animate attributeName="x" dur="1s" repeatCount="-1" />`,
+		`<animate attributeName="x" dur="1s" calcMode="bounce" />`,
+		`<animate attributeName="x" dur="1s" keySplines="0 0 1" />`,
+		`<animateTransform attributeName="transform" type="matrix" dur="1s" />`,
+		`<animateMotion path="M 0 0 X" dur="1s" />`,
+	} {
+		svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><rect width="10" height="10">` + svg + `</rect></svg>`
+		if _, err := canvas.Read(data.SVG, strings.NewReader(svg)); err == nil {
+			t.Error("Expected error for: ", svg)
+		}
+	}
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><rect width="10" height="10">
+		<animate attributeName="x" dur="00:01:30.5" begin="click" repeatDur="indefinite" values="0;5;0" keyTimes="0;0.5;1" />
+		<animate attributeName="y" dur="250ms" repeatCount="2.5" />
+	</rect></svg>`
+	if _, err := canvas.Read(data.SVG, strings.NewReader(svg)); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Animate_004(t *testing.T) {
+	// Animated groups and transforms are not collapsed when optimised
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Group(c.Circle(data.ZeroPoint, 5), c.Circle(data.Point{10, 10}, 5)).Animation(c.Animate("opacity", "0", "1", time.Second, 1))
+	c.Group(c.Rect(data.ZeroPoint, data.Size{10, 10}).Animation(c.AnimateTransform(nil, c.Rotate(90), time.Second, 0))).Transform(c.Translate(data.Point{50, 50}))
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify|data.Optimise, b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); strings.Contains(str, `<g><circle cx="0" cy="0" r="5"></circle><circle cx="10" cy="10" r="5"></circle><animate`) == false {
+		t.Error("Unexpected output, got: ", str)
+	} else if strings.Contains(str, `<g transform="translate(50 50)"><rect`) == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+}
@@ -0,0 +1,362 @@
+package canvas
+
+import (
+	"math"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// boundswriter is a renderer which collects the extent of everything
+// which would be drawn, in the user space of the first transform
+type boundswriter struct {
+	canvas *Canvas
+	stack  []matrix
+	clips  []clipextent // Extent of the clipping paths for each transform
+	pts    []data.Point
+}
+
+// clipextent is the extent of a clipping path in the user space of the
+// first transform, which limits the points added since the transform
+// was pushed
+type clipextent struct {
+	start   int
+	clipped bool
+	origin  data.Point
+	size    data.Size
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Maximum distance between curves and flattened curves in user
+	// space when computing bounding boxes
+	boundsTolerance = 0.01
+)
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Bounds returns the bounding box of an element in the user space of the
+// canvas, including the stroke and markers of shapes, text, and the
+// transforms of the element and any groups which contain it. Curves are
+// bounded by their extrema rather than by their control points, and the
+// extent is limited by clipping paths and marker viewports. Returns a zero
+// size when the element draws nothing
+func (this *Element) Bounds() (data.Point, data.Size) {
+	// Determine the transform and style from the parent element
+	m := identity
+	var chain []data.Node
+	for parent := this.Node.Parent(); parent != nil; parent = parent.Parent() {
+		chain = append(chain, parent)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if attr, exists := chain[i].Attr("transform"); exists {
+			if m_, err := parseTransform(attr.Value); err == nil {
+				m = m.multiply(m_)
+			}
+		}
+	}
+	style := this.Canvas.rootStyle()
+	if parent := this.Node.Parent(); parent != nil {
+		style = this.Canvas.inheritedStyle(parent)
+	}
+
+	// Definitions are not drawn, so the extent is that of the children
+	r := &boundswriter{canvas: this.Canvas, stack: []matrix{m}, clips: []clipextent{{}}}
+	if this.isElement("defs", "symbol", "marker", "pattern", "clipPath", "mask") {
+		style = style.inherit(this.Node)
+		for _, child := range this.Children() {
+			if err := this.Canvas.renderNode(r, child, style); err != nil {
+				return data.ZeroPoint, data.ZeroSize
+			}
+		}
+	} else if err := this.Canvas.renderNode(r, this.Node, style); err != nil {
+		return data.ZeroPoint, data.ZeroSize
+	}
+
+	// Return the bounding box
+	if len(r.pts) == 0 {
+		return data.ZeroPoint, data.ZeroSize
+	}
+	return pathBounds([]subpath{{pts: r.pts}})
+}
+
+// FitViewBox sets the view box of the canvas to the bounding box of the
+// elements drawn on the canvas, with a margin on each side. Returns an
+// error if nothing is drawn on the canvas
+func (this *Canvas) FitViewBox(margin float32) error {
+	origin, size := this.Element.Bounds()
+	if size == data.ZeroSize {
+		return data.ErrNotFound.WithPrefix("FitViewBox")
+	}
+	origin = data.Point{origin.X - margin, origin.Y - margin}
+	size = data.Size{size.W + 2*margin, size.H + 2*margin}
+	if size.W <= 0 || size.H <= 0 {
+		return data.ErrBadParameter.WithPrefix("FitViewBox")
+	}
+	return this.SetViewBox(origin, size)
+}
+
+/////////////////////////////////////////////////////////////////////
+// RENDERER METHODS
+
+func (this *boundswriter) push(m matrix) error {
+	this.stack = append(this.stack, this.ctm().multiply(m))
+	this.clips = append(this.clips, clipextent{start: len(this.pts)})
+	return nil
+}
+
+func (this *boundswriter) pop() error {
+	if len(this.stack) <= 1 {
+		return data.ErrInternalAppError.WithPrefix("pop")
+	}
+
+	// Limit the points added since the transform was pushed to the
+	// extent of the clipping path
+	if clip := this.clips[len(this.clips)-1]; clip.clipped && len(this.pts) > clip.start {
+		origin, size := pathBounds([]subpath{{pts: this.pts[clip.start:]}})
+		this.pts = this.pts[:clip.start]
+		if origin, size, ok := intersectBounds(origin, size, clip.origin, clip.size); ok {
+			this.pts = append(this.pts, rectPoints(origin, size)...)
+		}
+	}
+	this.stack = this.stack[:len(this.stack)-1]
+	this.clips = this.clips[:len(this.clips)-1]
+	return nil
+}
+
+func (this *boundswriter) path(path []pathop, style *renderstyle) error {
+	m := this.ctm()
+	this.pts = append(this.pts, pathExtrema(transformPath(m, path))...)
+
+	// Add the stroke outline, flattened in user space
+	if (style.stroke != nil || style.strokeRef != "") && style.strokeWidth > 0 {
+		tolerance := float32(boundsTolerance)
+		if scale := m.scale(); scale > 0 {
+			tolerance /= scale
+		}
+		stroker := newStroker(style.strokeWidth, style.lineCap, style.lineJoin, style.miterLimit, tolerance)
+		for _, poly := range stroker.stroke(dash(flatten(path, tolerance), style.dashes, style.dashOffset)) {
+			this.pts = append(this.pts, transformPoints(m, poly)...)
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+func (this *boundswriter) text(pt data.Point, value string, style *renderstyle) error {
+	metrics := this.canvas.fontFor(style).Metrics(style.fontSize)
+	x0, x1 := pt.X, pt.X+this.canvas.measure(style, value)
+	this.pts = append(this.pts, transformPoints(this.ctm(), []data.Point{
+		{x0, pt.Y - metrics.Ascent}, {x1, pt.Y - metrics.Ascent},
+		{x1, pt.Y + metrics.Descent}, {x0, pt.Y + metrics.Descent},
+	})...)
+	return nil
+}
+
+func (this *boundswriter) image(pt data.Point, size data.Size, href string, aspect aspectratio) error {
+	if size.W > 0 && size.H > 0 {
+		this.pts = append(this.pts, transformPoints(this.ctm(), rectPoints(pt, size))...)
+	}
+	return nil
+}
+
+func (this *boundswriter) clip(shapes []clipshape) error {
+	// Determine the extent of the shapes, where no shapes clip everything
+	var pts []data.Point
+	for _, shape := range shapes {
+		pts = append(pts, pathExtrema(transformPath(this.ctm(), shape.path))...)
+	}
+	origin, size := data.ZeroPoint, data.Size{-1, -1}
+	if len(pts) > 0 {
+		origin, size = pathBounds([]subpath{{pts: pts}})
+	}
+
+	// Intersect with any clipping path for the same transform
+	clip := &this.clips[len(this.clips)-1]
+	if clip.clipped {
+		var ok bool
+		if origin, size, ok = intersectBounds(origin, size, clip.origin, clip.size); ok == false {
+			size = data.Size{-1, -1}
+		}
+	}
+	clip.clipped, clip.origin, clip.size = true, origin, size
+
+	// Return success
+	return nil
+}
+
+func (this *boundswriter) beginMask() error {
+	// Masks do not change the bounding box
+	return nil
+}
+
+func (this *boundswriter) endMask(mask *Element, m matrix) error {
+	// Masks do not change the bounding box
+	return nil
+}
+
+func (this *boundswriter) beginLayer(opacity float32, blend data.BlendMode) error {
+	// Opacity and blending do not change the bounding box
+	return nil
+}
+
+func (this *boundswriter) endLayer() error {
+	// Opacity and blending do not change the bounding box
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// bounds returns the bounding box of an element and any children in
+// the user space of the element, or false if there is no geometry
+func (this *Canvas) bounds(elem *Element) (data.Point, data.Size, bool) {
+	pts := this.boundsPoints(elem, identity, nil)
+	if len(pts) == 0 {
+		return data.ZeroPoint, data.ZeroSize, false
+	}
+	origin, size := pathBounds([]subpath{{pts: pts}})
+	return origin, size, true
+}
+
+// boundsPoints appends the extrema of the geometry of an element and
+// any children, transformed by a matrix
+func (this *Canvas) boundsPoints(elem *Element, m matrix, pts []data.Point) []data.Point {
+	if path, err := elem.geometry(); err == nil {
+		pts = append(pts, pathExtrema(transformPath(m, path))...)
+	} else if elem.isElement("text") {
+		pts = append(pts, transformPoints(m, this.textPoints(elem, this.inheritedStyle(elem.Node)))...)
+	}
+	if elem.isElement("svg", "g", "a") {
+		for _, child := range elem.Children() {
+			cm := m
+			if attr, exists := child.Attr("transform"); exists {
+				if m_, err := parseTransform(attr.Value); err == nil {
+					cm = cm.multiply(m_)
+				}
+			}
+			pts = this.boundsPoints(&Element{child, this}, cm, pts)
+		}
+	}
+	return pts
+}
+
+// ctm returns the current transform
+func (this *boundswriter) ctm() matrix {
+	return this.stack[len(this.stack)-1]
+}
+
+// pathExtrema returns the end points of each segment of a path and the
+// points where curves reach a minimum or maximum in either axis
+func pathExtrema(path []pathop) []data.Point {
+	var pts []data.Point
+	var start, pt data.Point
+	for _, seg := range path {
+		switch seg.op {
+		case 'M':
+			start = seg.pts[0]
+		case 'Q':
+			for _, t := range quadraticExtrema(pt, seg.pts[0], seg.pts[1]) {
+				pts = append(pts, quadraticPoint(pt, seg.pts[0], seg.pts[1], t))
+			}
+		case 'C':
+			for _, t := range cubicExtrema(pt, seg.pts[0], seg.pts[1], seg.pts[2]) {
+				pts = append(pts, cubicPoint(pt, seg.pts[0], seg.pts[1], seg.pts[2], t))
+			}
+		case 'Z':
+			pt = start
+			continue
+		}
+		pt = seg.pts[len(seg.pts)-1]
+		pts = append(pts, pt)
+	}
+	return pts
+}
+
+// quadraticExtrema returns the parameters between 0 and 1 where the
+// derivative of a quadratic curve is zero in either axis
+func quadraticExtrema(p0, p1, p2 data.Point) []float32 {
+	var ts []float32
+	for _, v := range [][3]float32{{p0.X, p1.X, p2.X}, {p0.Y, p1.Y, p2.Y}} {
+		if d := v[0] - 2*v[1] + v[2]; d != 0 {
+			if t := (v[0] - v[1]) / d; t > 0 && t < 1 {
+				ts = append(ts, t)
+			}
+		}
+	}
+	return ts
+}
+
+// cubicExtrema returns the parameters between 0 and 1 where the
+// derivative of a cubic curve is zero in either axis
+func cubicExtrema(p0, p1, p2, p3 data.Point) []float32 {
+	var ts []float32
+	for _, v := range [][4]float32{{p0.X, p1.X, p2.X, p3.X}, {p0.Y, p1.Y, p2.Y, p3.Y}} {
+		// The derivative is a quadratic at^2 + bt + c
+		a := float64(-v[0] + 3*v[1] - 3*v[2] + v[3])
+		b := float64(2 * (v[0] - 2*v[1] + v[2]))
+		c := float64(v[1] - v[0])
+		if math.Abs(a) < 1e-12 {
+			if b != 0 {
+				ts = append(ts, float32(-c/b))
+			}
+			continue
+		}
+		if d := b*b - 4*a*c; d >= 0 {
+			d = math.Sqrt(d)
+			ts = append(ts, float32((-b+d)/(2*a)), float32((-b-d)/(2*a)))
+		}
+	}
+
+	// Remove parameters outside of the curve
+	result := ts[:0]
+	for _, t := range ts {
+		if t > 0 && t < 1 {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// quadraticPoint returns the point on a quadratic curve at parameter t
+func quadraticPoint(p0, p1, p2 data.Point, t float32) data.Point {
+	u := 1 - t
+	return data.Point{
+		u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+		u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+	}
+}
+
+// cubicPoint returns the point on a cubic curve at parameter t
+func cubicPoint(p0, p1, p2, p3 data.Point, t float32) data.Point {
+	u := 1 - t
+	return data.Point{
+		u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X,
+		u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y,
+	}
+}
+
+// intersectBounds returns the intersection of two bounding boxes, or
+// false if they do not intersect
+func intersectBounds(o1 data.Point, s1 data.Size, o2 data.Point, s2 data.Size) (data.Point, data.Size, bool) {
+	x0, y0 := f32.Max(o1.X, o2.X), f32.Max(o1.Y, o2.Y)
+	x1, y1 := f32.Min(o1.X+s1.W, o2.X+s2.W), f32.Min(o1.Y+s1.H, o2.Y+s2.H)
+	if x1 < x0 || y1 < y0 {
+		return data.ZeroPoint, data.ZeroSize, false
+	}
+	return data.Point{x0, y0}, data.Size{x1 - x0, y1 - y0}, true
+}
+
+// rectPoints returns the corners of a rectangle
+func rectPoints(pt data.Point, size data.Size) []data.Point {
+	return []data.Point{
+		pt, {pt.X + size.W, pt.Y}, {pt.X + size.W, pt.Y + size.H}, {pt.X, pt.Y + size.H},
+	}
+}
@@ -0,0 +1,124 @@
+package canvas_test
+
+import (
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+	f32 "github.com/djthorpe/data/pkg/f32"
+)
+
+func Test_Bounds_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	tests := []struct {
+		elem   data.CanvasElement
+		origin data.Point
+		size   data.Size
+	}{
+		{c.Rect(data.Point{10, 10}, data.Size{20, 20}), data.Point{10, 10}, data.Size{20, 20}},
+		{c.Rect(data.Point{10, 10}, data.Size{20, 20}).Style(c.Stroke(color.Black, 1), c.StrokeWidth(2)), data.Point{9, 9}, data.Size{22, 22}},
+		{c.Circle(data.Point{50, 50}, 10), data.Point{40, 40}, data.Size{20, 20}},
+		{c.Ellipse(data.Point{50, 50}, data.Size{10, 5}), data.Point{40, 45}, data.Size{20, 10}},
+		{c.Line(data.Point{10, 20}, data.Point{30, 10}), data.Point{10, 10}, data.Size{20, 10}},
+		{c.Polygon(data.Point{0, 0}, data.Point{10, 5}, data.Point{5, 10}), data.ZeroPoint, data.Size{10, 10}},
+		{c.Path(c.MoveTo(data.ZeroPoint), c.CubicTo(data.Point{10, 0}, data.Point{0, 10}, data.Point{10, 10})), data.ZeroPoint, data.Size{10, 7.5}},
+		{c.Path(c.MoveTo(data.ZeroPoint), c.QuadraticTo(data.Point{10, 0}, data.Point{5, 10})), data.ZeroPoint, data.Size{10, 5}},
+		{c.Image(data.Point{5, 5}, data.Size{10, 20}, "image.png"), data.Point{5, 5}, data.Size{10, 20}},
+		{c.Group(), data.ZeroPoint, data.ZeroSize},
+	}
+	for i, test := range tests {
+		if test.elem == nil {
+			t.Fatal("Unexpected nil element for test", i)
+		}
+		if origin, size := test.elem.Bounds(); boundsEqual(origin, size, test.origin, test.size, 0.01) == false {
+			t.Error("Test", i, ": unexpected bounds, got: ", origin, size)
+		}
+	}
+}
+
+func Test_Bounds_002(t *testing.T) {
+	// Nested group transforms are applied to elements within the groups
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	circle := c.Circle(data.ZeroPoint, 5)
+	inner := c.Group(circle).Transform(c.Scale(data.Size{2, 2}))
+	outer := c.Group(inner).Transform(c.Translate(data.Point{10, 20}))
+	for _, elem := range []data.CanvasElement{circle, inner, outer} {
+		if origin, size := elem.Bounds(); boundsEqual(origin, size, data.Point{0, 10}, data.Size{20, 20}, 0.01) == false {
+			t.Error("Unexpected bounds, got: ", origin, size)
+		}
+	}
+
+	// Elements referenced by use elements are included
+	c.Defs(c.Rect(data.ZeroPoint, data.Size{10, 10}).Id("box"))
+	if origin, size := c.Use("box", data.Point{80, 80}).Bounds(); origin != (data.Point{80, 80}) || size != (data.Size{10, 10}) {
+		t.Error("Unexpected bounds, got: ", origin, size)
+	}
+}
+
+func Test_Bounds_003(t *testing.T) {
+	// Markers are placed at the vertices of a path, and clipped to the
+	// marker viewport
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Defs(c.Marker(data.Point{2, 2}, data.Size{4, 4}, c.Rect(data.ZeroPoint, data.Size{10, 10})).Id("arrow"))
+	line := c.Line(data.ZeroPoint, data.Point{10, 0}).Style(c.UseMarker(data.End, "url(#arrow)"))
+	if origin, size := line.Bounds(); origin != (data.Point{0, -2}) || size != (data.Size{12, 4}) {
+		t.Error("Unexpected bounds, got: ", origin, size)
+	}
+
+	// Markers are oriented to the direction of the path and scaled by
+	// the stroke width
+	line = c.Line(data.ZeroPoint, data.Point{0, 10}).Style(c.UseMarker(data.End, "url(#arrow)"), c.StrokeWidth(2))
+	if origin, size := line.Bounds(); boundsEqual(origin, size, data.Point{-4, 0}, data.Size{8, 14}, 0.01) == false {
+		t.Error("Unexpected bounds, got: ", origin, size)
+	}
+}
+
+func Test_Bounds_004(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	if err := c.FitViewBox(5); err == nil {
+		t.Error("Expected error for empty canvas")
+	}
+	c.Circle(data.Point{50, 50}, 10)
+	c.Rect(data.Point{40, 20}, data.Size{5, 5}).Transform(c.Translate(data.Point{10, 0}))
+	if err := c.FitViewBox(5); err != nil {
+		t.Fatal(err)
+	} else if origin, size := c.ViewBox(); origin != (data.Point{35, 15}) || size != (data.Size{30, 50}) {
+		t.Error("Unexpected view box, got: ", origin, size)
+	}
+
+	// Read a document and crop to the contents
+	c2, err := canvas.Read(data.SVG, strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 200">
+		<g transform="translate(100 100)"><path d="M0 0 C 0 20 20 20 20 0" stroke="black" stroke-width="2"/></g>
+	</svg>`))
+	if err != nil {
+		t.Fatal(err)
+	} else if err := c2.FitViewBox(0); err != nil {
+		t.Fatal(err)
+	} else if origin, size := c2.ViewBox(); boundsEqual(origin, size, data.Point{99, 100}, data.Size{22, 16}, 0.05) == false {
+		t.Error("Unexpected view box, got: ", origin, size)
+	}
+}
+
+func Test_Bounds_005(t *testing.T) {
+	// Clipping paths limit the extent of an element
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.ClipPath(c.Rect(data.ZeroPoint, data.Size{50, 50})).Id("quarter")
+	rect := c.Rect(data.Point{20, 20}, data.Size{60, 60}).Style(c.Clip("quarter"))
+	if origin, size := rect.Bounds(); origin != (data.Point{20, 20}) || size != (data.Size{30, 30}) {
+		t.Error("Unexpected bounds, got: ", origin, size)
+	}
+
+	// Elements outside of the clipping path draw nothing
+	rect = c.Rect(data.Point{60, 60}, data.Size{10, 10}).Style(c.Clip("quarter"))
+	if _, size := rect.Bounds(); size != data.ZeroSize {
+		t.Error("Unexpected bounds, got: ", size)
+	}
+}
+
+// boundsEqual returns true if two bounding boxes are within a tolerance
+func boundsEqual(origin data.Point, size data.Size, origin2 data.Point, size2 data.Size, tolerance float32) bool {
+	return f32.Abs(origin.X-origin2.X) < tolerance && f32.Abs(origin.Y-origin2.Y) < tolerance &&
+		f32.Abs(size.W-size2.W) < tolerance && f32.Abs(size.H-size2.H) < tolerance
+}
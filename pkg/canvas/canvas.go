@@ -17,8 +17,12 @@ import (
 type Canvas struct {
 	data.Document
 	*Element
-	origin data.Point
-	size   data.Size
+	origin    data.Point
+	size      data.Size
+	fonts     []data.Font
+	precision int
+	css       string           // Style sheets for the cached rules
+	rules     []data.StyleRule // Cached style sheet rules
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -30,6 +34,7 @@ func NewCanvas(size data.Size, units data.Unit) data.Canvas {
 	this.Element = &Element{this.Document, this}
 	this.origin = data.ZeroPoint
 	this.size = size
+	this.precision = defaultPrecision
 
 	// Set width and height attributes
 	w, h := size.UnitString(units)
@@ -123,6 +128,15 @@ func (this *Canvas) Title(cdata string) data.Canvas {
 	return this
 }
 
+func (this *Canvas) Metadata(nodes ...data.Node) data.Canvas {
+	if this.Element.Metadata(nodes...) == nil {
+		return nil
+	}
+
+	// Return success
+	return this
+}
+
 func (this *Canvas) Version(value string) data.Canvas {
 	value = strings.TrimSpace(value)
 
@@ -143,6 +157,10 @@ func (this *Canvas) Version(value string) data.Canvas {
 
 func (this *Canvas) Write(fmt data.Writer, w io.Writer) error {
 	switch {
+	case fmt&data.PNG == data.PNG:
+		return this.writePNG(fmt, w)
+	case fmt&data.PDF == data.PDF:
+		return this.writePDF(fmt, w)
 	case fmt&data.SVG == data.SVG:
 		return this.writeSVG(fmt, w)
 	default:
@@ -155,6 +173,13 @@ func (this *Canvas) writeSVG(fmt data.Writer, w io.Writer) error {
 	if fmt&data.Minify != data.Minify {
 		opts |= data.DOMWriteIndentSpace2
 	}
+	if fmt&data.Optimise == data.Optimise {
+		if document, err := this.optimise(); err != nil {
+			return err
+		} else {
+			return document.WriteEx(w, opts)
+		}
+	}
 	return this.Document.WriteEx(w, opts)
 }
 
@@ -192,3 +217,64 @@ func viewBoxFromAttr(element data.Node) (data.Point, data.Size, error) {
 	}
 	return pt, sz, nil
 }
+
+// sizeFromAttr returns the width and height of the canvas in pixels,
+// or the viewBox size if the width and height are not set
+func sizeFromAttr(element data.Node, viewBox data.Size) (data.Size, error) {
+	size := viewBox
+	if attr, exists := element.Attr("width"); exists && attr.Value != "" {
+		if v, err := parseUnitValue(attr.Value); err != nil {
+			return data.ZeroSize, err
+		} else {
+			size.W = v
+		}
+	}
+	if attr, exists := element.Attr("height"); exists && attr.Value != "" {
+		if v, err := parseUnitValue(attr.Value); err != nil {
+			return data.ZeroSize, err
+		} else {
+			size.H = v
+		}
+	}
+	return size, nil
+}
+
+// parseUnitValue returns a value with optional unit suffix in pixels,
+// assuming 96 pixels per inch
+func parseUnitValue(value string) (float32, error) {
+	value = strings.TrimSpace(value)
+	scale := float32(1)
+	for _, unit := range []data.Unit{data.PX, data.CM, data.MM, data.IN, data.PC, data.PT, data.EX, data.EM} {
+		if suffix := unit.String(); strings.HasSuffix(value, suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, suffix))
+			scale = pixelsPerUnit(unit)
+			break
+		}
+	}
+	if v, err := strconv.ParseFloat(value, 32); err != nil {
+		return 0, data.ErrBadParameter.WithPrefix("Invalid length: ", strconv.Quote(value))
+	} else {
+		return float32(v) * scale, nil
+	}
+}
+
+func pixelsPerUnit(unit data.Unit) float32 {
+	switch unit {
+	case data.CM:
+		return 96 / 2.54
+	case data.MM:
+		return 96 / 25.4
+	case data.IN:
+		return 96
+	case data.PC:
+		return 16
+	case data.PT:
+		return 96.0 / 72.0
+	case data.EX:
+		return 8
+	case data.EM:
+		return 16
+	default:
+		return 1
+	}
+}
@@ -8,6 +8,8 @@ import (
 	data "github.com/djthorpe/data"
 	canvas "github.com/djthorpe/data/pkg/canvas"
 	color "github.com/djthorpe/data/pkg/color"
+	f32 "github.com/djthorpe/data/pkg/f32"
+	geom "github.com/djthorpe/data/pkg/geom"
 )
 
 func CheckError(t *testing.T, err error) {
@@ -613,3 +615,56 @@ func Test_Canvas_032(t *testing.T) {
 		t.Error("Unexpected return, got: ", str)
 	}
 }
+
+func Test_Canvas_033(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{16, 16}, data.PX)
+	if g := c.Group(); g == nil {
+		t.Error("Unexpected nil from c.Group")
+	} else if g.Style(
+		c.StrokeDash(5, 2),
+		c.StrokeDashOffset(1),
+	) == nil {
+		t.Error("Unexpected nil from g.Style")
+	} else if str := fmt.Sprint(g); str != `<g style="stroke-dasharray: 5 2; stroke-dashoffset: 1;"></g>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if g := c.Group().Style(c.StrokeDash()); g == nil {
+		t.Error("Unexpected nil from g.Style")
+	} else if str := fmt.Sprint(g); str != `<g style="stroke-dasharray: none;"></g>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if g := c.Group().Style(c.NoStroke(), c.StrokeDash(5)); g == nil {
+		t.Error("Unexpected nil from g.Style")
+	} else if str := fmt.Sprint(g); str != `<g style="stroke: none;"></g>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Canvas_034(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{16, 16}, data.PX)
+	if g := c.Group().Transform(c.Matrix([6]float32{1, 0, 0, 1, 0, 0})); g == nil {
+		t.Error("Unexpected nil from g.Transform")
+	} else if str := fmt.Sprint(g); str != "<g></g>" {
+		t.Error("Unexpected return, got: ", str)
+	}
+	m := geom.ScaleMatrix(data.Size{2, 3}).Multiply(geom.TranslateMatrix(data.Point{1, 2}))
+	if g := c.Group().Transform(c.Matrix(m)); g == nil {
+		t.Error("Unexpected nil from g.Transform")
+	} else if str := fmt.Sprint(g); str != "<g transform=\"matrix(2,0,0,3,2,6)\"></g>" {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Canvas_035(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{16, 16}, data.PX)
+	r := c.Rect(data.ZeroPoint, data.Size{1, 1}).Transform(c.Scale(data.Size{2, 2}))
+	c.Group(c.Group(r).Transform(c.RotateAround(90, data.Point{5, 5}))).Transform(c.Translate(data.Point{10, 0}))
+	if m, err := canvas.ElementTransform(r); err != nil {
+		t.Fatal(err)
+	} else if pt := m.Apply(data.Point{1, 0}); f32.Abs(pt.X-20) > 0.001 || f32.Abs(pt.Y-2) > 0.001 {
+		t.Error("Unexpected point, got: ", pt)
+	}
+	if _, err := canvas.ElementTransform(nil); err == nil {
+		t.Error("Expected error for nil element")
+	}
+}
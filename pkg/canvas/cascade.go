@@ -0,0 +1,315 @@
+package canvas
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/stylesheet"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// declaration is a property declared on an element, with the
+// precedence of the declaration in the cascade
+type declaration struct {
+	name, value string
+	origin      int // Presentation attribute, style sheet or style attribute
+	important   bool
+	specificity int
+	order       int
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	originAttr = iota
+	originStyleSheet
+	originStyleAttr
+)
+
+var (
+	// Initial values of the properties used when drawing
+	initialValues = map[string]string{
+		"fill":              "black",
+		"fill-opacity":      "1",
+		"fill-rule":         "nonzero",
+		"stroke":            "none",
+		"stroke-opacity":    "1",
+		"stroke-width":      "1",
+		"stroke-linecap":    "butt",
+		"stroke-linejoin":   "miter",
+		"stroke-miterlimit": "4",
+		"stroke-dasharray":  "none",
+		"stroke-dashoffset": "0",
+		"font-family":       "serif",
+		"font-size":         "16",
+		"font-weight":       "normal",
+		"font-style":        "normal",
+		"text-anchor":       "start",
+		"marker-start":      "none",
+		"marker-mid":        "none",
+		"marker-end":        "none",
+		"opacity":           "1",
+		"mix-blend-mode":    "normal",
+		"visibility":        "visible",
+		"display":           "inline",
+	}
+
+	// Properties which are inherited, in addition to those starting
+	// with fill, stroke, font and marker
+	inheritedProps = []string{
+		"clip-rule", "color", "cursor", "direction", "dominant-baseline",
+		"letter-spacing", "paint-order", "pointer-events", "text-anchor",
+		"visibility", "word-spacing", "writing-mode",
+	}
+)
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ComputedStyle returns the properties of an element after applying
+// presentation attributes, style sheet rules and the style attribute in
+// order of precedence, where rules with a more specific selector take
+// precedence and declarations marked "!important" take precedence over
+// those which are not. Properties such as fill, stroke and font which
+// are not declared are inherited from the parent element, or otherwise
+// have their initial value. Values are returned as strings, and nil is
+// returned if the argument is not a canvas element
+func (this *Canvas) ComputedStyle(elem data.CanvasElement) map[string]interface{} {
+	elem_, ok := elem.(*Element)
+	if ok == false || elem_ == nil || elem_.Node == nil {
+		return nil
+	}
+
+	// Determine the chain of elements from the root
+	chain := []data.Node{}
+	for node := elem_.Node; node != nil; node = node.Parent() {
+		chain = append(chain, node)
+	}
+
+	// Apply the properties of each element in turn
+	rules := this.styleRules()
+	parent := initialValues
+	for i := len(chain) - 1; i >= 0; i-- {
+		style := make(map[string]string, len(parent))
+		for name, value := range parent {
+			if isInherited(name) {
+				style[name] = value
+			}
+		}
+		for name, value := range initialValues {
+			if isInherited(name) == false {
+				style[name] = value
+			}
+		}
+		for _, decl := range cascade(rules, chain[i]) {
+			name, value := decl[0], decl[1]
+			switch value {
+			case "inherit":
+				if value, exists := parent[name]; exists {
+					style[name] = value
+				} else {
+					delete(style, name)
+				}
+			case "initial":
+				if value, exists := initialValues[name]; exists {
+					style[name] = value
+				} else {
+					delete(style, name)
+				}
+			default:
+				style[name] = value
+			}
+		}
+		parent = style
+	}
+
+	// Return the computed style
+	result := make(map[string]interface{}, len(parent))
+	for name, value := range parent {
+		result[name] = value
+	}
+	return result
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// styleRules returns the rules of the style sheets in the canvas which
+// can apply to elements when drawn, caching the rules until the style
+// sheets change. Rules with a pseudo-class and invalid style sheets
+// are ignored
+func (this *Canvas) styleRules() []data.StyleRule {
+	css := []string{}
+	walkElements(this.Document, func(node data.Node) {
+		if isStyleSheet(node) {
+			css = append(css, textContent(node))
+		}
+	})
+	if key := strings.Join(css, "\x00"); key != this.css || this.rules == nil {
+		rules := []data.StyleRule{}
+		for _, value := range css {
+			sheet := stylesheet.NewStyleSheet()
+			if err := sheet.Read(strings.NewReader(value)); err != nil {
+				continue
+			}
+			for _, rule := range sheet.Rules("", "", "", "") {
+				if rule.State() == "" {
+					rules = append(rules, rule)
+				}
+			}
+		}
+		this.css, this.rules = key, rules
+	}
+	return this.rules
+}
+
+// cascade returns the properties declared for a node which take
+// precedence over other declarations of the same property, in order
+// of increasing precedence
+func cascade(rules []data.StyleRule, node data.Node) [][2]string {
+	if isElement(node) == false {
+		return nil
+	}
+	decls := []declaration{}
+	add := func(name, value string, origin int, important bool, specificity int) {
+		if name == "marker" {
+			for _, name := range []string{"marker-start", "marker-mid", "marker-end"} {
+				decls = append(decls, declaration{name, value, origin, important, specificity, len(decls)})
+			}
+		} else {
+			decls = append(decls, declaration{name, value, origin, important, specificity, len(decls)})
+		}
+	}
+
+	// Presentation attributes
+	for _, attr := range attrs(node) {
+		if attr.Name.Space == "" && isPresentationAttr(attr.Name.Local) {
+			add(attr.Name.Local, strings.TrimSpace(attr.Value), originAttr, false, 0)
+		}
+	}
+
+	// Style sheet rules which match the element
+	for _, rule := range matchingRules(rules, node) {
+		value, _ := rule.Value().(string)
+		add(rule.Name(), value, originStyleSheet, rule.Important(), specificity(rule))
+	}
+
+	// Style attribute
+	if attr, exists := node.Attr("style"); exists {
+		for _, decl := range parseStyleAttr(attr.Value) {
+			value, important := importantValue(decl[1])
+			add(decl[0], value, originStyleAttr, important, 0)
+		}
+	}
+
+	// Sort declarations by precedence, and keep the last declaration
+	// of each property
+	sort.SliceStable(decls, func(i, j int) bool {
+		a, b := decls[i], decls[j]
+		if a.important != b.important {
+			return b.important
+		} else if a.origin != b.origin {
+			return a.origin < b.origin
+		} else if a.specificity != b.specificity {
+			return a.specificity < b.specificity
+		}
+		return a.order < b.order
+	})
+	last := make(map[string]int, len(decls))
+	for i, decl := range decls {
+		last[decl.name] = i
+	}
+	result := make([][2]string, 0, len(last))
+	for i, decl := range decls {
+		if last[decl.name] == i {
+			result = append(result, [2]string{decl.name, decl.value})
+		}
+	}
+	return result
+}
+
+// matchingRules returns the style sheet rules which match the tag, id
+// and classes of a node, ignoring any pseudo-class
+func matchingRules(rules []data.StyleRule, node data.Node) []data.StyleRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	tag, id, classes := node.Name().Local, "", []string{}
+	if attr, exists := node.Attr("id"); exists {
+		id = strings.TrimSpace(attr.Value)
+	}
+	if attr, exists := node.Attr("class"); exists {
+		classes = strings.Fields(attr.Value)
+	}
+	result := []data.StyleRule{}
+	for _, rule := range rules {
+		if rule.Tag() != "" && rule.Tag() != tag {
+			continue
+		} else if rule.Id() != "" && rule.Id() != id {
+			continue
+		} else if rule.Class() != "" && stringInList(rule.Class(), classes) == false {
+			continue
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// property returns the value of a property declared for a node, which
+// is not inherited from the parent element
+func property(rules []data.StyleRule, node data.Node, name string) (string, bool) {
+	for _, decl := range cascade(rules, node) {
+		if decl[0] == name {
+			return decl[1], true
+		}
+	}
+	return "", false
+}
+
+// specificity returns the specificity of the selector for a rule
+func specificity(rule data.StyleRule) int {
+	value := 0
+	if rule.Id() != "" {
+		value += 100
+	}
+	if rule.Class() != "" {
+		value += 10
+	}
+	if rule.State() != "" {
+		value += 10
+	}
+	if rule.Tag() != "" {
+		value += 1
+	}
+	return value
+}
+
+// importantValue returns a value without any "!important" suffix, and
+// true if the suffix was present
+func importantValue(value string) (string, bool) {
+	if i := strings.LastIndex(value, "!"); i >= 0 && strings.EqualFold(strings.TrimSpace(value[i+1:]), "important") {
+		return strings.TrimSpace(value[:i]), true
+	}
+	return value, false
+}
+
+// isPresentationAttr returns true if an attribute can be used in place
+// of a style property
+func isPresentationAttr(name string) bool {
+	return stringInList(name, presentationAttrs)
+}
+
+// isInherited returns true if a property is inherited from the parent
+// element when it is not declared
+func isInherited(name string) bool {
+	for _, prefix := range []string{"fill", "stroke", "font", "marker"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return stringInList(name, inheritedProps)
+}
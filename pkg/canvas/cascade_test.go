@@ -0,0 +1,120 @@
+package canvas_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+	stylesheet "github.com/djthorpe/data/pkg/stylesheet"
+)
+
+func Test_Cascade_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	s := stylesheet.NewStyleSheet()
+	s.Add("*", "stroke-width: 3")
+	s.Add("rect#special", "fill: green")
+	s.Add("rect.major", "fill: blue; stroke: red")
+	s.Add("rect", "fill: yellow; opacity: 0.5")
+	s.Add(".major", "font-size: 12 !important")
+	s.Add("rect:hover", "fill: purple")
+	c.StyleSheet(s)
+
+	rect := c.Rect(data.ZeroPoint, data.Size{10, 10}).Class("minor major")
+	special := c.Rect(data.ZeroPoint, data.Size{10, 10}).Class("major").Id("special").Style(c.FontSize(20, data.PX))
+	g := c.Group(rect, special).Style(c.FontFamily("sans-serif"))
+
+	// The more specific rule and inherited values are used
+	style := c.ComputedStyle(rect)
+	tests := map[string]string{
+		"fill":         "blue",
+		"stroke":       "red",
+		"stroke-width": "3",
+		"opacity":      "0.5",
+		"font-size":    "12",
+		"font-family":  "sans-serif",
+		"fill-rule":    "nonzero",
+	}
+	for name, value := range tests {
+		if style[name] != value {
+			t.Errorf("%v: expected %q, got %v", name, value, style[name])
+		}
+	}
+
+	// The id selector takes precedence and important rules take
+	// precedence over the style attribute
+	style = c.ComputedStyle(special)
+	if style["fill"] != "green" {
+		t.Error("Unexpected fill, got: ", style["fill"])
+	} else if style["font-size"] != "12" {
+		t.Error("Unexpected font size, got: ", style["font-size"])
+	}
+
+	// Values which are not declared have their initial value
+	if style := c.ComputedStyle(g); style["fill"] != "black" || style["stroke-width"] != "3" {
+		t.Error("Unexpected style, got: ", style)
+	}
+
+	// Elements not on the canvas have no style
+	if style := c.ComputedStyle(nil); style != nil {
+		t.Error("Unexpected style, got: ", style)
+	}
+}
+
+func Test_Cascade_002(t *testing.T) {
+	// The style attribute takes precedence over presentation attributes
+	// and style sheets, and values can be inherited explicitly
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 20 10">
+		<style>.c { fill: blue } circle { stroke: green }</style>
+		<g fill="red" stroke-width="4" overflow="hidden">
+			<circle cx="5" cy="5" r="5" class="c" fill="yellow" style="stroke: inherit; overflow: inherit" />
+			<circle cx="15" cy="5" r="5" fill="yellow" style="fill: inherit !important; stroke-width: initial" />
+		</g>
+	</svg>`
+	c, err := canvas.Read(data.SVG, strings.NewReader(svg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := c.ComputedStyle(c.ElementsAt(data.Point{5, 5})[0])
+	if a["fill"] != "blue" || a["stroke"] != "none" || a["overflow"] != "hidden" || a["stroke-width"] != "4" {
+		t.Error("Unexpected style, got: ", a)
+	}
+	b := c.ComputedStyle(c.ElementsAt(data.Point{15, 5})[0])
+	if b["fill"] != "red" || b["stroke"] != "green" || b["stroke-width"] != "1" || b["overflow"] != nil {
+		t.Error("Unexpected style, got: ", b)
+	}
+}
+
+func Test_Cascade_003(t *testing.T) {
+	// Style sheets are used when rendering and hit testing
+	c := canvas.NewCanvas(data.Size{20, 10}, data.PX)
+	s := stylesheet.NewStyleSheet()
+	s.Add(".red", "fill: red")
+	s.Add(".hollow", "fill: none; stroke: black; stroke-width: 4")
+	c.StyleSheet(s)
+	c.Rect(data.ZeroPoint, data.Size{10, 10}).Class("red").Style(c.Fill(color.Blue, 1))
+	c.Rect(data.Point{10, 0}, data.Size{10, 10}).Class("hollow").Id("hollow")
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, a := img.At(5, 5).RGBA(); r != 0 || g != 0 || b != 0xFFFF || a != 0xFFFF {
+		t.Error("Unexpected color, got: ", img.At(5, 5))
+	}
+	if _, _, _, a := img.At(15, 5).RGBA(); a != 0 {
+		t.Error("Unexpected color, got: ", img.At(15, 5))
+	}
+	if ids := hitIds(c.ElementsAt(data.Point{15, 5})); ids != "" {
+		t.Error("Unexpected hit, got: ", ids)
+	} else if ids := hitIds(c.ElementsAt(data.Point{11, 5})); ids != "hollow" {
+		t.Error("Unexpected hit, got: ", ids)
+	}
+}
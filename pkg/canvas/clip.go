@@ -0,0 +1,138 @@
+package canvas
+
+import (
+	"strings"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// clipshape is a path within a clipping path, in the user space of
+// the element which is clipped
+type clipshape struct {
+	path []pathop
+	rule data.FillRule
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// clipShapes returns the shapes of the clipping path referenced by an
+// element, or false if the element is not clipped. An element is clipped
+// entirely when there are no shapes
+func (this *Canvas) clipShapes(elem *Element, rules []data.StyleRule) ([]clipshape, bool) {
+	clip := this.reference(elem, rules, "clip-path", "clipPath")
+	if clip == nil {
+		return nil, false
+	}
+
+	// Determine the transform from the clipping path onto the element
+	m := identity
+	if attr, exists := clip.Attr("transform"); exists {
+		if m_, err := parseTransform(attr.Value); err == nil {
+			m = m_
+		}
+	}
+	if attr, exists := clip.Attr("clipPathUnits"); exists && strings.TrimSpace(attr.Value) == data.ObjectBoundingBox.String() {
+		if origin, size, exists := this.bounds(elem); exists == false {
+			return []clipshape{}, true
+		} else {
+			m = matrix{size.W, 0, 0, size.H, origin.X, origin.Y}.multiply(m)
+		}
+	}
+
+	// Add the shapes, with the clip rule inherited from the clipping path
+	rule := clipRule(rules, clip.Node, data.NonZero)
+	shapes := []clipshape{}
+	for _, child := range clip.Children() {
+		shape := &Element{child, this}
+		path, err := shape.geometry()
+		if err != nil || len(path) == 0 {
+			continue
+		}
+		cm := m
+		if attr, exists := child.Attr("transform"); exists {
+			if m_, err := parseTransform(attr.Value); err == nil {
+				cm = cm.multiply(m_)
+			}
+		}
+		shapes = append(shapes, clipshape{transformPath(cm, path), clipRule(rules, child, rule)})
+	}
+
+	// Return shapes
+	return shapes, true
+}
+
+// maskFor returns the mask referenced by an element and the transform
+// for the contents of the mask, or nil if the element is not masked
+func (this *Canvas) maskFor(elem *Element, rules []data.StyleRule) (*Element, matrix) {
+	mask := this.reference(elem, rules, "mask", "mask")
+	if mask == nil {
+		return nil, identity
+	}
+	if attr, exists := mask.Attr("maskContentUnits"); exists && strings.TrimSpace(attr.Value) == data.ObjectBoundingBox.String() {
+		if origin, size, exists := this.bounds(elem); exists {
+			return mask, matrix{size.W, 0, 0, size.H, origin.X, origin.Y}
+		}
+	}
+	return mask, identity
+}
+
+// reference returns the element referenced by a property in the form
+// url(#id), or nil if there is no element with the tag name
+func (this *Canvas) reference(elem *Element, rules []data.StyleRule, name, tag string) *Element {
+	value, exists := property(rules, elem.Node, name)
+	if exists == false {
+		return nil
+	}
+	if _, ref, ok := parsePaint(value); ok == false || ref == "" {
+		return nil
+	} else if node := this.Document.GetElementById(ref); node == nil {
+		return nil
+	} else if elem := (&Element{node, this}); elem.isElement(tag) == false {
+		return nil
+	} else {
+		return elem
+	}
+}
+
+// clipRule returns the clip rule for a node, or the inherited rule
+func clipRule(rules []data.StyleRule, node data.Node, rule data.FillRule) data.FillRule {
+	if value, exists := property(rules, node, "clip-rule"); exists {
+		switch value {
+		case "evenodd":
+			return data.EvenOdd
+		case "nonzero":
+			return data.NonZero
+		}
+	}
+	return rule
+}
+
+// attrOrStyle returns the value of a property from the style attribute
+// of a node, or from a presentation attribute
+func attrOrStyle(node data.Node, name string) (string, bool) {
+	value, found := "", false
+	if attr, exists := node.Attr(name); exists {
+		value, found = strings.TrimSpace(attr.Value), true
+	}
+	if attr, exists := node.Attr("style"); exists {
+		for _, decl := range parseStyleAttr(attr.Value) {
+			if decl[0] == name {
+				value, found = decl[1], true
+			}
+		}
+	}
+	return value, found
+}
+
+// transformPath returns a path with points transformed by a matrix
+func transformPath(m matrix, path []pathop) []pathop {
+	result := make([]pathop, len(path))
+	for i, seg := range path {
+		result[i] = pathop{seg.op, transformPoints(m, seg.pts)}
+	}
+	return result
+}
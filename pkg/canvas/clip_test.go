@@ -0,0 +1,93 @@
+package canvas_test
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_Clip_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	p := c.ClipPath(c.Rect(data.Point{10, 10}, data.Size{80, 80}))
+	if p == nil {
+		t.Fatal("Unexpected nil from c.ClipPath")
+	} else if p.Id("plot") == nil {
+		t.Fatal("Unexpected nil from p.Id")
+	} else if str := fmt.Sprint(c.DOM().FirstChild()); str != `<defs><clipPath id="plot"><rect x="10" y="10" width="80" height="80"></rect></clipPath></defs>` {
+		t.Error("Expected clipping path in defs, got: ", str)
+	}
+	if l := c.Line(data.ZeroPoint, data.Point{100, 100}).Style(c.Clip("plot")); l == nil {
+		t.Error("Unexpected nil from c.Line")
+	} else if str := fmt.Sprint(l); str != `<line x1="0" y1="0" x2="100" y2="100" style="clip-path: url(#plot);"></line>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Clip_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	m := c.Mask(c.Circle(data.Point{50, 50}, 50).Style(c.Fill(color.White, 1)))
+	if m == nil {
+		t.Fatal("Unexpected nil from c.Mask")
+	} else if m.Id("spot") == nil {
+		t.Fatal("Unexpected nil from m.Id")
+	} else if str := fmt.Sprint(m); str != `<mask id="spot"><circle cx="50" cy="50" r="50" style="fill: white; fill-opacity: 1;"></circle></mask>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if g := c.Group().Style(c.MaskWith("#spot")); g == nil {
+		t.Error("Unexpected nil from c.Group")
+	} else if str := fmt.Sprint(g); str != `<g style="mask: url(#spot);"></g>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Clip_003(t *testing.T) {
+	// Rectangle clipped to the left half, and a rectangle masked to the top half
+	c := canvas.NewCanvas(data.Size{20, 20}, data.PX)
+	c.ClipPath(c.Rect(data.ZeroPoint, data.Size{10, 20})).Id("left")
+	c.Mask(c.Rect(data.ZeroPoint, data.Size{20, 10}).Style(c.Fill(color.White, 1))).Id("top")
+	c.Rect(data.ZeroPoint, data.Size{20, 20}).Style(c.Fill(color.Red, 1), c.Clip("left"))
+	c.Rect(data.ZeroPoint, data.Size{20, 20}).Style(c.Fill(color.Blue, 1), c.MaskWith("top"))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		x, y    int
+		r, g, b uint32
+		a       uint32
+	}{
+		{5, 5, 0, 0, 0xFFFF, 0xFFFF},
+		{15, 5, 0, 0, 0xFFFF, 0xFFFF},
+		{5, 15, 0xFFFF, 0, 0, 0xFFFF},
+		{15, 15, 0, 0, 0, 0},
+	} {
+		if r, g, b, a := img.At(test.x, test.y).RGBA(); r != test.r || g != test.g || b != test.b || a != test.a {
+			t.Error("Unexpected color at ", test.x, ",", test.y, ": ", img.At(test.x, test.y))
+		}
+	}
+}
+
+func Test_Clip_004(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.ClipPath(c.Rect(data.ZeroPoint, data.Size{50, 50})).Id("quarter")
+	c.Circle(data.Point{50, 50}, 50).Style(c.Fill(color.Red, 1), c.Clip("quarter"))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PDF, b); err != nil {
+		t.Fatal(err)
+	}
+	if str := b.String(); strings.Contains(str, "h\nW n\n") == false {
+		t.Error("Missing clipping path")
+	}
+}
@@ -98,7 +98,7 @@ func (this *Element) Transform(transforms ...data.CanvasTransform) data.CanvasEl
 	// Set attribute
 	if len(attr) == 0 {
 		return this
-	} else if err := this.SetAttr("transform", strings.Join(attr, " ")); err != nil {
+	} else if err := this.SetAttr(this.transformAttr(), strings.Join(attr, " ")); err != nil {
 		return nil
 	} else {
 		return this
@@ -128,6 +128,19 @@ func (this *Element) isElement(tags ...string) bool {
 	return false
 }
 
+// transformAttr returns the name of the transform attribute, which
+// differs for paint servers
+func (this *Element) transformAttr() string {
+	switch {
+	case this.isElement("linearGradient", "radialGradient"):
+		return "gradientTransform"
+	case this.isElement("pattern"):
+		return "patternTransform"
+	default:
+		return "transform"
+	}
+}
+
 func (this *Element) isAttrId(value string) bool {
 	return reAttrId.MatchString(value)
 }
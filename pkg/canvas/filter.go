@@ -0,0 +1,253 @@
+package canvas
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/color"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+var (
+	// Filter region for drop shadows, which extends beyond the bounding
+	// box of the element so the shadow is not clipped
+	dropShadowRegion = [4]string{"-50%", "-50%", "200%", "200%"}
+)
+
+/////////////////////////////////////////////////////////////////////
+// FILTER
+
+// Filter defines a filter with a sequence of primitives, which is
+// added to the canvas definitions
+func (this *Canvas) Filter(primitives ...data.CanvasFilter) data.CanvasGroup {
+	f, err := this.NewElement("filter")
+	if err != nil {
+		return nil
+	}
+
+	// Append primitives. If any primitives are nil, then return nil to
+	// bubble up any errors
+	for _, primitive := range primitives {
+		if primitive == nil {
+			return nil
+		} else if elem, ok := primitive.(*Element); ok == false || elem.isFilterPrimitive() == false {
+			return nil
+		} else if err := f.AddChild(elem.Node); err != nil {
+			return nil
+		}
+	}
+
+	// Move filter into the definitions
+	if defs := this.defs(); defs == nil {
+		return nil
+	} else if err := defs.AddChild(f.Node); err != nil {
+		return nil
+	}
+
+	// Return filter
+	return f
+}
+
+/////////////////////////////////////////////////////////////////////
+// FILTER PRIMITIVES
+
+// GaussianBlur returns a primitive which blurs the input with a
+// standard deviation in user units
+func (this *Canvas) GaussianBlur(in string, deviation float32) data.CanvasFilter {
+	if deviation < 0 {
+		return nil
+	}
+	elem, err := this.newFilterPrimitive("feGaussianBlur", in)
+	if err != nil {
+		return nil
+	}
+	elem.SetAttr("stdDeviation", f32.String(deviation))
+	return elem
+}
+
+// Offset returns a primitive which moves the input by a distance
+func (this *Canvas) Offset(in string, delta data.Point) data.CanvasFilter {
+	elem, err := this.newFilterPrimitive("feOffset", in)
+	if err != nil {
+		return nil
+	}
+	elem.SetAttr("dx", f32.String(delta.X))
+	elem.SetAttr("dy", f32.String(delta.Y))
+	return elem
+}
+
+// ColorMatrix returns a primitive which transforms the color of each
+// pixel of the input with a matrix of four rows and five columns,
+// applied to the red, green, blue and alpha values and a constant
+func (this *Canvas) ColorMatrix(in string, matrix [20]float32) data.CanvasFilter {
+	elem, err := this.newFilterPrimitive("feColorMatrix", in)
+	if err != nil {
+		return nil
+	}
+	elem.SetAttr("type", "matrix")
+	elem.SetAttr("values", f32.Join(matrix[:], " "))
+	return elem
+}
+
+// Saturate returns a primitive which changes the saturation of the
+// input, where zero produces a grayscale image and one leaves the input
+// unchanged
+func (this *Canvas) Saturate(in string, value float32) data.CanvasFilter {
+	if value < 0 {
+		return nil
+	}
+	elem, err := this.newFilterPrimitive("feColorMatrix", in)
+	if err != nil {
+		return nil
+	}
+	elem.SetAttr("type", "saturate")
+	elem.SetAttr("values", f32.String(value))
+	return elem
+}
+
+// Flood returns a primitive which fills the filter region with a color
+// and opacity
+func (this *Canvas) Flood(color_ data.Color, opacity float32) data.CanvasFilter {
+	elem, err := this.newFilterPrimitive("feFlood", "")
+	if err != nil {
+		return nil
+	}
+	elem.SetAttr("flood-color", color.String(color_))
+	elem.SetAttr("flood-opacity", f32.String(f32.Max(0, f32.Min(opacity, 1))))
+	return elem
+}
+
+// Composite returns a primitive which combines two inputs with a
+// Porter-Duff compositing operation, where the first input is drawn
+// over the second
+func (this *Canvas) Composite(in, in2 string, op data.Composite) data.CanvasFilter {
+	in2 = strings.TrimSpace(in2)
+	if in2 == "" {
+		return nil
+	}
+	elem, err := this.newFilterPrimitive("feComposite", in)
+	if err != nil {
+		return nil
+	}
+	elem.SetAttr("in2", in2)
+	elem.SetAttr("operator", fmt.Sprint(op))
+	return elem
+}
+
+// Merge returns a primitive which draws inputs over each other in
+// order, where an empty input is the result of the previous primitive
+func (this *Canvas) Merge(in ...string) data.CanvasFilter {
+	if len(in) == 0 {
+		return nil
+	}
+	elem, err := this.newFilterPrimitive("feMerge", "")
+	if err != nil {
+		return nil
+	}
+	for _, in := range in {
+		node := this.Document.CreateElementNS("feMergeNode", data.XmlNamespaceSVG)
+		if in = strings.TrimSpace(in); in != "" {
+			node.SetAttr("in", in)
+		}
+		if err := elem.AddChild(node); err != nil {
+			return nil
+		}
+	}
+	return elem
+}
+
+/////////////////////////////////////////////////////////////////////
+// FILTER PRIMITIVE METHODS
+
+func (this *Element) Result(name string) data.CanvasFilter {
+	name = strings.TrimSpace(name)
+	if this.isFilterPrimitive() == false {
+		return nil
+	} else if name == "" {
+		if err := this.RemoveAttr("result"); err != nil {
+			return nil
+		}
+	} else if err := this.SetAttr("result", name); err != nil {
+		return nil
+	}
+
+	// Return primitive
+	return this
+}
+
+/////////////////////////////////////////////////////////////////////
+// STYLES
+
+func (*Canvas) FilterWith(id string) data.CanvasStyle {
+	return &styledef{Op: filterRef, Uri: urlForId(id)}
+}
+
+// DropShadow returns a style which draws a shadow of an element in a
+// color, moved by an offset and blurred with a standard deviation. The
+// filter is added to the canvas definitions, and shared between
+// elements with the same shadow
+func (this *Canvas) DropShadow(offset data.Point, blur float32, color_ data.Color) data.CanvasStyle {
+	if blur < 0 {
+		return nil
+	}
+
+	// Return an existing filter with the same parameters
+	hash := fnv.New32a()
+	fmt.Fprint(hash, offset, blur, color_)
+	id := fmt.Sprintf("shadow-%08x", hash.Sum32())
+	if this.Document.GetElementById(id) != nil {
+		return this.FilterWith(id)
+	}
+
+	// Blur the alpha channel, move and color it, then draw the source
+	// graphic over the shadow
+	shadow := this.Offset("", offset)
+	if shadow == nil {
+		return nil
+	}
+	f := this.Filter(
+		this.GaussianBlur("SourceAlpha", blur),
+		shadow.Result("shadow"),
+		this.Flood(color_, 1),
+		this.Composite("", "shadow", data.CompositeIn),
+		this.Merge("", "SourceGraphic"),
+	)
+	if f == nil {
+		return nil
+	} else if elem := f.(*Element); elem.Id(id) == nil {
+		return nil
+	} else {
+		for i, attr := range []string{"x", "y", "width", "height"} {
+			elem.SetAttr(attr, dropShadowRegion[i])
+		}
+	}
+
+	// Return the filter reference
+	return this.FilterWith(id)
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// newFilterPrimitive returns a filter primitive with an input, which is
+// omitted when empty
+func (this *Canvas) newFilterPrimitive(name, in string) (*Element, error) {
+	elem, err := this.NewElement(name)
+	if err != nil {
+		return nil, err
+	}
+	if in = strings.TrimSpace(in); in != "" {
+		elem.SetAttr("in", in)
+	}
+	return elem, nil
+}
+
+// isFilterPrimitive returns true if the element is a filter primitive
+func (this *Element) isFilterPrimitive() bool {
+	return this.isElement("feGaussianBlur", "feOffset", "feColorMatrix", "feFlood", "feComposite", "feMerge")
+}
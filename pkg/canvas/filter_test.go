@@ -0,0 +1,104 @@
+package canvas_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_Filter_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	f := c.Filter(c.Saturate("", 0))
+	if f == nil {
+		t.Fatal("Unexpected nil from c.Filter")
+	} else if f.Id("grayscale") == nil {
+		t.Fatal("Unexpected nil from f.Id")
+	} else if str := fmt.Sprint(c.DOM().FirstChild()); str != `<defs><filter id="grayscale"><feColorMatrix type="saturate" values="0"></feColorMatrix></filter></defs>` {
+		t.Error("Expected filter in defs, got: ", str)
+	}
+	if i := c.Image(data.ZeroPoint, data.Size{50, 50}, "logo.png").Style(c.FilterWith("grayscale")); i == nil {
+		t.Error("Unexpected nil from c.Image")
+	} else if str := fmt.Sprint(i); strings.Contains(str, `style="filter: url(#grayscale);"`) == false {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Filter_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	if f := c.Filter(
+		c.GaussianBlur("SourceGraphic", 2).Result("blur"),
+		c.Offset("blur", data.Point{1, 2}),
+		c.Flood(color.Red, 2),
+		c.Composite("", "blur", data.CompositeXor),
+		c.ColorMatrix("", [20]float32{1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0}),
+		c.Merge("", "SourceGraphic"),
+	); f == nil {
+		t.Fatal("Unexpected nil from c.Filter")
+	} else if str := fmt.Sprint(f); str != `<filter>`+
+		`<feGaussianBlur in="SourceGraphic" stdDeviation="2" result="blur"></feGaussianBlur>`+
+		`<feOffset in="blur" dx="1" dy="2"></feOffset>`+
+		`<feFlood flood-color="red" flood-opacity="1"></feFlood>`+
+		`<feComposite in2="blur" operator="xor"></feComposite>`+
+		`<feColorMatrix type="matrix" values="1 0 0 0 0 0 1 0 0 0 0 0 1 0 0 0 0 0 1 0"></feColorMatrix>`+
+		`<feMerge><feMergeNode></feMergeNode><feMergeNode in="SourceGraphic"></feMergeNode></feMerge>`+
+		`</filter>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+
+	// Invalid primitives
+	if c.GaussianBlur("", -1) != nil {
+		t.Error("Expected nil for negative deviation")
+	}
+	if c.Composite("", "", data.CompositeIn) != nil {
+		t.Error("Expected nil for missing input")
+	}
+	if c.Merge() != nil {
+		t.Error("Expected nil for merge without inputs")
+	}
+	if c.Filter(c.GaussianBlur("", -1)) != nil {
+		t.Error("Expected nil for filter with invalid primitive")
+	}
+	if c.Filter(c.Rect(data.ZeroPoint, data.Size{10, 10}).(data.CanvasFilter)) != nil {
+		t.Error("Expected nil for filter with shape")
+	}
+}
+
+func Test_Filter_003(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	shadow := c.DropShadow(data.Point{2, 2}, 3, color.Black)
+	if shadow == nil {
+		t.Fatal("Unexpected nil from c.DropShadow")
+	}
+	c.Rect(data.Point{10, 10}, data.Size{40, 20}).Style(c.Fill(color.White, 1), shadow)
+	c.Rect(data.Point{10, 40}, data.Size{40, 20}).Style(c.Fill(color.White, 1), c.DropShadow(data.Point{2, 2}, 3, color.Black))
+
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if n := strings.Count(str, "<filter "); n != 1 {
+		t.Error("Expected one shared filter, got: ", str)
+	}
+	if strings.Contains(str, `<feOffset dx="2" dy="2" result="shadow"></feOffset><feFlood flood-color="black" flood-opacity="1"></feFlood><feComposite in2="shadow" operator="in"></feComposite><feMerge><feMergeNode></feMergeNode><feMergeNode in="SourceGraphic"></feMergeNode></feMerge>`) == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+	if n := strings.Count(str, `filter: url(#shadow-`); n != 2 {
+		t.Error("Expected two references to the filter, got: ", str)
+	}
+
+	// Filters are read back, and survive optimisation
+	if _, err := canvas.Read(data.SVG, strings.NewReader(str)); err != nil {
+		t.Error(err)
+	}
+	b.Reset()
+	if err := c.Write(data.Optimise|data.Minify, b); err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(b.String(), `<feMergeNode in="SourceGraphic"`) == false {
+		t.Error("Unexpected optimised output, got: ", b.String())
+	}
+}
@@ -0,0 +1,213 @@
+package canvas
+
+import (
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+	"github.com/djthorpe/data/pkg/font"
+	"github.com/djthorpe/data/pkg/geom"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// stdfont is one of the standard fonts which are available in all
+// PDF readers, with the name used in PDF documents
+type stdfont struct {
+	name string
+	data.Font
+}
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// AddFont registers a font, which is used to measure text in the font
+// family. Fonts are usually read with font.Open
+func (this *Canvas) AddFont(font data.Font) error {
+	if font == nil || font.Family() == "" {
+		return data.ErrBadParameter.WithPrefix("AddFont")
+	}
+	this.fonts = append(this.fonts, font)
+	return nil
+}
+
+// Font returns the registered font which best matches a font family and
+// variant, or bundled metrics for the family when no font is registered
+func (this *Canvas) Font(family string, variant data.FontVariant) data.Font {
+	var best data.Font
+	score := 0
+	for _, font := range this.fonts {
+		if strings.EqualFold(font.Family(), strings.Trim(strings.TrimSpace(family), "\"'")) == false {
+			continue
+		}
+		if s := variantDistance(font.Variant(), variant); best == nil || s < score {
+			best, score = font, s
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return font.Generic(family, variant)
+}
+
+// MeasureText returns the advance width of a string and the height of
+// a font for a font size
+func (this *Canvas) MeasureText(f data.Font, size float32, value string) data.Size {
+	return font.MeasureText(f, size, value)
+}
+
+// TextBounds returns the bounding box of a text element from the metrics
+// of the fonts used, in the co-ordinate system of the element before any
+// transform is applied. Returns a zero size if the element is not a
+// text element or contains no text
+func (this *Canvas) TextBounds(elem data.CanvasElement) (data.Point, data.Size) {
+	if elem, ok := elem.(*Element); ok == false || elem.isElement("text") == false {
+		return data.ZeroPoint, data.ZeroSize
+	} else if pts := this.textPoints(elem, this.inheritedStyle(elem.Node)); len(pts) == 0 {
+		return data.ZeroPoint, data.ZeroSize
+	} else {
+		return pathBounds([]subpath{{pts: pts}})
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// fontFor returns the font used to measure text in a style, which is the
+// first registered font in the font family list, or otherwise the
+// standard font
+func (this *Canvas) fontFor(style *renderstyle) data.Font {
+	if len(this.fonts) > 0 {
+		for _, family := range strings.Split(style.fontFamily, ",") {
+			family = strings.Trim(strings.TrimSpace(family), "\"'")
+			for _, font := range this.fonts {
+				if strings.EqualFold(font.Family(), family) {
+					return this.Font(family, style.variant())
+				}
+			}
+		}
+	}
+	return style.font()
+}
+
+// measure returns the advance width of a string in a style
+func (this *Canvas) measure(style *renderstyle, value string) float32 {
+	return this.fontFor(style).Width(style.fontSize, value)
+}
+
+// textPoints returns the corners of the boxes around the text runs in a
+// text element with a computed style, from the ascent to the descent
+// of each font
+func (this *Canvas) textPoints(elem *Element, style *renderstyle) []data.Point {
+	var pts []data.Point
+	layout := &textlayout{canvas: this}
+	layout.layout(elem.Node, style)
+	for _, runs := range layout.chunked() {
+		shift := this.anchorShift(runs)
+		for _, run := range runs {
+			metrics := this.fontFor(run.style).Metrics(run.style.fontSize)
+			width := this.measure(run.style, run.value)
+			if run.path == nil {
+				x0, x1 := run.pt.X+shift, run.pt.X+shift+width
+				pts = append(pts,
+					data.Point{x0, run.pt.Y - metrics.Ascent}, data.Point{x1, run.pt.Y - metrics.Ascent},
+					data.Point{x1, run.pt.Y + metrics.Descent}, data.Point{x0, run.pt.Y + metrics.Descent},
+				)
+				continue
+			}
+
+			// Text on a path is bounded by the ascent and descent around
+			// each glyph on the path
+			d := run.pt.X + shift
+			for _, glyph := range run.value {
+				w := this.measure(run.style, string(glyph))
+				if pt, _, ok := geom.PointAtLength(d+w/2, run.path); ok {
+					r := f32.Max(metrics.Ascent+metrics.Descent, w)
+					pts = append(pts, data.Point{pt.X - r, pt.Y - r}, data.Point{pt.X + r, pt.Y + r})
+				}
+				d += w
+			}
+		}
+	}
+	return pts
+}
+
+// font returns the standard font which best matches the font family,
+// weight and style
+func (this *renderstyle) font() *stdfont {
+	family := "serif"
+	for _, name := range strings.Split(this.fontFamily, ",") {
+		if _, exists := font.StandardName(name, 0); exists {
+			family = name
+			break
+		}
+	}
+	name, _ := font.StandardName(family, this.variant())
+	return &stdfont{name, font.Standard(name)}
+}
+
+// variant returns the font variant for the font weight and style
+func (this *renderstyle) variant() data.FontVariant {
+	variant := data.Regular
+	switch this.fontWeight {
+	case "100":
+		variant = data.Thin
+	case "200":
+		variant = data.ExtraLight
+	case "300", "lighter":
+		variant = data.Light
+	case "500":
+		variant = data.Medium
+	case "600":
+		variant = data.SemiBold
+	case "bold", "bolder", "700":
+		variant = data.Bold
+	case "800":
+		variant = data.ExtraBold
+	case "900":
+		variant = data.Black
+	}
+	switch this.fontStyle {
+	case "italic":
+		variant |= data.Italic
+	case "oblique":
+		variant |= data.Oblique
+	}
+	return variant
+}
+
+// variantDistance returns how closely a font variant matches a requested
+// variant, where zero is an exact match
+func variantDistance(a, b data.FontVariant) int {
+	slant := data.Italic | data.Oblique
+	distance := 0
+	if a&slant != b&slant {
+		distance += 1000
+	}
+	return distance + abs(weight(a)-weight(b))
+}
+
+// weight returns the numeric weight of a font variant
+func weight(variant data.FontVariant) int {
+	for i, w := range []data.FontVariant{data.Thin, data.ExtraLight, data.Light, data.Regular, data.Medium, data.SemiBold, data.Bold, data.ExtraBold, data.Black} {
+		if variant&w != 0 {
+			return (i + 1) * 100
+		}
+	}
+	switch {
+	case variant&data.Bolder != 0:
+		return 700
+	case variant&data.Lighter != 0:
+		return 300
+	default:
+		return 400
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,187 @@
+package canvas
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+	"github.com/djthorpe/data/pkg/geom"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// pathop is a path segment with absolute co-ordinates, where op
+// is one of M, L, Q, C or Z and the end point is the last point
+type pathop struct {
+	op  byte
+	pts []data.Point
+}
+
+// subpath is a flattened series of points
+type subpath struct {
+	pts    []data.Point
+	closed bool
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Approximation of a quarter circle with a cubic curve
+	kappa = 0.5522847498
+)
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// geometry returns the path for a drawing primitive, or nil if the
+// element is not a drawing primitive
+func (this *Element) geometry() ([]pathop, error) {
+	switch {
+	case this.isElement("rect"):
+		pt := data.Point{this.attrFloat("x", 0), this.attrFloat("y", 0)}
+		sz := data.Size{this.attrFloat("width", 0), this.attrFloat("height", 0)}
+		rx, ry := this.attrFloat("rx", f32.NaN()), this.attrFloat("ry", f32.NaN())
+		if f32.IsNaN(rx) {
+			rx = ry
+		} else if f32.IsNaN(ry) {
+			ry = rx
+		}
+		return rectPath(pt, sz, data.Size{rx, ry}), nil
+	case this.isElement("circle"):
+		r := this.attrFloat("r", 0)
+		return ellipsePath(data.Point{this.attrFloat("cx", 0), this.attrFloat("cy", 0)}, data.Size{r, r}), nil
+	case this.isElement("ellipse"):
+		return ellipsePath(data.Point{this.attrFloat("cx", 0), this.attrFloat("cy", 0)}, data.Size{this.attrFloat("rx", 0), this.attrFloat("ry", 0)}), nil
+	case this.isElement("line"):
+		return []pathop{
+			{'M', []data.Point{{this.attrFloat("x1", 0), this.attrFloat("y1", 0)}}},
+			{'L', []data.Point{{this.attrFloat("x2", 0), this.attrFloat("y2", 0)}}},
+		}, nil
+	case this.isElement("polyline", "polygon"):
+		attr, _ := this.Attr("points")
+		pts, err := parsePoints(attr.Value)
+		if err != nil {
+			return nil, err
+		}
+		path := make([]pathop, 0, len(pts)+1)
+		for i, pt := range pts {
+			if i == 0 {
+				path = append(path, pathop{'M', []data.Point{pt}})
+			} else {
+				path = append(path, pathop{'L', []data.Point{pt}})
+			}
+		}
+		if len(path) > 0 && this.isElement("polygon") {
+			path = append(path, pathop{'Z', nil})
+		}
+		return path, nil
+	case this.isElement("path"):
+		attr, _ := this.Attr("d")
+		return parsePathData(attr.Value)
+	default:
+		return nil, nil
+	}
+}
+
+// attrFloat returns an attribute value as a number, or the default
+// value if the attribute does not exist or cannot be parsed
+func (this *Element) attrFloat(name string, def float32) float32 {
+	if attr, exists := this.Attr(name); exists == false {
+		return def
+	} else if v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(attr.Value, "px")), 32); err != nil {
+		return def
+	} else {
+		return float32(v)
+	}
+}
+
+// rectPath returns the path for a rectangle with optional rounded corners
+func rectPath(pt data.Point, sz data.Size, r data.Size) []pathop {
+	if sz.W <= 0 || sz.H <= 0 {
+		return nil
+	}
+	rx := f32.Min(f32.Max(r.W, 0), sz.W/2)
+	ry := f32.Min(f32.Max(r.H, 0), sz.H/2)
+	if f32.IsNaN(rx) || f32.IsNaN(ry) || rx == 0 || ry == 0 {
+		return []pathop{
+			{'M', []data.Point{pt}},
+			{'L', []data.Point{{pt.X + sz.W, pt.Y}}},
+			{'L', []data.Point{{pt.X + sz.W, pt.Y + sz.H}}},
+			{'L', []data.Point{{pt.X, pt.Y + sz.H}}},
+			{'Z', nil},
+		}
+	}
+	x0, y0, x1, y1 := pt.X, pt.Y, pt.X+sz.W, pt.Y+sz.H
+	kx, ky := rx*kappa, ry*kappa
+	return []pathop{
+		{'M', []data.Point{{x0 + rx, y0}}},
+		{'L', []data.Point{{x1 - rx, y0}}},
+		{'C', []data.Point{{x1 - rx + kx, y0}, {x1, y0 + ry - ky}, {x1, y0 + ry}}},
+		{'L', []data.Point{{x1, y1 - ry}}},
+		{'C', []data.Point{{x1, y1 - ry + ky}, {x1 - rx + kx, y1}, {x1 - rx, y1}}},
+		{'L', []data.Point{{x0 + rx, y1}}},
+		{'C', []data.Point{{x0 + rx - kx, y1}, {x0, y1 - ry + ky}, {x0, y1 - ry}}},
+		{'L', []data.Point{{x0, y0 + ry}}},
+		{'C', []data.Point{{x0, y0 + ry - ky}, {x0 + rx - kx, y0}, {x0 + rx, y0}}},
+		{'Z', nil},
+	}
+}
+
+// ellipsePath returns the path for an ellipse as four cubic curves
+func ellipsePath(c data.Point, r data.Size) []pathop {
+	if r.W <= 0 || r.H <= 0 {
+		return nil
+	}
+	kx, ky := r.W*kappa, r.H*kappa
+	return []pathop{
+		{'M', []data.Point{{c.X + r.W, c.Y}}},
+		{'C', []data.Point{{c.X + r.W, c.Y + ky}, {c.X + kx, c.Y + r.H}, {c.X, c.Y + r.H}}},
+		{'C', []data.Point{{c.X - kx, c.Y + r.H}, {c.X - r.W, c.Y + ky}, {c.X - r.W, c.Y}}},
+		{'C', []data.Point{{c.X - r.W, c.Y - ky}, {c.X - kx, c.Y - r.H}, {c.X, c.Y - r.H}}},
+		{'C', []data.Point{{c.X + kx, c.Y - r.H}, {c.X + r.W, c.Y - ky}, {c.X + r.W, c.Y}}},
+		{'Z', nil},
+	}
+}
+
+// flatten returns the path as a series of polylines, where tolerance
+// is the maximum distance between the curve and the polyline
+func flatten(path []pathop, tolerance float32) []subpath {
+	result := []subpath{}
+	current := -1
+	var start, pt data.Point
+	for _, seg := range path {
+		if seg.op != 'M' && seg.op != 'Z' && current < 0 {
+			result = append(result, subpath{pts: []data.Point{pt}})
+			current = len(result) - 1
+		}
+		switch seg.op {
+		case 'M':
+			result = append(result, subpath{pts: []data.Point{seg.pts[0]}})
+			current = len(result) - 1
+			start = seg.pts[0]
+		case 'L':
+			result[current].pts = append(result[current].pts, seg.pts[0])
+		case 'Q':
+			result[current].pts = append(result[current].pts, geom.FlattenQuadratic(pt, seg.pts[0], seg.pts[1], tolerance)...)
+		case 'C':
+			result[current].pts = append(result[current].pts, geom.FlattenCubic(pt, seg.pts[0], seg.pts[1], seg.pts[2], tolerance)...)
+		case 'Z':
+			if current >= 0 {
+				result[current].closed = true
+				current = -1
+			}
+			pt = start
+			continue
+		}
+		pt = seg.pts[len(seg.pts)-1]
+	}
+	return result
+}
+
+// hypot returns the length of the vector (x,y)
+func hypot(x, y float32) float32 {
+	return f32.Sqrt(x*x + y*y)
+}
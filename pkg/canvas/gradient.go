@@ -0,0 +1,129 @@
+package canvas
+
+import (
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/color"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// GRADIENTS
+
+func (this *Canvas) LinearGradient(id string, p1, p2 data.Point) data.CanvasGradient {
+	g, err := this.newDef("linearGradient", id)
+	if err != nil {
+		return nil
+	}
+
+	g.SetAttr("x1", f32.String(p1.X))
+	g.SetAttr("y1", f32.String(p1.Y))
+	g.SetAttr("x2", f32.String(p2.X))
+	g.SetAttr("y2", f32.String(p2.Y))
+	return g
+}
+
+func (this *Canvas) RadialGradient(id string, centre data.Point, radius float32) data.CanvasGradient {
+	g, err := this.newDef("radialGradient", id)
+	if err != nil {
+		return nil
+	}
+
+	g.SetAttr("cx", f32.String(centre.X))
+	g.SetAttr("cy", f32.String(centre.Y))
+	g.SetAttr("r", f32.String(f32.Abs(radius)))
+	return g
+}
+
+/////////////////////////////////////////////////////////////////////
+// GRADIENT METHODS
+
+func (this *Element) Stop(offset float32, c data.Color, opacity float32) data.CanvasGradient {
+	if this.isElement("linearGradient", "radialGradient") == false {
+		return nil
+	}
+	stop := this.Document.CreateElementNS("stop", data.XmlNamespaceSVG)
+	if stop == nil {
+		return nil
+	}
+	stop.SetAttr("offset", f32.String(f32.Max(f32.Min(offset, 1), 0)))
+	stop.SetAttr("stop-color", color.String(c))
+	if opacity != 1 {
+		stop.SetAttr("stop-opacity", f32.String(f32.Max(f32.Min(opacity, 1), 0)))
+	}
+	if err := this.AddChild(stop); err != nil {
+		return nil
+	}
+
+	// Return gradient
+	return this
+}
+
+func (this *Element) SpreadMethod(spread data.Spread) data.CanvasGradient {
+	if this.isElement("linearGradient", "radialGradient") == false {
+		return nil
+	}
+	if spread == data.SpreadPad {
+		this.RemoveAttr("spreadMethod")
+	} else if err := this.SetAttr("spreadMethod", spread.String()); err != nil {
+		return nil
+	}
+
+	// Return gradient
+	return this
+}
+
+func (this *Element) GradientUnits(units data.Coordinates) data.CanvasGradient {
+	if this.isElement("linearGradient", "radialGradient") == false {
+		return nil
+	}
+	if units == data.ObjectBoundingBox {
+		this.RemoveAttr("gradientUnits")
+	} else if err := this.SetAttr("gradientUnits", units.String()); err != nil {
+		return nil
+	}
+
+	// Return gradient
+	return this
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// newDef creates an element with an identifier within the definitions
+// for the canvas, creating the definitions if necessary
+func (this *Canvas) newDef(name, id string) (*Element, error) {
+	id = strings.TrimPrefix(strings.TrimSpace(id), "#")
+	if id == "" {
+		return nil, data.ErrBadParameter.WithPrefix(name, ": Missing id")
+	}
+	elem, err := this.NewElement(name)
+	if err != nil {
+		return nil, err
+	} else if elem.Id(id) == nil {
+		return nil, data.ErrBadParameter.WithPrefix(name, ": Invalid id")
+	} else if defs := this.defs(); defs == nil {
+		return nil, data.ErrInternalAppError.WithPrefix(name)
+	} else if err := defs.AddChild(elem.Node); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return elem, nil
+}
+
+// defs returns the first definitions element of the canvas, or creates
+// one if there are no definitions
+func (this *Canvas) defs() *Element {
+	for _, child := range this.Document.Children() {
+		if elem := (&Element{child, this}); elem.isElement("defs") {
+			return elem
+		}
+	}
+	if defs, ok := this.Defs().(*Element); ok {
+		return defs
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+package canvas_test
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_Gradient_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	g := c.LinearGradient("fade", data.Point{0, 0}, data.Point{0, 1}).
+		Stop(0, color.Red, 1).
+		Stop(1, color.Blue, 0.5).
+		SpreadMethod(data.SpreadReflect)
+	if g == nil {
+		t.Fatal("Unexpected nil from c.LinearGradient")
+	} else if str := fmt.Sprint(g); str != `<linearGradient id="fade" x1="0" y1="0" x2="0" y2="1" spreadMethod="reflect"><stop offset="0" stop-color="red"></stop><stop offset="1" stop-color="blue" stop-opacity="0.500000"></stop></linearGradient>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if str := fmt.Sprint(c.DOM().FirstChild()); str != `<defs>`+fmt.Sprint(g)+`</defs>` {
+		t.Error("Expected gradient in defs, got: ", str)
+	}
+}
+
+func Test_Gradient_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	g1 := c.RadialGradient("#glow", data.Point{50, 50}, 50).GradientUnits(data.UserSpaceOnUse).Transform(c.Scale(data.Size{1, 2}))
+	g2 := c.LinearGradient("shine", data.ZeroPoint, data.Point{1, 1})
+	if g1 == nil || g2 == nil {
+		t.Fatal("Unexpected nil from gradient")
+	} else if str := fmt.Sprint(g1); str != `<radialGradient id="glow" cx="50" cy="50" r="50" gradientUnits="userSpaceOnUse" gradientTransform="scale(1,2)"></radialGradient>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if defs := c.DOM().GetElementsByTagNameNS("defs", data.XmlNamespaceSVG); len(defs) != 1 {
+		t.Error("Expected one defs element")
+	} else if len(defs[0].Children()) != 2 {
+		t.Error("Expected two gradients in defs")
+	}
+	if g := c.LinearGradient("", data.ZeroPoint, data.ZeroPoint); g != nil {
+		t.Error("Expected nil for missing id")
+	}
+}
+
+func Test_Gradient_003(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	if r := c.Rect(data.ZeroPoint, data.Size{10, 10}).Style(c.FillGradient("fade"), c.StrokeGradient("#glow")); r == nil {
+		t.Error("Unexpected nil from c.Rect")
+	} else if str := fmt.Sprint(r); str != `<rect x="0" y="0" width="10" height="10" style="fill: url(#fade); stroke: url(#glow);"></rect>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Gradient_004(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 10}, data.PX)
+	c.LinearGradient("fade", data.Point{0, 0}, data.Point{1, 0}).Stop(0, color.Black, 1).Stop(1, color.White, 1)
+	c.Rect(data.ZeroPoint, data.Size{100, 10}).Style(c.FillGradient("fade"))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := uint32(0)
+	for x := 0; x < 100; x += 10 {
+		r, g, b, a := img.At(x, 5).RGBA()
+		if a != 0xFFFF || r != g || g != b {
+			t.Error("Unexpected color at ", x, ": ", img.At(x, 5))
+		} else if x > 0 && r <= prev {
+			t.Error("Expected increasing brightness at ", x, ": ", img.At(x, 5))
+		}
+		prev = r
+	}
+}
@@ -88,6 +88,134 @@ func (this *Canvas) Marker(pt data.Point, sz data.Size, children ...data.CanvasE
 	return m
 }
 
+func (this *Canvas) Pattern(pt data.Point, sz data.Size, children ...data.CanvasElement) data.CanvasGroup {
+	p, err := this.NewElement("pattern")
+	if err != nil {
+		return nil
+	}
+
+	// Set attributes on element
+	p.SetAttr("x", f32.String(pt.X))
+	p.SetAttr("y", f32.String(pt.Y))
+	p.SetAttr("width", f32.String(f32.Abs(sz.W)))
+	p.SetAttr("height", f32.String(f32.Abs(sz.H)))
+
+	// Append children. If any children are nil, then return nil to bubble up
+	// any errors
+	for _, child := range children {
+		if child == nil {
+			return nil
+		} else if elem, ok := child.(*Element); ok == false {
+			return nil
+		} else if err := p.AddChild(elem.Node); err != nil {
+			return nil
+		}
+	}
+
+	// Move pattern into the definitions
+	if defs := this.defs(); defs == nil {
+		return nil
+	} else if err := defs.AddChild(p.Node); err != nil {
+		return nil
+	}
+
+	// Return pattern
+	return p
+}
+
+func (this *Canvas) ClipPath(children ...data.CanvasElement) data.CanvasGroup {
+	c, err := this.NewElement("clipPath")
+	if err != nil {
+		return nil
+	}
+
+	// Append children. If any children are nil, then return nil to bubble up
+	// any errors
+	for _, child := range children {
+		if child == nil {
+			return nil
+		} else if elem, ok := child.(*Element); ok == false {
+			return nil
+		} else if err := c.AddChild(elem.Node); err != nil {
+			return nil
+		}
+	}
+
+	// Move clipping path into the definitions
+	if defs := this.defs(); defs == nil {
+		return nil
+	} else if err := defs.AddChild(c.Node); err != nil {
+		return nil
+	}
+
+	// Return clipping path
+	return c
+}
+
+func (this *Canvas) Mask(children ...data.CanvasElement) data.CanvasGroup {
+	m, err := this.NewElement("mask")
+	if err != nil {
+		return nil
+	}
+
+	// Append children. If any children are nil, then return nil to bubble up
+	// any errors
+	for _, child := range children {
+		if child == nil {
+			return nil
+		} else if elem, ok := child.(*Element); ok == false {
+			return nil
+		} else if err := m.AddChild(elem.Node); err != nil {
+			return nil
+		}
+	}
+
+	// Move mask into the definitions
+	if defs := this.defs(); defs == nil {
+		return nil
+	} else if err := defs.AddChild(m.Node); err != nil {
+		return nil
+	}
+
+	// Return mask
+	return m
+}
+
+func (this *Canvas) Symbol(pt data.Point, sz data.Size, children ...data.CanvasElement) data.CanvasGroup {
+	s, err := this.NewElement("symbol")
+	if err != nil {
+		return nil
+	}
+
+	// Set attributes on element
+	sz = data.Size{f32.Abs(sz.W), f32.Abs(sz.H)}
+	s.SetAttr("viewBox", f32.Join([]float32{pt.X, pt.Y, sz.W, sz.H}, " "))
+	s.SetAttr("width", f32.String(sz.W))
+	s.SetAttr("height", f32.String(sz.H))
+
+	// Append children. If any children are nil, then return nil to bubble up
+	// any errors
+	for _, child := range children {
+		if child == nil {
+			return nil
+		} else if elem, ok := child.(*Element); ok == false {
+			return nil
+		} else if err := s.AddChild(elem.Node); err != nil {
+			return nil
+		}
+	}
+
+	// Move symbol into the definitions
+	if defs := this.defs(); defs == nil {
+		return nil
+	} else if err := defs.AddChild(s.Node); err != nil {
+		return nil
+	}
+
+	// Return symbol
+	return s
+}
+
 func (this *Element) Desc(cdata string) data.CanvasGroup {
 	cdata = strings.TrimSpace(cdata)
 
@@ -138,6 +266,20 @@ func (this *Element) OrientationAngle(angle float32) data.CanvasGroup {
 	return this
 }
 
+func (this *Element) PatternUnits(units data.Coordinates) data.CanvasGroup {
+	if this.isElement("pattern") == false {
+		return nil
+	}
+	if units == data.ObjectBoundingBox {
+		this.RemoveAttr("patternUnits")
+	} else if err := this.SetAttr("patternUnits", units.String()); err != nil {
+		return nil
+	}
+
+	// Return group
+	return this
+}
+
 func (this *Element) Append(children ...data.CanvasElement) data.CanvasGroup {
 	// Append children. If any children are nil, then return nil to bubble up
 	// any errors
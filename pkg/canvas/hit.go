@@ -0,0 +1,210 @@
+package canvas
+
+import (
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/geom"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// hitwriter is a renderer which determines whether anything drawn
+// covers a point in the user space of the first transform
+type hitwriter struct {
+	canvas  *Canvas
+	pt      data.Point
+	stack   []matrix
+	clipped []bool // True when the point is outside a clipping path
+	hit     bool
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Maximum distance between curves and flattened curves in user
+	// space of the canvas when testing whether a point is covered
+	hitTolerance = 0.05
+)
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ElementsAt returns the shapes, text, images and use elements which
+// are drawn at a point on the canvas, with the topmost element first.
+// An element is returned when its fill or stroke covers the point,
+// according to the fill rule, stroke width and any clipping paths.
+// Elements which are neither filled nor stroked are not returned
+func (this *Canvas) ElementsAt(pt data.Point) []data.CanvasElement {
+	result := []data.CanvasElement{}
+	var walk func(node data.Node, m matrix, parent *renderstyle)
+	walk = func(node data.Node, m matrix, parent *renderstyle) {
+		elem := &Element{node, this}
+		if elem.isElement("svg", "g", "a") {
+			// Descend into groups which are not clipped at the point
+			if attr, exists := node.Attr("transform"); exists {
+				if m_, err := parseTransform(attr.Value); err == nil {
+					m = m.multiply(m_)
+				}
+			}
+			style := parent.inherit(node)
+			if style.displayNone {
+				return
+			} else if shapes, exists := this.clipShapes(elem, parent.rules); exists && clipContains(shapes, m, pt) == false {
+				return
+			}
+			for _, child := range node.Children() {
+				walk(child, m, style)
+			}
+		} else if elem.isElement("use", "rect", "circle", "ellipse", "line", "polyline", "polygon", "path", "text", "image") {
+			r := &hitwriter{canvas: this, pt: pt, stack: []matrix{m}, clipped: []bool{false}}
+			if err := this.renderNode(r, node, parent); err == nil && r.hit {
+				result = append(result, elem)
+			}
+		}
+	}
+	walk(this.Document, identity, this.rootStyle())
+
+	// Reverse the order so that the topmost element is first
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+/////////////////////////////////////////////////////////////////////
+// RENDERER METHODS
+
+func (this *hitwriter) push(m matrix) error {
+	this.stack = append(this.stack, this.ctm().multiply(m))
+	this.clipped = append(this.clipped, this.clipped[len(this.clipped)-1])
+	return nil
+}
+
+func (this *hitwriter) pop() error {
+	if len(this.stack) <= 1 {
+		return data.ErrInternalAppError.WithPrefix("pop")
+	}
+	this.stack = this.stack[:len(this.stack)-1]
+	this.clipped = this.clipped[:len(this.clipped)-1]
+	return nil
+}
+
+func (this *hitwriter) path(path []pathop, style *renderstyle) error {
+	if this.hit || this.clipped[len(this.clipped)-1] {
+		return nil
+	}
+
+	// Flatten curves in user space, within the tolerance on the canvas
+	m := this.ctm()
+	tolerance := float32(hitTolerance)
+	if scale := m.scale(); scale > 0 {
+		tolerance /= scale
+	}
+	paths := flatten(path, tolerance)
+
+	// Test the fill and the stroke outline
+	if style.fill != nil || style.fillRef != "" {
+		polys := make([][]data.Point, len(paths))
+		for i, path := range paths {
+			polys[i] = transformPoints(m, path.pts)
+		}
+		if geom.Contains(this.pt, style.fillRule, polys...) {
+			this.hit = true
+			return nil
+		}
+	}
+	if (style.stroke != nil || style.strokeRef != "") && style.strokeWidth > 0 {
+		stroker := newStroker(style.strokeWidth, style.lineCap, style.lineJoin, style.miterLimit, tolerance)
+		polys := stroker.stroke(dash(paths, style.dashes, style.dashOffset))
+		for i, poly := range polys {
+			polys[i] = transformPoints(m, poly)
+		}
+		if geom.Contains(this.pt, data.NonZero, polys...) {
+			this.hit = true
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+func (this *hitwriter) text(pt data.Point, value string, style *renderstyle) error {
+	if this.hit || this.clipped[len(this.clipped)-1] {
+		return nil
+	} else if style.fill == nil && style.fillRef == "" {
+		return nil
+	}
+	metrics := this.canvas.fontFor(style).Metrics(style.fontSize)
+	origin := data.Point{pt.X, pt.Y - metrics.Ascent}
+	size := data.Size{this.canvas.measure(style, value), metrics.Ascent + metrics.Descent}
+	if geom.Contains(this.pt, data.NonZero, transformPoints(this.ctm(), rectPoints(origin, size))) {
+		this.hit = true
+	}
+	return nil
+}
+
+func (this *hitwriter) image(pt data.Point, size data.Size, href string, aspect aspectratio) error {
+	if this.hit || this.clipped[len(this.clipped)-1] {
+		return nil
+	}
+	if geom.Contains(this.pt, data.NonZero, transformPoints(this.ctm(), rectPoints(pt, size))) {
+		this.hit = true
+	}
+	return nil
+}
+
+func (this *hitwriter) clip(shapes []clipshape) error {
+	if clipContains(shapes, this.ctm(), this.pt) == false {
+		this.clipped[len(this.clipped)-1] = true
+	}
+	return nil
+}
+
+func (this *hitwriter) beginMask() error {
+	// Masks do not change whether an element is hit
+	return nil
+}
+
+func (this *hitwriter) endMask(mask *Element, m matrix) error {
+	// Masks do not change whether an element is hit
+	return nil
+}
+
+func (this *hitwriter) beginLayer(opacity float32, blend data.BlendMode) error {
+	// Opacity and blending do not change whether an element is hit
+	return nil
+}
+
+func (this *hitwriter) endLayer() error {
+	// Opacity and blending do not change whether an element is hit
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// ctm returns the current transform
+func (this *hitwriter) ctm() matrix {
+	return this.stack[len(this.stack)-1]
+}
+
+// clipContains returns true if a point is within the union of clipping
+// shapes transformed by a matrix
+func clipContains(shapes []clipshape, m matrix, pt data.Point) bool {
+	tolerance := float32(hitTolerance)
+	if scale := m.scale(); scale > 0 {
+		tolerance /= scale
+	}
+	for _, shape := range shapes {
+		paths := flatten(shape.path, tolerance)
+		polys := make([][]data.Point, len(paths))
+		for i, path := range paths {
+			polys[i] = transformPoints(m, path.pts)
+		}
+		if geom.Contains(pt, shape.rule, polys...) {
+			return true
+		}
+	}
+	return false
+}
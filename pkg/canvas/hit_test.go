@@ -0,0 +1,114 @@
+package canvas_test
+
+import (
+	"fmt"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_Hit_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Rect(data.Point{10, 10}, data.Size{20, 20}).Id("rect")
+	c.Circle(data.Point{25, 25}, 5).Id("circle")
+	c.Group(c.Rect(data.ZeroPoint, data.Size{10, 10}).Id("moved")).Transform(c.Translate(data.Point{50, 0}), c.Scale(data.Size{2, 2}))
+	tests := []struct {
+		pt  data.Point
+		ids string
+	}{
+		{data.Point{25, 25}, "circle rect"},
+		{data.Point{12, 12}, "rect"},
+		{data.Point{50, 50}, ""},
+		{data.Point{65, 15}, "moved"},
+		{data.Point{75, 15}, ""},
+	}
+	for _, test := range tests {
+		if ids := hitIds(c.ElementsAt(test.pt)); ids != test.ids {
+			t.Errorf("%v: expected %q, got %q", test.pt, test.ids, ids)
+		}
+	}
+}
+
+func Test_Hit_002(t *testing.T) {
+	// Fill rules determine whether holes are hit
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	path, err := canvas.ParsePath("M0 0 H30 V30 H0 Z M10 10 H20 V20 H10 Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Path(path...).Id("nonzero")
+	c.Path(path...).Id("evenodd").Style(c.FillRule(data.EvenOdd))
+	if ids := hitIds(c.ElementsAt(data.Point{15, 15})); ids != "nonzero" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+	if ids := hitIds(c.ElementsAt(data.Point{5, 5})); ids != "evenodd nonzero" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+}
+
+func Test_Hit_003(t *testing.T) {
+	// Strokes are hit within half the stroke width, and unfilled
+	// shapes are not hit inside
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Line(data.Point{0, 50}, data.Point{100, 50}).Id("line").Style(c.Stroke(color.Black, 1), c.StrokeWidth(4))
+	c.Line(data.Point{50, 0}, data.Point{50, 100}).Id("hidden")
+	c.Rect(data.Point{40, 40}, data.Size{20, 20}).Id("outline").Style(c.NoFill(), c.Stroke(color.Black, 1), c.StrokeWidth(2))
+	tests := []struct {
+		pt  data.Point
+		ids string
+	}{
+		{data.Point{20, 51}, "line"},
+		{data.Point{20, 53}, ""},
+		{data.Point{50, 20}, ""},
+		{data.Point{45, 45}, ""},
+		{data.Point{45, 50}, "line"},
+		{data.Point{40.5, 45}, "outline"},
+		{data.Point{40.5, 49}, "outline line"},
+	}
+	for _, test := range tests {
+		if ids := hitIds(c.ElementsAt(test.pt)); ids != test.ids {
+			t.Errorf("%v: expected %q, got %q", test.pt, test.ids, ids)
+		}
+	}
+}
+
+func Test_Hit_004(t *testing.T) {
+	// Clipping paths and use elements
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.ClipPath(c.Rect(data.ZeroPoint, data.Size{10, 10})).Id("clip")
+	c.Group(c.Rect(data.ZeroPoint, data.Size{50, 50}).Id("clipped")).Style(c.Clip("clip"))
+	c.Defs(c.Circle(data.ZeroPoint, 5).Id("dot"))
+	c.Use("dot", data.Point{80, 80}).Id("use")
+	if ids := hitIds(c.ElementsAt(data.Point{5, 5})); ids != "clipped" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+	if ids := hitIds(c.ElementsAt(data.Point{20, 20})); ids != "" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+	if ids := hitIds(c.ElementsAt(data.Point{82, 82})); ids != "use" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+	if ids := hitIds(c.ElementsAt(data.Point{88, 80})); ids != "" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+}
+
+// hitIds returns the identifiers of elements separated by spaces
+func hitIds(elems []data.CanvasElement) string {
+	str := ""
+	for i, elem := range elems {
+		if i > 0 {
+			str += " "
+		}
+		if node, ok := elem.(*canvas.Element); ok {
+			if attr, exists := node.Attr("id"); exists {
+				str += attr.Value
+				continue
+			}
+		}
+		str += fmt.Sprint(elem)
+	}
+	return str
+}
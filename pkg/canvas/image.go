@@ -0,0 +1,276 @@
+package canvas
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// aspectratio determines how an image is fitted within a viewport,
+// from the preserveAspectRatio attribute
+type aspectratio struct {
+	none  bool    // Stretch the image to fill the viewport
+	x, y  float32 // Alignment within the viewport between 0 and 1
+	slice bool    // Fill the viewport rather than fit within it
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Quality of images encoded as JPEG, between 1 and 100
+	jpegQuality = 90
+)
+
+var (
+	// Initial value of preserveAspectRatio, which centres the image
+	// within the viewport
+	defaultAspectRatio = aspectratio{x: 0.5, y: 0.5}
+)
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ImageData returns an image element which embeds an image as a data
+// URI, encoded as PNG or JPEG. When the size is zero, the size of the
+// image in pixels is used
+func (this *Canvas) ImageData(pt data.Point, size data.Size, img image.Image, format data.ImageFormat) data.CanvasImage {
+	if img == nil {
+		return nil
+	} else if size == data.ZeroSize {
+		size = data.Size{float32(img.Bounds().Dx()), float32(img.Bounds().Dy())}
+	}
+
+	// Encode the image
+	buf := new(bytes.Buffer)
+	switch format {
+	case data.ImagePNG:
+		if err := png.Encode(buf, img); err != nil {
+			return nil
+		}
+	case data.ImageJPEG:
+		if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	// Create the element
+	elem, err := this.NewElement("image")
+	if err != nil {
+		return nil
+	}
+	elem.SetAttr("x", f32.String(pt.X))
+	elem.SetAttr("y", f32.String(pt.Y))
+	elem.SetAttr("width", f32.String(size.W))
+	elem.SetAttr("height", f32.String(size.H))
+	elem.SetAttr("href", "data:"+format.String()+";base64,"+base64.StdEncoding.EncodeToString(buf.Bytes()))
+	return elem
+}
+
+// DecodeImage returns the image embedded in an image element as a data
+// URI. Returns ErrBadParameter if the element is not an image element or
+// the image is not embedded
+func (this *Canvas) DecodeImage(elem data.CanvasElement) (image.Image, error) {
+	elem_, ok := elem.(*Element)
+	if ok == false || elem_ == nil || elem_.isElement("image") == false {
+		return nil, data.ErrBadParameter.WithPrefix("DecodeImage")
+	}
+	href, exists := attrHref(elem_.Node)
+	if exists == false {
+		return nil, data.ErrBadParameter.WithPrefix("DecodeImage: Missing href")
+	}
+	return decodeImage(href.Value)
+}
+
+// PreserveAspectRatio sets how an image is fitted within its viewport.
+// The image either fits within the viewport or fills the viewport and
+// is clipped, aligned at the start, middle or end in each direction, or
+// is stretched to fill the viewport, in which case the alignment is
+// ignored
+func (this *Element) PreserveAspectRatio(x, y data.Align, fit data.ImageFit) data.CanvasImage {
+	if this.isElement("image") == false {
+		return nil
+	}
+	var value string
+	switch fit {
+	case data.FitNone:
+		value = "none"
+	case data.FitMeet:
+		value = "x" + aspectAlign(x) + "Y" + aspectAlign(y)
+	case data.FitSlice:
+		value = "x" + aspectAlign(x) + "Y" + aspectAlign(y) + " " + fit.String()
+	default:
+		return nil
+	}
+	if err := this.SetAttr("preserveAspectRatio", value); err != nil {
+		return nil
+	}
+
+	// Return success
+	return this
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// decodeImage returns an image from a data URI with PNG or JPEG data
+func decodeImage(uri string) (image.Image, error) {
+	mediatype, bytes_, err := parseDataURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	var img image.Image
+	switch mediatype {
+	case data.ImagePNG.String():
+		img, err = png.Decode(bytes.NewReader(bytes_))
+	case data.ImageJPEG.String():
+		img, err = jpeg.Decode(bytes.NewReader(bytes_))
+	default:
+		return nil, data.ErrNotImplemented.WithPrefix("Unsupported image type: ", strconv.Quote(mediatype))
+	}
+	if err != nil {
+		return nil, data.ErrBadParameter.WithPrefix("Invalid image data: ", err)
+	}
+	return img, nil
+}
+
+// isDataURI returns true if a URI contains embedded data
+func isDataURI(uri string) bool {
+	return strings.HasPrefix(strings.TrimSpace(uri), "data:")
+}
+
+// parseDataURI returns the media type and data of a data URI in the
+// form "data:[<mediatype>][;base64],<data>", where the media type
+// parameters are ignored
+func parseDataURI(uri string) (string, []byte, error) {
+	uri = strings.TrimSpace(uri)
+	if isDataURI(uri) == false {
+		return "", nil, data.ErrBadParameter.WithPrefix("Not a data URI")
+	}
+	comma := strings.Index(uri, ",")
+	if comma < 0 {
+		return "", nil, data.ErrBadParameter.WithPrefix("Invalid data URI")
+	}
+	params := strings.Split(uri[len("data:"):comma], ";")
+	mediatype := strings.ToLower(strings.TrimSpace(params[0]))
+	if mediatype == "" {
+		mediatype = "text/plain"
+	}
+	if params[len(params)-1] == "base64" {
+		// Whitespace is permitted within the encoded data
+		value := strings.Join(strings.Fields(uri[comma+1:]), "")
+		if bytes, err := base64.StdEncoding.DecodeString(value); err != nil {
+			return "", nil, data.ErrBadParameter.WithPrefix("Invalid data URI: ", err)
+		} else {
+			return mediatype, bytes, nil
+		}
+	} else if value, err := url.PathUnescape(uri[comma+1:]); err != nil {
+		return "", nil, data.ErrBadParameter.WithPrefix("Invalid data URI: ", err)
+	} else {
+		return mediatype, []byte(value), nil
+	}
+}
+
+// parseAspectRatio returns the alignment and scaling of an image from
+// a preserveAspectRatio value, which is "none" or an alignment such as
+// "xMidYMid" followed by an optional "meet" or "slice"
+func parseAspectRatio(value string) (aspectratio, error) {
+	fields := strings.Fields(value)
+	if len(fields) > 0 && fields[0] == "defer" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return defaultAspectRatio, data.ErrBadParameter.WithPrefix("Invalid preserveAspectRatio: ", strconv.Quote(value))
+	}
+	result := aspectratio{}
+	if fields[0] == "none" {
+		result.none = true
+	} else if len(fields[0]) != 8 || fields[0][0] != 'x' || fields[0][4] != 'Y' {
+		return defaultAspectRatio, data.ErrBadParameter.WithPrefix("Invalid preserveAspectRatio: ", strconv.Quote(value))
+	} else {
+		for i, align := range []string{fields[0][1:4], fields[0][5:8]} {
+			var v float32
+			switch align {
+			case "Min":
+				v = 0
+			case "Mid":
+				v = 0.5
+			case "Max":
+				v = 1
+			default:
+				return defaultAspectRatio, data.ErrBadParameter.WithPrefix("Invalid preserveAspectRatio: ", strconv.Quote(value))
+			}
+			if i == 0 {
+				result.x = v
+			} else {
+				result.y = v
+			}
+		}
+	}
+	if len(fields) == 2 {
+		switch fields[1] {
+		case "meet":
+			result.slice = false
+		case "slice":
+			result.slice = true
+		default:
+			return defaultAspectRatio, data.ErrBadParameter.WithPrefix("Invalid preserveAspectRatio: ", strconv.Quote(value))
+		}
+	}
+	return result, nil
+}
+
+// aspectAlign returns the alignment within a preserveAspectRatio value,
+// where the default alignment is the middle
+func aspectAlign(align data.Align) string {
+	switch align {
+	case data.Start:
+		return "Min"
+	case data.End:
+		return "Max"
+	default:
+		return "Mid"
+	}
+}
+
+// matrix returns the transform from the pixels of an image of a size
+// onto a viewport
+func (this aspectratio) matrix(pt data.Point, viewport, size data.Size) matrix {
+	if size.W <= 0 || size.H <= 0 {
+		return identity
+	}
+	sx, sy := viewport.W/size.W, viewport.H/size.H
+	if this.none == false {
+		if this.slice {
+			sx = f32.Max(sx, sy)
+		} else {
+			sx = f32.Min(sx, sy)
+		}
+		sy = sx
+	}
+	tx := pt.X + (viewport.W-size.W*sx)*this.x
+	ty := pt.Y + (viewport.H-size.H*sy)*this.y
+	return matrix{sx, 0, 0, sy, tx, ty}
+}
+
+// visible returns the area of a viewport covered by an image of a size
+func (this aspectratio) visible(pt data.Point, viewport, size data.Size) (data.Point, data.Size) {
+	if this.none || this.slice {
+		return pt, viewport
+	}
+	m := this.matrix(pt, viewport, size)
+	return m.apply(data.ZeroPoint), data.Size{size.W * m[0], size.H * m[3]}
+}
@@ -0,0 +1,169 @@
+package canvas_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+)
+
+func Test_Image_001(t *testing.T) {
+	// Images are embedded as data URIs and decoded
+	src := testImage(4, 2)
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	i := c.ImageData(data.ZeroPoint, data.ZeroSize, src, data.ImagePNG)
+	if i == nil {
+		t.Fatal("Unexpected nil from c.ImageData")
+	} else if str := fmt.Sprint(i); strings.HasPrefix(str, `<image x="0" y="0" width="4" height="2" href="data:image/png;base64,`) == false {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if img, err := c.DecodeImage(i); err != nil {
+		t.Error(err)
+	} else if equalImages(src, img) == false {
+		t.Error("Unexpected decoded image")
+	}
+
+	// Images encoded as JPEG are lossy
+	if i := c.ImageData(data.ZeroPoint, data.Size{8, 8}, src, data.ImageJPEG); i == nil {
+		t.Error("Unexpected nil from c.ImageData")
+	} else if str := fmt.Sprint(i); strings.Contains(str, `href="data:image/jpeg;base64,`) == false {
+		t.Error("Unexpected return, got: ", str)
+	} else if img, err := c.DecodeImage(i); err != nil {
+		t.Error(err)
+	} else if img.Bounds().Dx() != 4 || img.Bounds().Dy() != 2 {
+		t.Error("Unexpected bounds, got: ", img.Bounds())
+	}
+
+	// Images referenced by URL or other elements are not decoded
+	if _, err := c.DecodeImage(c.Image(data.ZeroPoint, data.Size{10, 10}, "logo.png")); err == nil {
+		t.Error("Expected error decoding URL")
+	}
+	if _, err := c.DecodeImage(c.Rect(data.ZeroPoint, data.Size{10, 10})); err == nil {
+		t.Error("Expected error decoding rect")
+	}
+	if c.ImageData(data.ZeroPoint, data.ZeroSize, nil, data.ImagePNG) != nil {
+		t.Error("Expected nil for nil image")
+	}
+}
+
+func Test_Image_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	i := c.ImageData(data.ZeroPoint, data.Size{10, 10}, testImage(2, 2), data.ImagePNG)
+	for _, test := range []struct {
+		x, y  data.Align
+		fit   data.ImageFit
+		value string
+	}{
+		{data.Middle, data.Middle, data.FitMeet, `preserveAspectRatio="xMidYMid"`},
+		{data.Start, data.End, data.FitSlice, `preserveAspectRatio="xMinYMax slice"`},
+		{data.End, data.Start, data.FitNone, `preserveAspectRatio="none"`},
+		{0, 0, data.FitMeet, `preserveAspectRatio="xMidYMid"`},
+	} {
+		if str := fmt.Sprint(i.PreserveAspectRatio(test.x, test.y, test.fit)); strings.Contains(str, test.value) == false {
+			t.Error("Unexpected return, got: ", str)
+		}
+	}
+	if i.PreserveAspectRatio(data.Start, data.Start, data.ImageFit(-1)) != nil {
+		t.Error("Expected nil for invalid fit")
+	}
+}
+
+func Test_Image_003(t *testing.T) {
+	// Embedded images are written and read back
+	src := testImage(3, 3)
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.ImageData(data.Point{10, 10}, data.Size{30, 30}, src, data.ImagePNG).PreserveAspectRatio(data.Start, data.Start, data.FitSlice)
+	for _, format := range []data.Writer{data.SVG, data.Optimise} {
+		b := new(strings.Builder)
+		if err := c.Write(format|data.Minify, b); err != nil {
+			t.Fatal(err)
+		}
+		c2, err := canvas.Read(data.SVG, strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		elems := c2.ElementsAt(data.Point{20, 20})
+		if len(elems) != 1 {
+			t.Fatal("Expected one element, got: ", elems)
+		} else if img, err := c2.DecodeImage(elems[0]); err != nil {
+			t.Error(err)
+		} else if equalImages(src, img) == false {
+			t.Error("Unexpected decoded image")
+		}
+	}
+
+	// Invalid images and aspect ratios are rejected
+	for _, svg := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"><image href="data:image/png;base64,AAAA"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><image href="data:image/jpeg;base64,!"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><image href="logo.png" preserveAspectRatio="xMidYCentre"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><image href="logo.png" preserveAspectRatio="none fill"/></svg>`,
+	} {
+		if _, err := canvas.Read(data.SVG, strings.NewReader(svg)); err == nil {
+			t.Error("Expected error reading ", svg)
+		}
+	}
+}
+
+func Test_Image_004(t *testing.T) {
+	// Embedded images are drawn onto bitmaps, fitted within the
+	// viewport according to the aspect ratio
+	src := testImage(2, 1)
+	c := canvas.NewCanvas(data.Size{60, 20}, data.PX)
+	c.ImageData(data.Point{0, 0}, data.Size{20, 20}, src, data.ImagePNG)
+	c.ImageData(data.Point{20, 0}, data.Size{20, 20}, src, data.ImagePNG).PreserveAspectRatio(data.Start, data.Start, data.FitSlice)
+	c.ImageData(data.Point{40, 0}, data.Size{20, 20}, src, data.ImagePNG).PreserveAspectRatio(data.Middle, data.Middle, data.FitNone)
+
+	img := renderPNG(t, c)
+	for _, test := range []struct {
+		x, y int
+		c    color.Color
+	}{
+		{5, 2, color.RGBA{}},
+		{5, 10, src.At(0, 0)},
+		{15, 10, src.At(1, 0)},
+		{5, 18, color.RGBA{}},
+		{25, 2, src.At(0, 0)},
+		{35, 18, src.At(0, 0)},
+		{45, 2, src.At(0, 0)},
+		{55, 18, src.At(1, 0)},
+	} {
+		if equalColors(img.At(test.x, test.y), test.c) == false {
+			t.Error("Unexpected color at ", test.x, ",", test.y, ": ", img.At(test.x, test.y))
+		}
+	}
+}
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 0x40), uint8(y * 0x40), 0x80, 0xFF})
+		}
+	}
+	return img
+}
+
+func equalImages(a, b image.Image) bool {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return false
+	}
+	for y := 0; y < a.Bounds().Dy(); y++ {
+		for x := 0; x < a.Bounds().Dx(); x++ {
+			if equalColors(a.At(a.Bounds().Min.X+x, a.Bounds().Min.Y+y), b.At(b.Bounds().Min.X+x, b.Bounds().Min.Y+y)) == false {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func equalColors(a, b color.Color) bool {
+	r0, g0, b0, a0 := a.RGBA()
+	r1, g1, b1, a1 := b.RGBA()
+	return r0>>8 == r1>>8 && g0>>8 == g1>>8 && b0>>8 == b1>>8 && a0>>8 == a1>>8
+}
@@ -0,0 +1,132 @@
+package canvas
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// LINK ELEMENTS
+
+// Link returns a hyperlink to a URL which wraps elements, where the
+// target is the name of the browsing context in which to open the link,
+// such as "_blank", or empty for the current context
+func (this *Canvas) Link(href, target string, children ...data.CanvasElement) data.CanvasGroup {
+	if href = strings.TrimSpace(href); href == "" {
+		return nil
+	}
+	a, err := this.NewElement("a")
+	if err != nil {
+		return nil
+	}
+	a.SetAttr("href", href)
+	if target = strings.TrimSpace(target); target != "" {
+		a.SetAttr("target", target)
+	}
+
+	// Append children. If any children are nil, then return nil to bubble up
+	// any errors
+	for _, child := range children {
+		if child == nil {
+			return nil
+		} else if elem, ok := child.(*Element); ok == false {
+			return nil
+		} else if err := a.AddChild(elem.Node); err != nil {
+			return nil
+		}
+	}
+
+	// Return link
+	return a
+}
+
+/////////////////////////////////////////////////////////////////////
+// TITLE AND METADATA
+
+// Title sets the title of an element, which is displayed as a tooltip
+// when the pointer is over the element. An empty title removes it
+func (this *Element) Title(cdata string) data.CanvasElement {
+	cdata = strings.TrimSpace(cdata)
+
+	// Remove existing title
+	if err := this.removeChildren("title"); err != nil {
+		return nil
+	} else if cdata == "" {
+		return this
+	}
+
+	// Create a title as the first child of the element
+	title := this.Document.CreateElementNS("title", data.XmlNamespaceSVG)
+	if err := title.AddChild(this.Document.CreateText(cdata)); err != nil {
+		return nil
+	} else if err := this.InsertChildBefore(title, this.FirstChild()); err != nil {
+		return nil
+	}
+
+	// Return success
+	return this
+}
+
+// Metadata sets the metadata of an element to nodes, which are usually
+// elements in another namespace created with the canvas document. Without
+// any nodes, the metadata is removed
+func (this *Element) Metadata(nodes ...data.Node) data.CanvasElement {
+	// Check nodes belong to the canvas document
+	for _, node := range nodes {
+		if node == nil {
+			return nil
+		} else if node_, ok := node.(interface{ Document() data.Document }); ok && node_.Document() != this.Document {
+			return nil
+		}
+	}
+
+	// Remove existing metadata
+	if err := this.removeChildren("metadata"); err != nil {
+		return nil
+	} else if len(nodes) == 0 {
+		return this
+	}
+
+	// Create metadata after any title and description
+	metadata := this.Document.CreateElementNS("metadata", data.XmlNamespaceSVG)
+	for _, node := range nodes {
+		if err := metadata.AddChild(node); err != nil {
+			return nil
+		}
+	}
+	var ref data.Node
+	for _, child := range elementChildren(this.Node) {
+		if name := child.Name(); name.Space != data.XmlNamespaceSVG || (name.Local != "title" && name.Local != "desc") {
+			ref = child
+			break
+		}
+	}
+	if ref == nil {
+		if err := this.AddChild(metadata); err != nil {
+			return nil
+		}
+	} else if err := this.InsertChildBefore(metadata, ref); err != nil {
+		return nil
+	}
+
+	// Return success
+	return this
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// removeChildren removes the SVG child elements of an element with a
+// tag name
+func (this *Element) removeChildren(tag string) error {
+	for _, child := range elementChildren(this.Node) {
+		if child.Name() == (xml.Name{data.XmlNamespaceSVG, tag}) {
+			if err := this.RemoveChild(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package canvas_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+const (
+	dcNamespace = "http://purl.org/dc/elements/1.1/"
+)
+
+func Test_Link_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	bar := c.Rect(data.Point{10, 50}, data.Size{10, 50}).Title(" Sales: 50 ")
+	if bar == nil {
+		t.Fatal("Unexpected nil from Title")
+	}
+	a := c.Link("/sales?q=1", "_blank", bar)
+	if a == nil {
+		t.Fatal("Unexpected nil from c.Link")
+	} else if str := fmt.Sprint(a); str != `<a href="/sales?q=1" target="_blank"><rect x="10" y="50" width="10" height="50"><title>Sales: 50</title></rect></a>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+
+	// Titles are replaced and removed
+	if str := fmt.Sprint(bar.Title("Sales: 60")); strings.Count(str, "<title>") != 1 || strings.Contains(str, "Sales: 60") == false {
+		t.Error("Unexpected return, got: ", str)
+	} else if str := fmt.Sprint(bar.Title("")); strings.Contains(str, "<title>") {
+		t.Error("Unexpected return, got: ", str)
+	}
+
+	// Links without a URL are not created
+	if c.Link(" ", "", c.Rect(data.ZeroPoint, data.Size{10, 10})) != nil {
+		t.Error("Expected nil for empty href")
+	}
+}
+
+func Test_Link_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	creator := c.DOM().CreateElementNS("creator", dcNamespace)
+	creator.AddChild(c.DOM().CreateText("Reports"))
+	g := c.Group(c.Circle(data.Point{50, 50}, 10)).Title("Tooltip")
+	if g.Metadata(creator) == nil {
+		t.Fatal("Unexpected nil from Metadata")
+	} else if str := fmt.Sprint(g); str != `<g><title>Tooltip</title><metadata><dc:creator>Reports</dc:creator></metadata><circle cx="50" cy="50" r="10"></circle></g>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+
+	// Nodes from another document are rejected
+	other := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	if g.Metadata(other.DOM().CreateElementNS("creator", dcNamespace)) != nil {
+		t.Error("Expected nil for node from another document")
+	}
+
+	// Metadata is removed
+	if str := fmt.Sprint(g.Metadata()); strings.Contains(str, "metadata") {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Link_003(t *testing.T) {
+	// Linked elements are drawn, hit and read back with namespaced
+	// metadata
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Metadata(c.DOM().CreateElementNS("title", dcNamespace))
+	c.Link("https://example.com/", "", c.Rect(data.Point{10, 10}, data.Size{20, 20}).Id("bar").Style(c.Fill(color.Red, 1)))
+	if ids := hitIds(c.ElementsAt(data.Point{20, 20})); ids != "bar" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+	if img := renderPNG(t, c); img != nil {
+		if _, _, _, a := img.At(20, 20).RGBA(); a != 0xFFFF {
+			t.Error("Expected linked element to be drawn")
+		}
+	}
+
+	for _, format := range []data.Writer{data.SVG, data.Optimise} {
+		b := new(strings.Builder)
+		if err := c.Write(format|data.Minify, b); err != nil {
+			t.Fatal(err)
+		}
+		str := b.String()
+		if strings.Contains(str, `xmlns:dc="http://purl.org/dc/elements/1.1/"`) == false || strings.Contains(str, `<metadata><dc:title></dc:title></metadata>`) == false {
+			t.Error("Missing metadata, got: ", str)
+		}
+		if strings.Contains(str, `<a href="https://example.com/">`) == false {
+			t.Error("Missing link, got: ", str)
+		}
+		if c2, err := canvas.Read(data.SVG, strings.NewReader(str)); err != nil {
+			t.Error(err)
+		} else if elems := c2.ElementsAt(data.Point{20, 20}); len(elems) != 1 {
+			t.Error("Unexpected elements, got: ", elems)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package canvas
+
+import (
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+	"github.com/djthorpe/data/pkg/geom"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// matrix is a 2D affine transform in the form [a b c d e f] which maps
+// a point (x,y) onto (ax + cy + e, bx + dy + f)
+type matrix geom.Matrix
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+var (
+	identity = matrix(geom.IdentityMatrix)
+)
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func translateMatrix(x, y float32) matrix {
+	return matrix(geom.TranslateMatrix(data.Point{x, y}))
+}
+
+func scaleMatrix(x, y float32) matrix {
+	return matrix(geom.ScaleMatrix(data.Size{x, y}))
+}
+
+func rotateMatrix(deg float32) matrix {
+	return matrix(geom.RotateMatrix(deg))
+}
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// multiply returns the transform which applies n and then m
+func (m matrix) multiply(n matrix) matrix {
+	return matrix(geom.Matrix(m).Multiply(geom.Matrix(n)))
+}
+
+// apply returns the transformed point
+func (m matrix) apply(pt data.Point) data.Point {
+	return geom.Matrix(m).Apply(pt)
+}
+
+// scale returns the average scaling factor of the transform
+func (m matrix) scale() float32 {
+	return f32.Sqrt(f32.Abs(geom.Matrix(m).Determinant()))
+}
+
+// invert returns the inverse transform, or false if the transform
+// cannot be inverted
+func (m matrix) invert() (matrix, bool) {
+	n, ok := geom.Matrix(m).Invert()
+	return matrix(n), ok
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// parseTransform returns a matrix from a transform attribute value
+// such as "translate(10,10) rotate(45)"
+func parseTransform(value string) (matrix, error) {
+	m, err := geom.ParseTransform(value)
+	return matrix(m), err
+}
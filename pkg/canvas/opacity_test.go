@@ -0,0 +1,155 @@
+package canvas_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_Opacity_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	if g := c.Group().Style(c.Opacity(0.5), c.Blend(data.BlendMultiply), c.Visibility(false), c.Display(true)); g == nil {
+		t.Error("Unexpected nil from c.Group")
+	} else if str := fmt.Sprint(g); str != `<g style="opacity: 0.500000; mix-blend-mode: multiply; visibility: hidden; display: inline;"></g>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if r := c.Rect(data.ZeroPoint, data.Size{10, 10}).Style(c.Opacity(2), c.Visibility(true), c.Display(false)); r == nil {
+		t.Error("Unexpected nil from c.Rect")
+	} else if str := fmt.Sprint(r); str != `<rect x="0" y="0" width="10" height="10" style="opacity: 1; visibility: visible; display: none;"></rect>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Opacity_002(t *testing.T) {
+	// Overlapping shapes are multiplied, and a group is drawn with
+	// opacity as a whole
+	c := canvas.NewCanvas(data.Size{40, 20}, data.PX)
+	c.Rect(data.ZeroPoint, data.Size{40, 20}).Style(c.Fill(color.White, 1))
+	c.Group(
+		c.Rect(data.ZeroPoint, data.Size{15, 20}).Style(c.Fill(color.Yellow, 1)),
+		c.Rect(data.Point{5, 0}, data.Size{15, 20}).Style(c.Fill(color.Cyan, 1), c.Blend(data.BlendMultiply)),
+	)
+	c.Group(
+		c.Rect(data.Point{20, 0}, data.Size{20, 20}).Style(c.Fill(color.Red, 1)),
+		c.Rect(data.Point{20, 0}, data.Size{20, 20}).Style(c.Fill(color.Red, 1)),
+	).Style(c.Opacity(0.5))
+
+	img := renderPNG(t, c)
+	for _, test := range []struct {
+		x, y    int
+		r, g, b uint8
+	}{
+		{2, 10, 0xFF, 0xFF, 0},
+		{10, 10, 0, 0xFF, 0},
+		{17, 10, 0, 0xFF, 0xFF},
+		{30, 10, 0xFF, 0x80, 0x80},
+	} {
+		if r, g, b, _ := img.At(test.x, test.y).RGBA(); uint8(r>>8) != test.r || uint8(g>>8) != test.g || uint8(b>>8) != test.b {
+			t.Error("Unexpected color at ", test.x, ",", test.y, ": ", img.At(test.x, test.y))
+		}
+	}
+}
+
+func Test_Opacity_003(t *testing.T) {
+	// Elements which are hidden or not displayed are not drawn, hit or
+	// included in bounding boxes, but visible children of hidden
+	// groups are drawn
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Rect(data.Point{0, 0}, data.Size{10, 10}).Id("hidden").Style(c.Visibility(false))
+	c.Group(c.Rect(data.Point{20, 0}, data.Size{10, 10}).Id("none")).Style(c.Display(false))
+	g := c.Group(
+		c.Rect(data.Point{40, 0}, data.Size{10, 10}).Id("inherited"),
+		c.Rect(data.Point{60, 0}, data.Size{10, 10}).Id("visible").Style(c.Visibility(true)),
+	).Style(c.Visibility(false))
+	for _, pt := range []data.Point{{5, 5}, {25, 5}, {45, 5}} {
+		if ids := hitIds(c.ElementsAt(pt)); ids != "" {
+			t.Error("Unexpected elements at ", pt, ", got: ", ids)
+		}
+	}
+	if ids := hitIds(c.ElementsAt(data.Point{65, 5})); ids != "visible" {
+		t.Error("Unexpected elements, got: ", ids)
+	}
+	if origin, size := g.Bounds(); origin != (data.Point{60, 0}) || size != (data.Size{10, 10}) {
+		t.Error("Unexpected bounds, got: ", origin, size)
+	}
+
+	img := renderPNG(t, c)
+	for x, a := range map[int]uint32{5: 0, 25: 0, 45: 0, 65: 0xFFFF} {
+		if _, _, _, a_ := img.At(x, 5).RGBA(); a_ != a {
+			t.Error("Unexpected alpha at ", x, ": ", img.At(x, 5))
+		}
+	}
+}
+
+func Test_Opacity_004(t *testing.T) {
+	// Properties are read and checked, and take part in the cascade
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+		<style>.density { mix-blend-mode: multiply }</style>
+		<g opacity="0.5" visibility="hidden"><rect class="density" width="10" height="10" style="visibility: visible"/></g>
+		<rect x="20" width="10" height="10" display="none"/>
+	</svg>`
+	c, err := canvas.Read(data.SVG, strings.NewReader(svg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	elems := c.ElementsAt(data.Point{5, 5})
+	if len(elems) != 1 {
+		t.Fatal("Expected one element, got: ", elems)
+	}
+	style := c.ComputedStyle(elems[0])
+	for name, value := range map[string]string{"opacity": "1", "mix-blend-mode": "multiply", "visibility": "visible", "display": "inline"} {
+		if style[name] != value {
+			t.Errorf("Unexpected %v, got %v", name, style[name])
+		}
+	}
+	if elems := c.ElementsAt(data.Point{25, 5}); len(elems) != 0 {
+		t.Error("Unexpected elements, got: ", elems)
+	}
+
+	for _, svg := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect opacity="half"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect style="mix-blend-mode: mix"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect visibility="invisible"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect style="display: hidden !important"/></svg>`,
+	} {
+		if _, err := canvas.Read(data.SVG, strings.NewReader(svg)); err == nil {
+			t.Error("Expected error reading ", svg)
+		}
+	}
+}
+
+func Test_Opacity_005(t *testing.T) {
+	// Opacity and blend modes of groups are applied to the elements in
+	// PDF documents
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Group(
+		c.Rect(data.ZeroPoint, data.Size{50, 50}).Style(c.Fill(color.Red, 0.5)),
+	).Style(c.Opacity(0.5), c.Blend(data.BlendColorBurn))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PDF|data.Compress, b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); strings.Contains(str, "<< /Type /ExtGState /ca 0.25 /CA 0.5 /BM /ColorBurn >>") == false {
+		t.Error("Missing graphics state, got: ", str)
+	}
+}
+
+func renderPNG(t *testing.T, c data.Canvas) image.Image {
+	t.Helper()
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
@@ -0,0 +1,717 @@
+package canvas
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/dom"
+	"github.com/djthorpe/data/pkg/stylesheet"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// optimiser reduces the size of a copy of a canvas document
+type optimiser struct {
+	data.Document
+	precision int
+	rules     []data.StyleRule // Rules of the style sheets in the document
+	classes   map[string]bool  // Class names used in the style sheets
+	unread    bool             // A style sheet could not be read completely
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Default number of decimal places for co-ordinates
+	defaultPrecision = 2
+
+	// Additional decimal places for scaling and rotation in transforms
+	transformPrecision = 3
+)
+
+var (
+	// Attributes with co-ordinates and lengths which are rounded
+	optimiseAttrs = map[string]bool{
+		"x": true, "y": true, "x1": true, "y1": true, "x2": true, "y2": true,
+		"cx": true, "cy": true, "r": true, "rx": true, "ry": true, "fx": true, "fy": true, "fr": true,
+		"width": true, "height": true, "dx": true, "dy": true, "points": true, "viewBox": true,
+		"refX": true, "refY": true, "markerWidth": true, "markerHeight": true,
+		"startOffset": true, "textLength": true, "font-size": true,
+		"stroke-width": true, "stroke-dasharray": true, "stroke-dashoffset": true,
+	}
+	// Attributes which contain a transform
+	optimiseTransforms = map[string]bool{
+		"transform": true, "gradientTransform": true, "patternTransform": true,
+	}
+)
+
+var (
+	reTransformFunction = regexp.MustCompile(`([a-zA-Z]+)\s*\(([^)]*)\)`)
+	reLength            = regexp.MustCompile(`^([+-]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][+-]?[0-9]+)?)([a-zA-Z%]*)$`)
+	reClassSelector     = regexp.MustCompile(`\.(-?[_a-zA-Z][_a-zA-Z0-9-]*)`)
+)
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Precision sets the number of decimal places for co-ordinates and
+// lengths when the canvas is written with data.Optimise
+func (this *Canvas) Precision(decimals uint) data.Canvas {
+	this.precision = int(decimals)
+	return this
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// optimise returns a copy of the canvas document which renders in the
+// same way but is smaller when written
+func (this *Canvas) optimise() (data.Document, error) {
+	// Copy the document
+	var buf bytes.Buffer
+	if err := this.Document.WriteEx(&buf, 0); err != nil {
+		return nil, err
+	}
+	document, err := dom.Read(&buf)
+	if err != nil {
+		return nil, err
+	}
+	o := &optimiser{Document: document, precision: this.precision}
+	o.readStyleSheets()
+
+	// Remove unused identifiers first, so that groups which only have
+	// an unused identifier can be collapsed
+	o.stripIds()
+	o.collapse(o.Document)
+	if err := o.round(o.Document); err != nil {
+		return nil, err
+	}
+	o.mergeStyles()
+
+	// Return the optimised document
+	return o.Document, nil
+}
+
+/////////////////////////////////////////////////////////////////////
+// OPTIMISER METHODS
+
+// readStyleSheets reads the rules of the style sheets in the document,
+// including rules with a pseudo-class, which determine whether style
+// attributes and groups can be replaced
+func (this *optimiser) readStyleSheets() {
+	this.classes = make(map[string]bool)
+	walkElements(this.Document, func(node data.Node) {
+		if isStyleSheet(node) {
+			css := textContent(node)
+			sheet := stylesheet.NewStyleSheet()
+			if err := sheet.Read(strings.NewReader(css)); err != nil {
+				this.unread = true
+				this.addClasses(css)
+			} else {
+				this.rules = append(this.rules, sheet.Rules("", "", "", "")...)
+			}
+
+			// Rule sets which are not parsed may match any element
+			if sheet, ok := sheet.(*stylesheet.StyleSheet); ok && len(sheet.Unparsed()) > 0 {
+				this.unread = true
+				for _, css := range sheet.Unparsed() {
+					this.addClasses(css)
+				}
+			}
+		}
+	})
+	for _, rule := range this.rules {
+		if class := rule.Class(); class != "" {
+			this.classes[class] = true
+		}
+	}
+}
+
+// addClasses records the class names in the selectors of style sheet
+// text which could not be parsed
+func (this *optimiser) addClasses(css string) {
+	for _, match := range reClassSelector.FindAllStringSubmatch(css, -1) {
+		this.classes[match[1]] = true
+	}
+}
+
+// styled returns true if any style sheet rule may match a node
+func (this *optimiser) styled(node data.Node) bool {
+	return this.unread || len(matchingRules(this.rules, node)) > 0
+}
+
+// stripIds removes identifiers which are not referenced by any attribute
+// or style sheet
+func (this *optimiser) stripIds() {
+	// Collect attribute values and style sheets which may reference ids
+	refs := []string{}
+	walkElements(this.Document, func(node data.Node) {
+		for _, attr := range node.Attrs() {
+			if attr.Name.Local != "id" || attr.Name.Space != "" {
+				refs = append(refs, attr.Value)
+			}
+		}
+		if node.Name().Local == "style" {
+			refs = append(refs, textContent(node))
+		}
+	})
+	walkElements(this.Document, func(node data.Node) {
+		if attr, exists := node.Attr("id"); exists && isReferenced(attr.Value, refs) == false {
+			node.RemoveAttr("id")
+		}
+	})
+}
+
+// collapse removes groups and definitions which are empty, replaces
+// groups without attributes by their children and moves the transform
+// of a group with a single child onto the child, from the leaves up.
+// Groups which match a style sheet rule are kept, as their children
+// may inherit properties from them
+func (this *optimiser) collapse(parent data.Node) {
+	for _, child := range parent.Children() {
+		if isSVGElement(child) {
+			this.collapse(child)
+		}
+	}
+	for _, child := range parent.Children() {
+		if isSVGElement(child) == false {
+			continue
+		}
+		switch child.Name().Local {
+		case "defs":
+			if len(elementChildren(child)) == 0 && len(attrs(child)) == 0 {
+				parent.RemoveChild(child)
+			}
+		case "g":
+			children := elementChildren(child)
+			switch {
+			case len(attrs(child)) == 0 && len(children) == 0:
+				parent.RemoveChild(child)
+			case this.styled(child):
+				// Keep the group, as its children inherit properties from it
+			case len(attrs(child)) == 0 && len(elementChildren(child)) == len(child.Children()) && hasAnimation(child) == false:
+				for _, node := range children {
+					parent.InsertChildBefore(node, child)
+				}
+				parent.RemoveChild(child)
+			case len(children) == 1 && len(child.Children()) == 1 && onlyTransform(child) && canTransform(children[0]):
+				transform, _ := child.Attr("transform")
+				if attr, exists := children[0].Attr("transform"); exists {
+					children[0].SetAttr("transform", strings.TrimSpace(transform.Value)+" "+strings.TrimSpace(attr.Value))
+				} else {
+					children[0].SetAttr("transform", transform.Value)
+				}
+				parent.InsertChildBefore(children[0], child)
+				parent.RemoveChild(child)
+			}
+		}
+	}
+}
+
+// round rounds co-ordinates and lengths, shortens path data and
+// transforms and removes whitespace from style attributes
+func (this *optimiser) round(parent data.Node) error {
+	for _, child := range parent.Children() {
+		if isSVGElement(child) {
+			if err := this.round(child); err != nil {
+				return err
+			}
+		}
+	}
+	if isSVGElement(parent) == false {
+		return nil
+	}
+	for _, attr := range attrs(parent) {
+		name, value := attr.Name.Local, attr.Value
+		if attr.Name.Space != "" {
+			continue
+		}
+		switch {
+		case name == "d" && parent.Name().Local == "path":
+			if d, err := this.pathData(value); err != nil {
+				return err
+			} else {
+				value = d
+			}
+		case optimiseTransforms[name]:
+			value = this.transform(value)
+		case name == "style":
+			decls := parseStyleAttr(value)
+			for i, decl := range decls {
+				decls[i] = [2]string{decl[0], this.lengths(decl[0], decl[1])}
+			}
+			value = styleString(decls)
+		default:
+			value = this.lengths(name, value)
+		}
+		if value != attr.Value {
+			parent.SetAttr(name, value)
+		}
+	}
+	return nil
+}
+
+// mergeStyles replaces style attributes which are repeated by a class,
+// where this makes the document smaller. The classes are defined in a
+// style sheet at the end of the document. A class does not take
+// precedence over rules with an id or marked "!important" as a style
+// attribute does, so the style attributes of elements which match any
+// existing style sheet rule are kept
+func (this *optimiser) mergeStyles() {
+	// Count the elements with each style, and record existing classes
+	// so that new class names do not match existing rules
+	count := make(map[string][]data.Node)
+	order := []string{}
+	classes := make(map[string]bool)
+	for class := range this.classes {
+		classes[class] = true
+	}
+	walkElements(this.Document, func(node data.Node) {
+		if attr, exists := node.Attr("style"); exists && attr.Value != "" && this.styled(node) == false {
+			if _, exists := count[attr.Value]; exists == false {
+				order = append(order, attr.Value)
+			}
+			count[attr.Value] = append(count[attr.Value], node)
+		}
+		if attr, exists := node.Attr("class"); exists {
+			for _, class := range strings.Fields(attr.Value) {
+				classes[class] = true
+			}
+		}
+	})
+
+	// Create classes for styles, where the class attributes and rule
+	// are shorter than the style attributes
+	rules := []string{}
+	n := 0
+	for _, style := range order {
+		nodes := count[style]
+		if len(nodes) < 2 {
+			continue
+		}
+		name := className(n)
+		for classes[name] {
+			n++
+			name = className(n)
+		}
+		if len(nodes)*len(style) <= len(nodes)*len(name)+len(name)+len(style)+3 {
+			continue
+		}
+		n++
+		rules = append(rules, "."+name+"{"+style+"}")
+		for _, node := range nodes {
+			node.RemoveAttr("style")
+			if attr, exists := node.Attr("class"); exists && strings.TrimSpace(attr.Value) != "" {
+				node.SetAttr("class", strings.TrimSpace(attr.Value)+" "+name)
+			} else {
+				node.SetAttr("class", name)
+			}
+		}
+	}
+
+	// Append the style sheet
+	if len(rules) > 0 {
+		style := this.Document.CreateElementNS("style", data.XmlNamespaceSVG)
+		style.AddChild(this.Document.CreateText(strings.Join(rules, "")))
+		this.Document.AddChild(style)
+	}
+}
+
+// pathData returns path data with co-ordinates rounded, where each
+// segment uses absolute or relative co-ordinates, whichever is shorter.
+// Lines are written as horizontal and vertical lines where possible and
+// repeated commands are omitted
+func (this *optimiser) pathData(value string) (string, error) {
+	s := newScanner(value)
+	var b strings.Builder
+	var cmd, implicit byte
+	var start, pt [2]float64
+	last := ""
+	for s.eof() == false {
+		if c := s.command(); c != 0 {
+			cmd = c
+		} else if cmd == 0 {
+			return "", s.errorf("Expected command")
+		} else if cmd == 'Z' || cmd == 'z' {
+			return "", s.errorf("Unexpected number")
+		}
+		upper := cmd &^ 0x20
+		relative := cmd != upper
+
+		// Read arguments, where coords marks the x and y co-ordinates
+		var coords []byte
+		switch upper {
+		case 'Z':
+		case 'H':
+			coords = []byte("x")
+		case 'V':
+			coords = []byte("y")
+		case 'M', 'L', 'T':
+			coords = []byte("xy")
+		case 'S', 'Q':
+			coords = []byte("xyxy")
+		case 'C':
+			coords = []byte("xyxyxy")
+		case 'A':
+			coords = []byte("lla00xy")
+		default:
+			return "", s.errorf("Unsupported path command")
+		}
+		args := make([]float64, len(coords))
+		for i := range args {
+			if coords[i] == '0' {
+				if flag, err := s.flag(); err != nil {
+					return "", err
+				} else if flag {
+					args[i] = 1
+				}
+			} else if v, err := s.number(); err != nil {
+				return "", err
+			} else {
+				args[i] = float64(v)
+			}
+		}
+
+		// Determine absolute co-ordinates rounded to the precision, and
+		// the equivalent relative co-ordinates
+		abs := make([]float64, len(args))
+		rel := make([]float64, len(args))
+		for i, arg := range args {
+			switch coords[i] {
+			case 'x', 'y':
+				axis := 0
+				if coords[i] == 'y' {
+					axis = 1
+				}
+				if relative {
+					arg += pt[axis]
+				}
+				abs[i] = this.roundTo(arg, this.precision)
+				rel[i] = this.roundTo(abs[i]-pt[axis], this.precision)
+			case 'l':
+				abs[i] = this.roundTo(arg, this.precision)
+				rel[i] = abs[i]
+			case 'a':
+				abs[i] = this.roundTo(arg, this.precision+1)
+				rel[i] = abs[i]
+			default:
+				abs[i], rel[i] = arg, arg
+			}
+		}
+
+		// Lines which are horizontal or vertical are shortened
+		op := upper
+		if upper == 'L' {
+			if abs[1] == pt[1] {
+				op, coords, abs, rel = 'H', coords[:1], abs[:1], rel[:1]
+			} else if abs[0] == pt[0] {
+				op, coords, abs, rel = 'V', coords[1:], abs[1:], rel[1:]
+			}
+		}
+
+		// Write the shorter of the absolute or relative segment
+		a, r := this.strings(abs), this.strings(rel)
+		if len(joinNumbers(r)) < len(joinNumbers(a)) || (len(joinNumbers(r)) == len(joinNumbers(a)) && op != 'M') {
+			op |= 0x20
+			a = r
+		}
+		if op != implicit || op == 'Z' || op == 'z' {
+			b.WriteByte(op)
+		} else if needsSeparator(last, a[0]) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(joinNumbers(a))
+		if len(a) > 0 {
+			last = a[len(a)-1]
+		}
+
+		// Update the current point and the command which is implied
+		// when the next command is omitted
+		switch op &^ 0x20 {
+		case 'Z':
+			pt, implicit = start, 0
+		case 'H':
+			pt[0], implicit = abs[0], op
+		case 'V':
+			pt[1], implicit = abs[0], op
+		case 'M':
+			pt = [2]float64{abs[0], abs[1]}
+			start, implicit = pt, 'L'|(op&0x20)
+			cmd = 'L' | (cmd & 0x20)
+		default:
+			pt, implicit = [2]float64{abs[len(abs)-2], abs[len(abs)-1]}, op
+		}
+	}
+	return b.String(), nil
+}
+
+// transform returns a transform with arguments rounded, where scaling,
+// rotation and skew have additional precision
+func (this *optimiser) transform(value string) string {
+	result := []string{}
+	for _, match := range reTransformFunction.FindAllStringSubmatch(value, -1) {
+		args, err := parseNumbers(match[2])
+		if err != nil {
+			return value
+		}
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			precision := this.precision
+			switch strings.ToLower(match[1]) {
+			case "matrix":
+				if i < 4 {
+					precision += transformPrecision
+				}
+			case "scale":
+				precision += transformPrecision
+			case "rotate", "skewx", "skewy":
+				if i == 0 {
+					precision += transformPrecision
+				}
+			}
+			values[i] = this.roundTo(float64(arg), precision)
+		}
+		result = append(result, match[1]+"("+this.numbers(values)+")")
+	}
+	if len(result) == 0 {
+		return value
+	}
+	return strings.Join(result, " ")
+}
+
+// lengths returns a value with numbers rounded when the attribute or
+// property contains co-ordinates or lengths, which may have units
+func (this *optimiser) lengths(name, value string) string {
+	if optimiseAttrs[name] == false {
+		return value
+	}
+	fields := strings.FieldsFunc(value, isListSeparator)
+	result := make([]string, len(fields))
+	for i, field := range fields {
+		match := reLength.FindStringSubmatch(field)
+		if match == nil {
+			return value
+		}
+		v, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return value
+		}
+		result[i] = this.number(this.roundTo(v, this.precision)) + match[2]
+	}
+	if name == "points" {
+		return joinNumbers(result)
+	}
+	return strings.Join(result, " ")
+}
+
+// roundTo returns a value rounded to a number of decimal places
+func (this *optimiser) roundTo(v float64, precision int) float64 {
+	scale := math.Pow(10, float64(precision))
+	if v = math.Round(v*scale) / scale; v == 0 {
+		// Avoid negative zero
+		return 0
+	}
+	return v
+}
+
+// number returns the shortest representation of a rounded number
+func (this *optimiser) number(v float64) string {
+	str := strconv.FormatFloat(v, 'f', -1, 64)
+	if strings.HasPrefix(str, "0.") {
+		return str[1:]
+	} else if strings.HasPrefix(str, "-0.") {
+		return "-" + str[2:]
+	}
+	return str
+}
+
+// numbers returns rounded numbers with the fewest separators
+func (this *optimiser) numbers(values []float64) string {
+	return joinNumbers(this.strings(values))
+}
+
+// strings returns the shortest representation of rounded numbers
+func (this *optimiser) strings(values []float64) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = this.number(v)
+	}
+	return result
+}
+
+/////////////////////////////////////////////////////////////////////
+// HELPER FUNCTIONS
+
+// joinNumbers joins numbers, with a separator only where the following
+// number could not otherwise be read separately
+func joinNumbers(values []string) string {
+	var b strings.Builder
+	for i, value := range values {
+		if i > 0 && needsSeparator(values[i-1], value) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(value)
+	}
+	return b.String()
+}
+
+// needsSeparator returns true if a number which follows another number
+// needs to be separated from it, which is not the case when the number
+// starts with a sign, or a decimal point following a decimal fraction
+func needsSeparator(prev, next string) bool {
+	switch {
+	case strings.HasPrefix(next, "-"):
+		return false
+	case strings.HasPrefix(next, "."):
+		return strings.Contains(prev, ".") == false
+	default:
+		return true
+	}
+}
+
+// className returns a short class name for an index
+func className(n int) string {
+	name := ""
+	for n++; n > 0; n = (n - 1) / 26 {
+		name = string(rune('a'+(n-1)%26)) + name
+	}
+	return name
+}
+
+// styleString returns style declarations without whitespace
+func styleString(decls [][2]string) string {
+	result := make([]string, len(decls))
+	for i, decl := range decls {
+		result[i] = decl[0] + ":" + decl[1]
+	}
+	return strings.Join(result, ";")
+}
+
+// isReferenced returns true if an identifier is referenced as "#id" or
+// as the start of an event such as "id.click" in one of the values
+func isReferenced(id string, values []string) bool {
+	for _, value := range values {
+		for _, prefix := range []string{"#" + id, id + "."} {
+			for i := strings.Index(value, prefix); i >= 0; {
+				end := i + len(prefix)
+				before := i == 0 || isNameChar(value[i-1]) == false
+				after := end == len(value) || isNameChar(value[end]) == false
+				if prefix[0] == '#' && after || prefix[0] != '#' && before {
+					return true
+				}
+				if j := strings.Index(value[end:], prefix); j >= 0 {
+					i = end + j
+				} else {
+					break
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isNameChar returns true if a byte may be part of an identifier
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == ':' || c >= 0x80
+}
+
+// walkElements calls a function for every element in a document
+func walkElements(node data.Node, fn func(data.Node)) {
+	fn(node)
+	for _, child := range node.Children() {
+		if isElement(child) {
+			walkElements(child, fn)
+		}
+	}
+}
+
+// isElement returns true if a node is an element rather than text
+// or a comment
+func isElement(node data.Node) bool {
+	return node.Name().Local != ""
+}
+
+// isSVGElement returns true if a node is an element in the SVG namespace
+func isSVGElement(node data.Node) bool {
+	return node.Name().Space == data.XmlNamespaceSVG && node.Name().Local != ""
+}
+
+// elementChildren returns the child elements of a node
+func elementChildren(node data.Node) []data.Node {
+	result := []data.Node{}
+	for _, child := range node.Children() {
+		if isElement(child) {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// attrs returns the attributes of a node, excluding namespace declarations
+func attrs(node data.Node) []xml.Attr {
+	result := []xml.Attr{}
+	for _, attr := range node.Attrs() {
+		if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+			result = append(result, attr)
+		}
+	}
+	return result
+}
+
+// textContent returns the text within a node
+func textContent(node data.Node) string {
+	var b strings.Builder
+	for _, child := range node.Children() {
+		if isElement(child) {
+			b.WriteString(textContent(child))
+		} else {
+			b.WriteString(child.Cdata())
+		}
+	}
+	return b.String()
+}
+
+// hasAnimation returns true if any children of a node are animations,
+// which apply to the node
+func hasAnimation(node data.Node) bool {
+	for _, child := range elementChildren(node) {
+		if (&Element{child, nil}).isAnimation() {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyTransform returns true if the only attribute of a node is a
+// transform
+func onlyTransform(node data.Node) bool {
+	attrs := attrs(node)
+	return len(attrs) == 1 && attrs[0].Name.Local == "transform" && attrs[0].Name.Space == ""
+}
+
+// canTransform returns true if the transform of a group can be moved
+// onto a child, which is not possible when the child is clipped, masked
+// or filtered in its own user space, or is animated
+func canTransform(node data.Node) bool {
+	if isSVGElement(node) == false || hasAnimation(node) {
+		return false
+	}
+	switch node.Name().Local {
+	case "g", "use", "rect", "circle", "ellipse", "line", "polyline", "polygon", "path", "text", "image":
+		for _, name := range []string{"clip-path", "mask", "filter"} {
+			if _, exists := attrOrStyle(node, name); exists {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
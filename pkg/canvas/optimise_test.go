@@ -0,0 +1,251 @@
+package canvas_test
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_Optimise_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Rect(data.Point{10.12345, 20.6789}, data.Size{30.333333, 40}).Transform(c.Rotate(33.333333))
+	c.Path(
+		c.MoveTo(data.Point{10.001, 10}),
+		c.LineTo(data.Point{20, 10}),
+		c.LineTo(data.Point{20, 30.5}),
+		c.LineTo(data.Point{25.25, 35.75}),
+		c.CubicTo(data.Point{100, 100}, data.Point{30, 40}, data.Point{50, 60}),
+		c.ArcTo(data.Point{90, 90}, data.Size{10, 10}, 0, false, true),
+		c.ClosePath(),
+	)
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify|data.Optimise, b); err != nil {
+		t.Fatal(err)
+	}
+	if str := b.String(); strings.Contains(str, `<rect x="10.12" y="20.68" width="30.33" height="40" transform="rotate(33.33333)">`) == false {
+		t.Error("Unexpected output, got: ", str)
+	} else if strings.Contains(str, `<path d="M10 10h10v20.5l5.25 5.25C30 40 50 60 100 100a10 10 0 0 1-10-10z">`) == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+
+	// Precision can be changed
+	b.Reset()
+	if err := c.Precision(0).Write(data.SVG|data.Minify|data.Optimise, b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); strings.Contains(str, `<rect x="10" y="21" width="30" height="40" transform="rotate(33.333)">`) == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+
+	// The canvas itself is not changed
+	if str := fmt.Sprint(c.DOM().FirstChild()); strings.Contains(str, `x="10.123450"`) == false {
+		t.Error("Unexpected canvas, got: ", str)
+	}
+}
+
+func Test_Optimise_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Defs()
+	c.Group()
+	c.Group(c.Group(c.Circle(data.Point{50, 50}, 10)).Transform(c.Translate(data.Point{10, 10})))
+	c.Group(c.Circle(data.Point{50, 50}, 10).Style(c.Clip("clip"))).Transform(c.Scale(data.Size{2, 2}))
+	c.Circle(data.Point{50, 50}, 10).Id("unused")
+	c.Defs(c.Circle(data.Point{50, 50}, 10).Id("used"))
+	c.Use("used", data.ZeroPoint)
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify|data.Optimise, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Contains(str, `<defs></defs>`) || strings.Contains(str, `<g></g>`) {
+		t.Error("Expected empty groups to be removed, got: ", str)
+	}
+	if strings.Contains(str, `<circle cx="50" cy="50" r="10" transform="translate(10 10)"></circle>`) == false {
+		t.Error("Expected groups to be collapsed, got: ", str)
+	}
+	if strings.Contains(str, `<g transform="scale(2)"><circle`) == false {
+		t.Error("Expected clipped element to remain in group, got: ", str)
+	}
+	if strings.Contains(str, `"unused"`) || strings.Contains(str, `id="used"`) == false {
+		t.Error("Unexpected identifiers, got: ", str)
+	}
+}
+
+func Test_Optimise_003(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	style := []data.CanvasStyle{c.Fill(color.Red, 1), c.StrokeWidth(1.33333)}
+	c.Circle(data.Point{10, 10}, 5).Style(style...)
+	c.Circle(data.Point{20, 10}, 5).Style(style...).Class("dot")
+	c.Circle(data.Point{30, 10}, 5).Style(style...)
+	c.Circle(data.Point{40, 10}, 5).Style(c.Fill(color.Blue, 1))
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify|data.Optimise, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Count(str, `class="a"`) != 2 || strings.Contains(str, `class="dot a"`) == false {
+		t.Error("Expected class for repeated style, got: ", str)
+	} else if strings.Contains(str, `<style>.a{fill:red;fill-opacity:1;stroke-width:1.33}</style></svg>`) == false {
+		t.Error("Expected style sheet, got: ", str)
+	} else if strings.Contains(str, `style="fill:blue;fill-opacity:1"`) == false {
+		t.Error("Expected style attribute, got: ", str)
+	}
+
+	// Optimised output can be read
+	if _, err := canvas.Read(data.SVG, strings.NewReader(str)); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Optimise_004(t *testing.T) {
+	// Read a complex document, and compare the path geometry before and
+	// after optimisation
+	r, err := os.Open(SVGFILE_F)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	c1, err := canvas.Read(data.SVG, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b1, b2 := new(strings.Builder), new(strings.Builder)
+	if err := c1.Write(data.SVG|data.Minify, b1); err != nil {
+		t.Fatal(err)
+	} else if err := c1.Write(data.SVG|data.Minify|data.Optimise, b2); err != nil {
+		t.Fatal(err)
+	} else if b2.Len() >= b1.Len() {
+		t.Error("Expected smaller output: ", b2.Len(), " >= ", b1.Len())
+	}
+	c2, err := canvas.Read(data.SVG, strings.NewReader(b2.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, p2 := pathValues(t, c1.DOM()), pathValues(t, c2.DOM())
+	if len(p1) != len(p2) || len(p1) == 0 {
+		t.Fatal("Unexpected number of values: ", len(p1), " and ", len(p2))
+	}
+	for i := range p1 {
+		v1, err1 := strconv.ParseFloat(strings.Trim(p1[i], "[]"), 32)
+		v2, err2 := strconv.ParseFloat(strings.Trim(p2[i], "[]"), 32)
+		if err1 != nil || err2 != nil {
+			if p1[i] != p2[i] {
+				t.Fatal("Unexpected command: ", p1[i], " and ", p2[i])
+			}
+		} else if math.Abs(v1-v2) > 0.01 {
+			t.Fatal("Unexpected value: ", p1[i], " and ", p2[i])
+		}
+	}
+}
+
+// pathValues returns the absolute commands and co-ordinates in all path
+// elements of a document
+func pathValues(t *testing.T, node data.Node) []string {
+	result := []string{}
+	if attr, exists := node.Attr("d"); exists && node.Name().Local == "path" {
+		segments, err := canvas.ParsePath(attr.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, strings.Fields(fmt.Sprint(segments))...)
+	}
+	for _, child := range node.Children() {
+		result = append(result, pathValues(t, child)...)
+	}
+	return result
+}
+
+func Test_Optimise_005(t *testing.T) {
+	// Style attributes of elements which match style sheet rules are
+	// kept, and groups which match style sheet rules are not removed
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+		<style>#hot { fill: red } g { stroke: blue }</style>
+		<rect id="hot" width="10" height="10" style="fill:blue;stroke-width:2"/>
+		<rect x="20" width="10" height="10" style="fill:blue;stroke-width:2"/>
+		<rect x="40" width="10" height="10" style="fill:blue;stroke-width:2"/>
+		<g><rect x="60" width="10" height="10" style="fill:blue;stroke-width:2"/></g>
+	</svg>`
+	c1, err := canvas.Read(data.SVG, strings.NewReader(svg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := new(strings.Builder)
+	if err := c1.Write(data.SVG|data.Minify|data.Optimise, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Contains(str, `<rect id="hot" width="10" height="10" style="fill:blue;stroke-width:2">`) == false {
+		t.Error("Expected style attribute, got: ", str)
+	} else if strings.Count(str, `class="a"`) != 3 {
+		t.Error("Expected class for repeated style, got: ", str)
+	} else if strings.Contains(str, `<g><rect`) == false {
+		t.Error("Expected group to remain, got: ", str)
+	}
+
+	// The computed style of each element is unchanged
+	c2, err := canvas.Read(data.SVG, strings.NewReader(str))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pt := range []data.Point{{5, 5}, {25, 5}, {65, 5}} {
+		e1, e2 := c1.ElementsAt(pt), c2.ElementsAt(pt)
+		if len(e1) != 1 || len(e2) != 1 {
+			t.Fatal("Expected one element at ", pt)
+		}
+		s1, s2 := c1.ComputedStyle(e1[0]), c2.ComputedStyle(e2[0])
+		for _, name := range []string{"fill", "stroke", "stroke-width"} {
+			if s1[name] != s2[name] {
+				t.Errorf("Unexpected %v at %v, got %v and %v", name, pt, s1[name], s2[name])
+			}
+		}
+	}
+}
+
+func Test_Optimise_006(t *testing.T) {
+	// Classes for repeated styles do not use the names of classes in
+	// style sheet rules
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+		<style>.a { stroke: green }</style>
+		<rect width="10" height="10" style="fill:blue;stroke-width:2"/>
+		<rect x="20" width="10" height="10" style="fill:blue;stroke-width:2"/>
+		<rect x="40" width="10" height="10" style="fill:blue;stroke-width:2"/>
+	</svg>`
+	c1, err := canvas.Read(data.SVG, strings.NewReader(svg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := new(strings.Builder)
+	if err := c1.Write(data.SVG|data.Minify|data.Optimise, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Contains(str, `class="a"`) {
+		t.Error("Unexpected class from style sheet rule, got: ", str)
+	} else if strings.Count(str, `class="b"`) != 3 {
+		t.Error("Expected class for repeated style, got: ", str)
+	}
+
+	// The computed style of each element is unchanged
+	c2, err := canvas.Read(data.SVG, strings.NewReader(str))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pt := range []data.Point{{5, 5}, {25, 5}, {45, 5}} {
+		e1, e2 := c1.ElementsAt(pt), c2.ElementsAt(pt)
+		if len(e1) != 1 || len(e2) != 1 {
+			t.Fatal("Expected one element at ", pt)
+		}
+		s1, s2 := c1.ComputedStyle(e1[0]), c2.ComputedStyle(e2[0])
+		for _, name := range []string{"fill", "stroke", "stroke-width"} {
+			if s1[name] != s2[name] {
+				t.Errorf("Unexpected %v at %v, got %v and %v", name, pt, s1[name], s2[name])
+			}
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// gradient is a linear or radial gradient resolved from a gradient
+// element and any gradients it references
+type gradient struct {
+	radial         bool
+	x1, y1, x2, y2 float32 // Linear gradient vector
+	cx, cy, r      float32 // Radial gradient end circle
+	fx, fy         float32 // Radial gradient focal point
+	spread         data.Spread
+	units          data.Coordinates
+	transform      matrix
+	stops          []gradientstop
+}
+
+type gradientstop struct {
+	offset  float32
+	color   data.Color
+	opacity float32
+}
+
+// gradientpaint is an image which is painted with a gradient, for
+// compositing through a coverage mask
+type gradientpaint struct {
+	*gradient
+	inverse matrix // Transform from pixels to gradient space
+	table   []color.RGBA64
+}
+
+// patternpaint is an image which is painted with a pattern tile
+// repeated in both directions
+type patternpaint struct {
+	tile    *image.RGBA
+	inverse matrix // Transform from pixels to tile pixels
+	opacity float32
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Maximum depth of references between gradients
+	gradientMaxDepth = 16
+
+	// Number of colors computed for each gradient
+	gradientTableSize = 256
+
+	// Maximum depth of patterns painted within pattern tiles
+	patternMaxDepth = 4
+
+	// Maximum width and height of a pattern tile in pixels
+	patternMaxTile = 2048
+)
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// gradient returns a gradient for an identifier, or nil if there is no
+// gradient with the identifier. Percentages in user space are relative
+// to the viewBox of the canvas
+func (this *Canvas) gradient(id string) *gradient {
+	// Follow references between gradients
+	chain := []data.Node{}
+	for node := this.Document.GetElementById(id); node != nil && len(chain) < gradientMaxDepth; {
+		if elem := (&Element{node, this}); elem.isElement("linearGradient", "radialGradient") == false {
+			break
+		}
+		chain = append(chain, node)
+		if attr, exists := attrHref(node); exists && strings.HasPrefix(attr.Value, "#") {
+			node = this.Document.GetElementById(strings.TrimPrefix(attr.Value, "#"))
+		} else {
+			break
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	// Set attributes from the first gradient in the chain which has them
+	g := &gradient{radial: chain[0].Name().Local == "radialGradient", transform: identity}
+	attr := func(name string) (string, bool) {
+		for _, node := range chain {
+			if attr, exists := node.Attr(name); exists {
+				return strings.TrimSpace(attr.Value), true
+			}
+		}
+		return "", false
+	}
+	if value, exists := attr("gradientUnits"); exists && value == data.UserSpaceOnUse.String() {
+		g.units = data.UserSpaceOnUse
+	}
+	if value, exists := attr("spreadMethod"); exists {
+		for _, spread := range []data.Spread{data.SpreadPad, data.SpreadReflect, data.SpreadRepeat} {
+			if value == spread.String() {
+				g.spread = spread
+			}
+		}
+	}
+	if value, exists := attr("gradientTransform"); exists {
+		if m, err := parseTransform(value); err == nil {
+			g.transform = m
+		}
+	}
+	length := func(name string, def string, ref float32) float32 {
+		value, exists := attr(name)
+		if exists == false {
+			value = def
+		}
+		if strings.HasSuffix(value, "%") {
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 32); err == nil {
+				if g.units == data.ObjectBoundingBox {
+					return float32(v) / 100
+				} else {
+					return float32(v) * ref / 100
+				}
+			}
+		} else if v, err := parseUnitValue(value); err == nil {
+			return v
+		}
+		return 0
+	}
+	w, h := f32.Abs(this.size.W), f32.Abs(this.size.H)
+	d := f32.Sqrt((w*w + h*h) / 2)
+	if g.radial {
+		g.cx, g.cy, g.r = length("cx", "50%", w), length("cy", "50%", h), length("r", "50%", d)
+		g.fx, g.fy = g.cx, g.cy
+		if _, exists := attr("fx"); exists {
+			g.fx = length("fx", "50%", w)
+		}
+		if _, exists := attr("fy"); exists {
+			g.fy = length("fy", "50%", h)
+		}
+	} else {
+		g.x1, g.y1 = length("x1", "0%", w), length("y1", "0%", h)
+		g.x2, g.y2 = length("x2", "100%", w), length("y2", "0%", h)
+	}
+
+	// Set stops from the first gradient in the chain which has them
+	rules := this.styleRules()
+	for _, node := range chain {
+		for _, child := range node.Children() {
+			if stop := (&Element{child, this}); stop.isElement("stop") {
+				g.stops = append(g.stops, stop.gradientStop(g.stops, rules))
+			}
+		}
+		if len(g.stops) > 0 {
+			break
+		}
+	}
+
+	// Return the gradient
+	return g
+}
+
+// gradientStop returns the offset, color and opacity of a stop element,
+// where offsets are no less than the offsets of previous stops
+func (this *Element) gradientStop(prev []gradientstop, rules []data.StyleRule) gradientstop {
+	stop := gradientstop{opacity: 1}
+	if attr, exists := this.Attr("offset"); exists {
+		if v, err := parseOpacity(strings.TrimSpace(attr.Value)); err == nil {
+			stop.offset = v
+		}
+	}
+	if len(prev) > 0 {
+		stop.offset = f32.Max(stop.offset, prev[len(prev)-1].offset)
+	}
+	for _, prop := range cascade(rules, this.Node) {
+		switch prop[0] {
+		case "stop-color":
+			if c, _, ok := parsePaint(strings.TrimSpace(prop[1])); ok && c != nil {
+				stop.color = *c
+			}
+		case "stop-opacity":
+			if v, err := parseOpacity(strings.TrimSpace(prop[1])); err == nil {
+				stop.opacity = v
+			}
+		}
+	}
+	return stop
+}
+
+// paint returns an image painted with the gradient, for an element with
+// a bounding box in user space and a transform from user space onto
+// pixels. Returns nil if there is nothing to paint
+func (this *gradient) paint(ctm matrix, bounds data.Size, origin data.Point, opacity float32) image.Image {
+	if len(this.stops) == 0 {
+		return nil
+	}
+
+	// Determine the transform from gradient space onto pixels
+	m := ctm
+	if this.units == data.ObjectBoundingBox {
+		if bounds.W <= 0 || bounds.H <= 0 {
+			return nil
+		}
+		m = m.multiply(matrix{bounds.W, 0, 0, bounds.H, origin.X, origin.Y})
+	}
+	inverse, ok := m.multiply(this.transform).invert()
+	if ok == false {
+		return nil
+	}
+
+	// Compute a table of colors
+	paint := &gradientpaint{this, inverse, make([]color.RGBA64, gradientTableSize)}
+	for i := range paint.table {
+		paint.table[i] = this.colorAt(float32(i)/(gradientTableSize-1), opacity)
+	}
+
+	// Return the paint
+	return paint
+}
+
+// colorAt returns the premultiplied color at an offset between 0 and 1
+func (this *gradient) colorAt(t float32, opacity float32) color.RGBA64 {
+	var r, g, b, a float32
+	stops := this.stops
+	switch {
+	case t <= stops[0].offset:
+		r, g, b, a = rgba(stops[0])
+	case t >= stops[len(stops)-1].offset:
+		r, g, b, a = rgba(stops[len(stops)-1])
+	default:
+		for i := 1; i < len(stops); i++ {
+			if t > stops[i].offset {
+				continue
+			}
+			s0, s1 := stops[i-1], stops[i]
+			k := float32(0)
+			if s1.offset > s0.offset {
+				k = (t - s0.offset) / (s1.offset - s0.offset)
+			}
+			r0, g0, b0, a0 := rgba(s0)
+			r1, g1, b1, a1 := rgba(s1)
+			r, g, b, a = r0+(r1-r0)*k, g0+(g1-g0)*k, b0+(b1-b0)*k, a0+(a1-a0)*k
+			break
+		}
+	}
+	a *= opacity
+	return color.RGBA64{uint16(r*a*0xFFFF + 0.5), uint16(g*a*0xFFFF + 0.5), uint16(b*a*0xFFFF + 0.5), uint16(a*0xFFFF + 0.5)}
+}
+
+// offset returns the position on the gradient for a point in gradient
+// space, before the spread method is applied
+func (this *gradient) offset(pt data.Point) float32 {
+	if this.radial == false {
+		dx, dy := this.x2-this.x1, this.y2-this.y1
+		if dd := dx*dx + dy*dy; dd == 0 {
+			return 1
+		} else {
+			return ((pt.X-this.x1)*dx + (pt.Y-this.y1)*dy) / dd
+		}
+	}
+
+	// For a radial gradient, find the circle between the focal point and
+	// the end circle on which the point lies
+	if this.r <= 0 {
+		return 1
+	}
+	fx, fy := float64(this.fx), float64(this.fy)
+	dx, dy := float64(this.cx)-fx, float64(this.cy)-fy
+	qx, qy := float64(pt.X)-fx, float64(pt.Y)-fy
+	r := float64(this.r)
+	a := dx*dx + dy*dy - r*r
+	b := qx*dx + qy*dy
+	c := qx*qx + qy*qy
+	if a == 0 {
+		if b == 0 {
+			return 0
+		}
+		return float32(c / (2 * b))
+	}
+	disc := b*b - a*c
+	if disc < 0 {
+		return 0
+	}
+	return float32(math.Max((b-math.Sqrt(disc))/a, (b+math.Sqrt(disc))/a))
+}
+
+// pattern returns an image painted with a pattern element, for flattened
+// paths with a transform from user space onto pixels. Returns nil if
+// there is nothing to paint
+func (this *pngwriter) pattern(elem *Element, opacity float32, m matrix, paths []subpath) image.Image {
+	if this.depth >= patternMaxDepth {
+		return nil
+	}
+
+	// Determine the units and transform of the pattern
+	units, contentUnits := data.ObjectBoundingBox, data.UserSpaceOnUse
+	if attr, exists := elem.Attr("patternUnits"); exists && strings.TrimSpace(attr.Value) == data.UserSpaceOnUse.String() {
+		units = data.UserSpaceOnUse
+	}
+	if attr, exists := elem.Attr("patternContentUnits"); exists && strings.TrimSpace(attr.Value) == data.ObjectBoundingBox.String() {
+		contentUnits = data.ObjectBoundingBox
+	}
+	transform := identity
+	if attr, exists := elem.Attr("patternTransform"); exists {
+		if m_, err := parseTransform(attr.Value); err == nil {
+			transform = m_
+		}
+	}
+
+	// Determine the tile in pattern space
+	origin, bounds := pathBounds(paths)
+	length := func(name string, ref float32) float32 {
+		attr, exists := elem.Attr(name)
+		if exists == false {
+			return 0
+		}
+		value := strings.TrimSpace(attr.Value)
+		if strings.HasSuffix(value, "%") {
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 32); err == nil {
+				if units == data.ObjectBoundingBox {
+					return float32(v) / 100
+				} else {
+					return float32(v) * ref / 100
+				}
+			}
+		} else if v, err := parseUnitValue(value); err == nil {
+			return v
+		}
+		return 0
+	}
+	w, h := f32.Abs(this.canvas.size.W), f32.Abs(this.canvas.size.H)
+	x, y, tw, th := length("x", w), length("y", h), length("width", w), length("height", h)
+	if units == data.ObjectBoundingBox {
+		if bounds.W <= 0 || bounds.H <= 0 {
+			return nil
+		}
+		x, y = origin.X+x*bounds.W, origin.Y+y*bounds.H
+		tw, th = tw*bounds.W, th*bounds.H
+	}
+	if tw <= 0 || th <= 0 {
+		return nil
+	}
+
+	// Determine the size of the tile in pixels and the transform from
+	// pixels onto the tile
+	pm := m.multiply(transform)
+	inverse, ok := pm.invert()
+	if ok == false {
+		return nil
+	}
+	scale := pm.scale()
+	cols := int(f32.Max(f32.Min(f32.Ceil(tw*scale), patternMaxTile), 1))
+	rows := int(f32.Max(f32.Min(f32.Ceil(th*scale), patternMaxTile), 1))
+	sx, sy := float32(cols)/tw, float32(rows)/th
+	inverse = matrix{sx, 0, 0, sy, -x * sx, -y * sy}.multiply(inverse)
+
+	// Determine the transform from the pattern contents onto the tile
+	content := scaleMatrix(sx, sy)
+	if vbOrigin, vbSize, err := viewBoxFromAttr(elem); err == nil && vbSize != data.ZeroSize {
+		content = content.multiply(viewBoxMatrix(vbOrigin, vbSize, data.Size{tw, th}))
+	} else if contentUnits == data.ObjectBoundingBox {
+		content = content.multiply(scaleMatrix(bounds.W, bounds.H))
+	}
+
+	// Render the contents onto the tile, with the style inherited from
+	// the pattern element
+	tile := image.NewRGBA(image.Rect(0, 0, cols, rows))
+	writer := newPNGWriter(this.canvas, tile, content)
+	writer.depth = this.depth + 1
+	style := this.canvas.inheritedStyle(elem.Node)
+	for _, child := range elem.Children() {
+		if err := this.canvas.renderNode(writer, child, style); err != nil {
+			return nil
+		}
+	}
+
+	// Return the paint
+	return &patternpaint{tile, inverse, opacity}
+}
+
+// inheritedStyle returns the computed style for a node, inherited from
+// the root element
+func (this *Canvas) inheritedStyle(node data.Node) *renderstyle {
+	chain := []data.Node{}
+	for ; node != nil; node = node.Parent() {
+		chain = append(chain, node)
+	}
+	style := this.rootStyle()
+	for i := len(chain) - 1; i >= 0; i-- {
+		style = style.inherit(chain[i])
+	}
+	return style
+}
+
+/////////////////////////////////////////////////////////////////////
+// IMAGE METHODS
+
+func (this *gradientpaint) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (this *gradientpaint) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (this *gradientpaint) At(x, y int) color.Color {
+	t := this.offset(this.inverse.apply(data.Point{float32(x) + 0.5, float32(y) + 0.5}))
+	switch this.spread {
+	case data.SpreadRepeat:
+		t -= f32.Floor(t)
+	case data.SpreadReflect:
+		t = f32.Abs(t)
+		t -= 2 * f32.Floor(t/2)
+		if t > 1 {
+			t = 2 - t
+		}
+	}
+	i := int(f32.Max(f32.Min(t, 1), 0)*(gradientTableSize-1) + 0.5)
+	return this.table[i]
+}
+
+func (this *patternpaint) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (this *patternpaint) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (this *patternpaint) At(x, y int) color.Color {
+	pt := this.inverse.apply(data.Point{float32(x) + 0.5, float32(y) + 0.5})
+	size := this.tile.Bounds().Size()
+	col, row := int(f32.Floor(pt.X))%size.X, int(f32.Floor(pt.Y))%size.Y
+	if col < 0 {
+		col += size.X
+	}
+	if row < 0 {
+		row += size.Y
+	}
+	c := this.tile.RGBAAt(col, row)
+	a := this.opacity * 0x101
+	return color.RGBA64{uint16(float32(c.R)*a + 0.5), uint16(float32(c.G)*a + 0.5), uint16(float32(c.B)*a + 0.5), uint16(float32(c.A)*a + 0.5)}
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// rgba returns the color and opacity of a stop as values between 0 and 1
+func rgba(stop gradientstop) (float32, float32, float32, float32) {
+	return float32(stop.color.R) / 0xFF, float32(stop.color.G) / 0xFF, float32(stop.color.B) / 0xFF, stop.opacity
+}
+
+// pathBounds returns the origin and size of the bounding box for
+// flattened paths
+func pathBounds(paths []subpath) (data.Point, data.Size) {
+	first := true
+	var minx, miny, maxx, maxy float32
+	for _, path := range paths {
+		for _, pt := range path.pts {
+			if first {
+				minx, miny, maxx, maxy = pt.X, pt.Y, pt.X, pt.Y
+				first = false
+			} else {
+				minx, miny = f32.Min(minx, pt.X), f32.Min(miny, pt.Y)
+				maxx, maxy = f32.Max(maxx, pt.X), f32.Max(maxy, pt.Y)
+			}
+		}
+	}
+	return data.Point{minx, miny}, data.Size{maxx - minx, maxy - miny}
+}
@@ -1,6 +1,8 @@
 package canvas
 
 import (
+	"strings"
+
 	"github.com/djthorpe/data"
 	"github.com/djthorpe/data/pkg/f32"
 )
@@ -23,14 +25,191 @@ func (*Canvas) LineTo(pt data.Point) data.CanvasPath {
 	return NewPathSegment("L", pt.X, pt.Y)
 }
 
+func (*Canvas) HorizontalTo(x float32) data.CanvasPath {
+	return NewPathSegment("H", x)
+}
+
+func (*Canvas) VerticalTo(y float32) data.CanvasPath {
+	return NewPathSegment("V", y)
+}
+
 func (*Canvas) QuadraticTo(pt, c data.Point) data.CanvasPath {
 	return NewPathSegment("Q", c.X, c.Y, pt.X, pt.Y)
 }
 
+// SmoothQuadraticTo draws a quadratic curve where the control point is the
+// reflection of the control point of the previous curve
+func (*Canvas) SmoothQuadraticTo(pt data.Point) data.CanvasPath {
+	return NewPathSegment("T", pt.X, pt.Y)
+}
+
 func (*Canvas) CubicTo(pt, c1, c2 data.Point) data.CanvasPath {
 	return NewPathSegment("C", c1.X, c1.Y, c2.X, c2.Y, pt.X, pt.Y)
 }
 
+// SmoothCubicTo draws a cubic curve where the first control point is the
+// reflection of the second control point of the previous curve
+func (*Canvas) SmoothCubicTo(pt, c2 data.Point) data.CanvasPath {
+	return NewPathSegment("S", c2.X, c2.Y, pt.X, pt.Y)
+}
+
+// ArcTo draws an elliptical arc with radii r, rotated by angle in degrees.
+// Of the four possible arcs, large selects an arc greater than 180 degrees
+// and sweep selects an arc drawn in the positive-angle direction
+func (*Canvas) ArcTo(pt data.Point, r data.Size, angle float32, large, sweep bool) data.CanvasPath {
+	return NewPathSegment("A", r.W, r.H, angle, flag(large), flag(sweep), pt.X, pt.Y)
+}
+
 func (*Canvas) ClosePath() data.CanvasPath {
 	return NewPathSegment("Z")
 }
+
+/////////////////////////////////////////////////////////////////////
+// RELATIVE PATH PRIMITIVES
+
+func (*Canvas) MoveToRel(pt data.Point) data.CanvasPath {
+	return NewPathSegment("m", pt.X, pt.Y)
+}
+
+func (*Canvas) LineToRel(pt data.Point) data.CanvasPath {
+	return NewPathSegment("l", pt.X, pt.Y)
+}
+
+func (*Canvas) HorizontalToRel(dx float32) data.CanvasPath {
+	return NewPathSegment("h", dx)
+}
+
+func (*Canvas) VerticalToRel(dy float32) data.CanvasPath {
+	return NewPathSegment("v", dy)
+}
+
+func (*Canvas) QuadraticToRel(pt, c data.Point) data.CanvasPath {
+	return NewPathSegment("q", c.X, c.Y, pt.X, pt.Y)
+}
+
+func (*Canvas) SmoothQuadraticToRel(pt data.Point) data.CanvasPath {
+	return NewPathSegment("t", pt.X, pt.Y)
+}
+
+func (*Canvas) CubicToRel(pt, c1, c2 data.Point) data.CanvasPath {
+	return NewPathSegment("c", c1.X, c1.Y, c2.X, c2.Y, pt.X, pt.Y)
+}
+
+func (*Canvas) SmoothCubicToRel(pt, c2 data.Point) data.CanvasPath {
+	return NewPathSegment("s", c2.X, c2.Y, pt.X, pt.Y)
+}
+
+func (*Canvas) ArcToRel(pt data.Point, r data.Size, angle float32, large, sweep bool) data.CanvasPath {
+	return NewPathSegment("a", r.W, r.H, angle, flag(large), flag(sweep), pt.X, pt.Y)
+}
+
+/////////////////////////////////////////////////////////////////////
+// PARSE PATH DATA
+
+// ParsePath returns path segments from SVG path data, as used in the "d"
+// attribute of a path element. Relative co-ordinates are converted to
+// absolute co-ordinates, and horizontal, vertical, smooth and arc
+// commands are converted to lines and curves
+func ParsePath(value string) ([]data.CanvasPath, error) {
+	path, err := parsePathData(value)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]data.CanvasPath, 0, len(path))
+	for _, seg := range path {
+		args := make([]float32, 0, len(seg.pts)*2)
+		for _, pt := range seg.pts {
+			args = append(args, pt.X, pt.Y)
+		}
+		result = append(result, NewPathSegment(string(seg.op), args...))
+	}
+	return result, nil
+}
+
+/////////////////////////////////////////////////////////////////////
+// POLYGONS AND POLYLINES
+
+// PathPolygons returns path segments as polygons, with curves flattened
+// into lines where tolerance is the maximum distance between a curve and
+// the lines. Each polygon is closed implicitly, as when the path is filled,
+// so that the polygons can be used with boolean operations in pkg/geom
+func PathPolygons(paths []data.CanvasPath, tolerance float32) ([][]data.Point, error) {
+	if tolerance <= 0 {
+		return nil, data.ErrBadParameter.WithPrefix("PathPolygons: tolerance")
+	}
+	path, err := pathOps(paths)
+	if err != nil {
+		return nil, err
+	}
+	result := [][]data.Point{}
+	for _, subpath := range flatten(path, tolerance) {
+		if len(subpath.pts) > 1 {
+			result = append(result, subpath.pts)
+		}
+	}
+	return result, nil
+}
+
+// PathPolylines returns path segments as polylines, with curves flattened
+// into lines where tolerance is the maximum distance between a curve and
+// the lines. A closed subpath ends with its first point, so that the
+// polylines can be measured using geom.Length and geom.PointAtLength
+func PathPolylines(paths []data.CanvasPath, tolerance float32) ([][]data.Point, error) {
+	if tolerance <= 0 {
+		return nil, data.ErrBadParameter.WithPrefix("PathPolylines: tolerance")
+	}
+	path, err := pathOps(paths)
+	if err != nil {
+		return nil, err
+	}
+	result := [][]data.Point{}
+	for _, subpath := range flatten(path, tolerance) {
+		if subpath.closed {
+			subpath.pts = append(subpath.pts, subpath.pts[0])
+		}
+		result = append(result, subpath.pts)
+	}
+	return result, nil
+}
+
+// PolygonPath returns path segments which draw one or more polygons as
+// closed subpaths
+func PolygonPath(polys ...[]data.Point) []data.CanvasPath {
+	result := []data.CanvasPath{}
+	for _, poly := range polys {
+		if len(poly) == 0 {
+			continue
+		}
+		result = append(result, NewPathSegment("M", poly[0].X, poly[0].Y))
+		for _, pt := range poly[1:] {
+			result = append(result, NewPathSegment("L", pt.X, pt.Y))
+		}
+		result = append(result, NewPathSegment("Z"))
+	}
+	return result
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// pathOps returns the segments of a path created by the path methods
+func pathOps(paths []data.CanvasPath) ([]pathop, error) {
+	d := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if segment, ok := path.(PathSegment); ok == false {
+			return nil, data.ErrBadParameter.WithPrefix("Invalid path segment")
+		} else if segment != "" {
+			d = append(d, string(segment))
+		}
+	}
+	return parsePathData(strings.Join(d, " "))
+}
+
+// flag returns 1 for true and 0 for false
+func flag(value bool) float32 {
+	if value {
+		return 1
+	} else {
+		return 0
+	}
+}
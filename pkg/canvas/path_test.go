@@ -0,0 +1,166 @@
+package canvas_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	geom "github.com/djthorpe/data/pkg/geom"
+)
+
+func Test_Path_001(t *testing.T) {
+	tests := []struct {
+		d, expected string
+	}{
+		{"M10 10 c 5 5 10 0 15 5 z", "[M 10 10 C 15 15 20 10 25 15 Z]"},
+		{"M10,10L20,20l10-10", "[M 10 10 L 20 20 L 30 10]"},
+		{"M0 0 10 10 20 0", "[M 0 0 L 10 10 L 20 0]"},
+		{"m5 5 5 5", "[M 5 5 L 10 10]"},
+		{"M0 0H10V10h-5v-5", "[M 0 0 L 10 0 L 10 10 L 5 10 L 5 5]"},
+		{"M0 0Q5 5 10 0T20 0", "[M 0 0 Q 5 5 10 0 Q 15 -5 20 0]"},
+		{"M0 0C0 5 5 10 10 10S20 5 20 0", "[M 0 0 C 0 5 5 10 10 10 C 15 10 20 5 20 0]"},
+		{"M0 0S5 5 10 0", "[M 0 0 C 0 0 5 5 10 0]"},
+		{"M.5.5-1e1-1.5e+1", "[M 0.500000 0.500000 L -10 -15]"},
+		{"M0 0 10 0z m5 5 l5 5", "[M 0 0 L 10 0 Z M 5 5 L 10 10]"},
+		{"M0 0A10 10 0 0 1 10 10", "[M 0 0 C 5.522848 0.000000 10 4.477152 10 10]"},
+		{"M0 0a10 10 0 0010 10", "[M 0 0 C 0.000000 5.522848 4.477152 10 10 10]"},
+	}
+	for _, test := range tests {
+		if path, err := canvas.ParsePath(test.d); err != nil {
+			t.Error(test.d, ": ", err)
+		} else if str := fmt.Sprint(path); str != test.expected {
+			t.Errorf("%q: Expected %v, got %v", test.d, test.expected, str)
+		}
+	}
+}
+
+func Test_Path_002(t *testing.T) {
+	for _, d := range []string{"10 10", "M10", "M0 0 X", "M0 0 z 10", "M0 0 A 1 1 0 2 0 1 1"} {
+		if _, err := canvas.ParsePath(d); err == nil {
+			t.Error("Expected error for ", d)
+		}
+	}
+}
+
+func Test_Path_003(t *testing.T) {
+	// Large arc sweeping through three quarters of a circle
+	if path, err := canvas.ParsePath("M10 0A10 10 0 1 1 0 -10"); err != nil {
+		t.Error(err)
+	} else if len(path) != 4 {
+		t.Error("Expected three curves, got ", path)
+	} else if str := fmt.Sprint(path[3]); strings.HasSuffix(str, " 0 -10") == false {
+		t.Error("Unexpected end point ", str)
+	}
+}
+
+func Test_Path_004(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	p := c.Path(
+		c.MoveTo(data.Point{10, 10}),
+		c.HorizontalTo(20),
+		c.VerticalTo(20),
+		c.SmoothQuadraticTo(data.Point{30, 30}),
+		c.SmoothCubicTo(data.Point{40, 40}, data.Point{35, 40}),
+		c.ArcTo(data.Point{50, 50}, data.Size{5, 10}, 30, true, false),
+		c.ClosePath(),
+	)
+	if str := fmt.Sprint(p); str != `<path d="M 10 10 H 20 V 20 T 30 30 S 35 40 40 40 A 5 10 30 1 0 50 50 Z"></path>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Path_005(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	p := c.Path(
+		c.MoveToRel(data.Point{10, 10}),
+		c.LineToRel(data.Point{5, 0}),
+		c.HorizontalToRel(5),
+		c.VerticalToRel(5),
+		c.QuadraticToRel(data.Point{10, 0}, data.Point{5, 5}),
+		c.SmoothQuadraticToRel(data.Point{10, 0}),
+		c.CubicToRel(data.Point{10, 0}, data.Point{0, 5}, data.Point{10, 5}),
+		c.SmoothCubicToRel(data.Point{10, 0}, data.Point{10, 5}),
+		c.ArcToRel(data.Point{-20, 0}, data.Size{10, 10}, 0, false, true),
+		c.ClosePath(),
+	)
+	if str := fmt.Sprint(p); str != `<path d="m 10 10 l 5 0 h 5 v 5 q 5 5 10 0 t 10 0 c 0 5 10 5 10 0 s 10 5 10 0 a 10 10 0 0 1 -20 0 Z"></path>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+
+	// Relative segments are converted to absolute segments when parsed
+	attr, _ := c.DOM().FirstChild().Attr("d")
+	if path, err := canvas.ParsePath(attr.Value); err != nil {
+		t.Error(err)
+	} else if str := fmt.Sprint(path[:5]); str != `[M 10 10 L 15 10 L 20 10 L 20 15 Q 25 20 30 15]` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Path_006(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	a, err := canvas.PathPolygons([]data.CanvasPath{
+		c.MoveTo(data.Point{0, 0}),
+		c.HorizontalTo(20),
+		c.VerticalTo(20),
+		c.HorizontalTo(0),
+		c.ClosePath(),
+	}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := canvas.PathPolygons([]data.CanvasPath{
+		c.MoveTo(data.Point{10, 10}),
+		c.LineToRel(data.Point{20, 0}),
+		c.LineToRel(data.Point{0, 20}),
+		c.LineToRel(data.Point{-20, 0}),
+	}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := geom.Intersection(a, b, data.NonZero)
+	if str := fmt.Sprint(c.Path(canvas.PolygonPath(result...)...)); str != `<path d="M 20 10 L 20 20 L 10 20 L 10 10 Z"></path>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+
+	// Curves are flattened within the tolerance
+	circle, err := canvas.ParsePath("M 60 50 A 10 10 0 0 1 40 50 A 10 10 0 0 1 60 50 Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polys, err := canvas.PathPolygons(circle, 0.01); err != nil {
+		t.Error(err)
+	} else if len(polys) != 1 || geom.Area(polys[0]) < 313.5 || geom.Area(polys[0]) > 314.2 {
+		t.Error("Unexpected polygons, got: ", polys)
+	}
+	if _, err := canvas.PathPolygons(circle, 0); err == nil {
+		t.Error("Expected error for zero tolerance")
+	}
+}
+
+func Test_Path_007(t *testing.T) {
+	// A square with a closing line, and an open semi-circle
+	path, err := canvas.ParsePath("M 0 0 H 10 V 10 H 0 Z M 20 0 A 10 10 0 0 1 40 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	polylines, err := canvas.PathPolylines(path, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polylines) != 2 || len(polylines[0]) != 5 {
+		t.Fatal("Unexpected polylines, got: ", polylines)
+	}
+	if length := geom.Length(polylines...); length < 71.4 || length > 71.5 {
+		t.Error("Unexpected length, got: ", length)
+	}
+	if pt, angle, ok := geom.PointAtLength(15, polylines...); ok == false || pt != (data.Point{10, 5}) || angle != 90 {
+		t.Error("Unexpected point, got: ", pt, angle)
+	}
+	if pt, angle, ok := geom.PointAtLength(40+31.4159/2, polylines...); ok == false || geom.IsNilPoint(pt) {
+		t.Error("Unexpected point, got: ", pt)
+	} else if pt.X < 29.99 || pt.X > 30.01 || pt.Y < -10.01 || pt.Y > -9.99 || angle < -1 || angle > 1 {
+		t.Error("Unexpected point, got: ", pt, angle)
+	}
+}
@@ -0,0 +1,144 @@
+package canvas
+
+import (
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/geom"
+)
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// parsePathData returns path segments from the "d" attribute of a path,
+// for all absolute and relative commands. Horizontal and vertical lines
+// are converted to lines, smooth curves to curves with reflected control
+// points and elliptical arcs to cubic curves
+func parsePathData(value string) ([]pathop, error) {
+	s := newScanner(value)
+	path := []pathop{}
+
+	var cmd, prev byte
+	var start, pt, ctrl data.Point
+	for s.eof() == false {
+		if c := s.command(); c != 0 {
+			cmd = c
+		} else if cmd == 0 {
+			return nil, s.errorf("Expected command")
+		} else if cmd == 'Z' || cmd == 'z' {
+			return nil, s.errorf("Unexpected number")
+		}
+
+		// Relative co-ordinates are relative to the current point
+		var origin data.Point
+		if cmd >= 'a' && cmd <= 'z' {
+			origin = pt
+		}
+
+		// Read arguments
+		var args []float32
+		switch cmd {
+		case 'Z', 'z':
+		case 'H', 'h', 'V', 'v':
+			args = make([]float32, 1)
+		case 'M', 'm', 'L', 'l', 'T', 't':
+			args = make([]float32, 2)
+		case 'S', 's', 'Q', 'q':
+			args = make([]float32, 4)
+		case 'C', 'c':
+			args = make([]float32, 6)
+		case 'A', 'a':
+			args = make([]float32, 7)
+		default:
+			return nil, s.errorf("Unsupported path command")
+		}
+		for i := range args {
+			var err error
+			if (cmd == 'A' || cmd == 'a') && (i == 3 || i == 4) {
+				var flag bool
+				if flag, err = s.flag(); flag {
+					args[i] = 1
+				}
+			} else {
+				args[i], err = s.number()
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Append path segments
+		switch cmd {
+		case 'Z', 'z':
+			path = append(path, pathop{'Z', nil})
+			pt = start
+		case 'M', 'm':
+			pt = data.Point{origin.X + args[0], origin.Y + args[1]}
+			path = append(path, pathop{'M', []data.Point{pt}})
+			start = pt
+			// Subsequent co-ordinate pairs are lines
+			if cmd == 'M' {
+				cmd = 'L'
+			} else {
+				cmd = 'l'
+			}
+		case 'L', 'l':
+			pt = data.Point{origin.X + args[0], origin.Y + args[1]}
+			path = append(path, pathop{'L', []data.Point{pt}})
+		case 'H', 'h':
+			pt = data.Point{origin.X + args[0], pt.Y}
+			path = append(path, pathop{'L', []data.Point{pt}})
+		case 'V', 'v':
+			pt = data.Point{pt.X, origin.Y + args[0]}
+			path = append(path, pathop{'L', []data.Point{pt}})
+		case 'C', 'c':
+			c1 := data.Point{origin.X + args[0], origin.Y + args[1]}
+			ctrl = data.Point{origin.X + args[2], origin.Y + args[3]}
+			pt = data.Point{origin.X + args[4], origin.Y + args[5]}
+			path = append(path, pathop{'C', []data.Point{c1, ctrl, pt}})
+		case 'S', 's':
+			c1 := pt
+			if prev == 'C' || prev == 'S' {
+				c1 = data.Point{2*pt.X - ctrl.X, 2*pt.Y - ctrl.Y}
+			}
+			ctrl = data.Point{origin.X + args[0], origin.Y + args[1]}
+			pt = data.Point{origin.X + args[2], origin.Y + args[3]}
+			path = append(path, pathop{'C', []data.Point{c1, ctrl, pt}})
+		case 'Q', 'q':
+			ctrl = data.Point{origin.X + args[0], origin.Y + args[1]}
+			pt = data.Point{origin.X + args[2], origin.Y + args[3]}
+			path = append(path, pathop{'Q', []data.Point{ctrl, pt}})
+		case 'T', 't':
+			if prev == 'Q' || prev == 'T' {
+				ctrl = data.Point{2*pt.X - ctrl.X, 2*pt.Y - ctrl.Y}
+			} else {
+				ctrl = pt
+			}
+			pt = data.Point{origin.X + args[0], origin.Y + args[1]}
+			path = append(path, pathop{'Q', []data.Point{ctrl, pt}})
+		case 'A', 'a':
+			end := data.Point{origin.X + args[5], origin.Y + args[6]}
+			path = append(path, arcPath(pt, data.Size{args[0], args[1]}, args[2], args[3] != 0, args[4] != 0, end)...)
+			pt = end
+		}
+
+		// Record the previous command for smooth curves
+		prev = cmd &^ 0x20
+	}
+	return path, nil
+}
+
+// arcPath returns cubic curves approximating an elliptical arc from p0
+// to p1, where each curve spans no more than a quarter of the ellipse
+func arcPath(p0 data.Point, r data.Size, angle float32, large, sweep bool, p1 data.Point) []pathop {
+	// Arcs with zero radius are lines, and identical end points are omitted
+	if p0 == p1 {
+		return nil
+	} else if r.W == 0 || r.H == 0 {
+		return []pathop{{'L', []data.Point{p1}}}
+	}
+	curves := geom.ArcToCubic(p0, r, angle, large, sweep, p1)
+	path := make([]pathop, 0, len(curves))
+	for _, pts := range curves {
+		path = append(path, pathop{'C', pts})
+	}
+	return path
+}
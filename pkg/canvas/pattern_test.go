@@ -0,0 +1,80 @@
+package canvas_test
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+)
+
+func Test_Pattern_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	p := c.Pattern(data.ZeroPoint, data.Size{10, 10}, c.Line(data.Point{0, 0}, data.Point{10, 10})).
+		PatternUnits(data.UserSpaceOnUse)
+	if p == nil {
+		t.Fatal("Unexpected nil from c.Pattern")
+	} else if p.Id("hatch") == nil {
+		t.Fatal("Unexpected nil from p.Id")
+	} else if str := fmt.Sprint(p); str != `<pattern x="0" y="0" width="10" height="10" patternUnits="userSpaceOnUse" id="hatch"><line x1="0" y1="0" x2="10" y2="10"></line></pattern>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if str := fmt.Sprint(c.DOM().FirstChild()); str != `<defs>`+fmt.Sprint(p)+`</defs>` {
+		t.Error("Expected pattern in defs, got: ", str)
+	}
+	if p.Transform(c.Rotate(45)) == nil {
+		t.Error("Unexpected nil from p.Transform")
+	} else if attr, exists := p.(data.Node).Attr("patternTransform"); exists == false || attr.Value != "rotate(45)" {
+		t.Error("Expected patternTransform, got: ", p)
+	}
+	if g := c.Group().PatternUnits(data.UserSpaceOnUse); g != nil {
+		t.Error("Expected nil for pattern units on group")
+	}
+}
+
+func Test_Pattern_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	if r := c.Rect(data.ZeroPoint, data.Size{10, 10}).Style(c.FillPattern("hatch"), c.StrokePattern("#dots")); r == nil {
+		t.Error("Unexpected nil from c.Rect")
+	} else if str := fmt.Sprint(r); str != `<rect x="0" y="0" width="10" height="10" style="fill: url(#hatch); stroke: url(#dots);"></rect>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+}
+
+func Test_Pattern_003(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{40, 10}, data.PX)
+	c.Pattern(data.ZeroPoint, data.Size{10, 10}, c.Rect(data.ZeroPoint, data.Size{5, 10})).
+		PatternUnits(data.UserSpaceOnUse).Id("bars")
+	c.Rect(data.ZeroPoint, data.Size{40, 10}).Style(c.FillPattern("bars"))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 40; x++ {
+		_, _, _, a := img.At(x, 5).RGBA()
+		if x%10 < 5 && a != 0xFFFF {
+			t.Error("Expected opaque pixel at ", x, ": ", img.At(x, 5))
+		} else if x%10 >= 5 && a != 0 {
+			t.Error("Expected transparent pixel at ", x, ": ", img.At(x, 5))
+		}
+	}
+}
+
+func Test_Pattern_004(t *testing.T) {
+	r := strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><defs><pattern id="p" width="2" height="2" patternUnits="userSpaceOnUse" patternTransform="rotate(45)"><rect width="1" height="2"></rect></pattern></defs><rect width="10" height="10" fill="url(#p)"></rect></svg>`)
+	if _, err := canvas.Read(data.SVG, r); err != nil {
+		t.Error(err)
+	}
+	r = strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg"><pattern patternTransform="rotate(x)"></pattern></svg>`)
+	if _, err := canvas.Read(data.SVG, r); err == nil {
+		t.Error("Expected error for invalid patternTransform")
+	}
+}
@@ -0,0 +1,497 @@
+package canvas
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// pdfwriter renders canvas elements into a PDF content stream
+type pdfwriter struct {
+	content bytes.Buffer
+	fonts   []string
+	states  []pdfstate
+	layers  []pdfstate // Opacity and blend mode of each layer
+	stack   int
+}
+
+// pdfstate is a graphics state with fill and stroke opacity and a
+// blend mode
+type pdfstate struct {
+	fill, stroke float32
+	blend        data.BlendMode
+}
+
+// pdfobjects writes numbered objects and records their offsets
+type pdfobjects struct {
+	w       io.Writer
+	n       int64
+	offsets []int64
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Points per CSS pixel
+	pointsPerPixel = 72.0 / 96.0
+)
+
+/////////////////////////////////////////////////////////////////////
+// WRITE CANVAS
+
+func (this *Canvas) writePDF(fmt data.Writer, w io.Writer) error {
+	// Determine the size of the page in points
+	size, err := sizeFromAttr(this.Document, this.size)
+	if err != nil {
+		return err
+	} else if size.W <= 0 || size.H <= 0 {
+		return data.ErrBadParameter.WithPrefix("writePDF: Invalid size")
+	}
+	page := data.Size{size.W * pointsPerPixel, size.H * pointsPerPixel}
+
+	// Render the content stream, with the origin at the top left
+	pdf := new(pdfwriter)
+	if err := pdf.push(matrix{1, 0, 0, -1, 0, page.H}.multiply(viewBoxMatrix(this.origin, this.size, page))); err != nil {
+		return err
+	} else if err := this.render(pdf); err != nil {
+		return err
+	} else if err := pdf.pop(); err != nil {
+		return err
+	}
+
+	// Write the document
+	return pdf.write(w, page, this.titleString(), fmt&data.Compress == data.Compress)
+}
+
+/////////////////////////////////////////////////////////////////////
+// RENDERER METHODS
+
+func (this *pdfwriter) push(m matrix) error {
+	this.stack++
+	this.content.WriteString("q\n")
+	if m != identity {
+		this.content.WriteString(pdfNumbers(m[:]...) + " cm\n")
+	}
+	return nil
+}
+
+func (this *pdfwriter) pop() error {
+	if this.stack == 0 {
+		return data.ErrInternalAppError.WithPrefix("pop")
+	}
+	this.stack--
+	this.content.WriteString("Q\n")
+	return nil
+}
+
+func (this *pdfwriter) path(path []pathop, style *renderstyle) error {
+	fill := style.fill != nil && style.fillOpacity > 0
+	stroke := style.stroke != nil && style.strokeOpacity > 0 && style.strokeWidth > 0
+	if fill == false && stroke == false {
+		return nil
+	}
+
+	// Set graphics state
+	this.content.WriteString("q\n")
+	this.setOpacity(style.fillOpacity, style.strokeOpacity)
+	if fill {
+		this.content.WriteString(pdfColor(*style.fill) + " rg\n")
+	}
+	if stroke {
+		this.content.WriteString(pdfColor(*style.stroke) + " RG\n")
+		this.content.WriteString(pdfNumbers(style.strokeWidth) + " w\n")
+		this.content.WriteString(fmt.Sprint(pdfLineCap(style.lineCap), " J ", pdfLineJoin(style.lineJoin), " j "))
+		this.content.WriteString(pdfNumbers(style.miterLimit) + " M\n")
+		if len(style.dashes) > 0 {
+			this.content.WriteString("[" + pdfNumbers(style.dashes...) + "] " + pdfNumbers(style.dashOffset) + " d\n")
+		}
+	}
+
+	// Construct path
+	this.writePath(path)
+
+	// Paint path
+	evenodd := ""
+	if style.fillRule == data.EvenOdd {
+		evenodd = "*"
+	}
+	switch {
+	case fill && stroke:
+		this.content.WriteString("B" + evenodd + "\n")
+	case fill:
+		this.content.WriteString("f" + evenodd + "\n")
+	default:
+		this.content.WriteString("S\n")
+	}
+
+	// Restore graphics state
+	this.content.WriteString("Q\n")
+
+	// Return success
+	return nil
+}
+
+func (this *pdfwriter) clip(shapes []clipshape) error {
+	if len(shapes) == 0 {
+		this.content.WriteString("0 0 0 0 re W n\n")
+		return nil
+	}
+	for _, shape := range shapes {
+		this.writePath(shape.path)
+	}
+	if shapes[0].rule == data.EvenOdd {
+		this.content.WriteString("W* n\n")
+	} else {
+		this.content.WriteString("W n\n")
+	}
+
+	// Return success
+	return nil
+}
+
+func (this *pdfwriter) beginMask() error {
+	// Masks are not applied in PDF documents
+	return nil
+}
+
+func (this *pdfwriter) endMask(mask *Element, m matrix) error {
+	// Masks are not applied in PDF documents
+	return nil
+}
+
+func (this *pdfwriter) beginLayer(opacity float32, blend data.BlendMode) error {
+	// Layers are not isolated in PDF documents, so the opacity of a
+	// layer is applied to each element drawn within the layer
+	this.layers = append(this.layers, pdfstate{opacity, opacity, blend})
+	return nil
+}
+
+func (this *pdfwriter) endLayer() error {
+	if len(this.layers) == 0 {
+		return data.ErrInternalAppError.WithPrefix("endLayer")
+	}
+	this.layers = this.layers[:len(this.layers)-1]
+	return nil
+}
+
+func (this *pdfwriter) image(pt data.Point, size data.Size, href string, aspect aspectratio) error {
+	// Images are not rendered in PDF documents
+	return nil
+}
+
+func (this *pdfwriter) text(pt data.Point, value string, style *renderstyle) error {
+	if style.fill == nil || style.fillOpacity == 0 || value == "" {
+		return nil
+	}
+	font := style.font()
+	this.content.WriteString("q\n")
+	this.setOpacity(style.fillOpacity, style.strokeOpacity)
+	this.content.WriteString(pdfColor(*style.fill) + " rg\n")
+	this.content.WriteString("BT\n")
+	this.content.WriteString("/" + this.fontResource(font.name) + " " + pdfNumbers(style.fontSize) + " Tf\n")
+
+	// Flip the text back into the right orientation
+	this.content.WriteString(pdfNumbers(1, 0, 0, -1, pt.X, pt.Y) + " Tm\n")
+	this.content.WriteString(pdfGlyphs(value) + " Tj\n")
+	this.content.WriteString("ET\n")
+	this.content.WriteString("Q\n")
+
+	// Return success
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// setOpacity sets the graphics state for fill and stroke opacity, and
+// the opacity and blend mode of any layers
+func (this *pdfwriter) setOpacity(fill, stroke float32) {
+	key := pdfstate{fill, stroke, data.BlendNormal}
+	for _, layer := range this.layers {
+		key.fill, key.stroke = key.fill*layer.fill, key.stroke*layer.stroke
+		if layer.blend != data.BlendNormal {
+			key.blend = layer.blend
+		}
+	}
+	if key == (pdfstate{1, 1, data.BlendNormal}) {
+		return
+	}
+	for i, state := range this.states {
+		if state == key {
+			this.content.WriteString(fmt.Sprintf("/GS%d gs\n", i+1))
+			return
+		}
+	}
+	this.states = append(this.states, key)
+	this.content.WriteString(fmt.Sprintf("/GS%d gs\n", len(this.states)))
+}
+
+// fontResource returns the resource name for a standard font
+func (this *pdfwriter) fontResource(name string) string {
+	for i, font := range this.fonts {
+		if font == name {
+			return fmt.Sprint("F", i+1)
+		}
+	}
+	this.fonts = append(this.fonts, name)
+	return fmt.Sprint("F", len(this.fonts))
+}
+
+// write the document with a single page
+func (this *pdfwriter) write(w io.Writer, page data.Size, title string, compress bool) error {
+	doc := &pdfobjects{w: w}
+	if err := doc.printf("%%PDF-1.4\n%%\xE2\xE3\xCF\xD3\n"); err != nil {
+		return err
+	}
+
+	// Object numbers: 1 catalog, 2 pages, 3 page, 4 content, 5 info,
+	// followed by fonts and graphics states
+	resources := new(strings.Builder)
+	obj := 6
+	if len(this.fonts) > 0 {
+		resources.WriteString(" /Font <<")
+		for i := range this.fonts {
+			fmt.Fprintf(resources, " /F%d %d 0 R", i+1, obj+i)
+		}
+		resources.WriteString(" >>")
+	}
+	if len(this.states) > 0 {
+		resources.WriteString(" /ExtGState <<")
+		for i := range this.states {
+			fmt.Fprintf(resources, " /GS%d %d 0 R", i+1, obj+len(this.fonts)+i)
+		}
+		resources.WriteString(" >>")
+	}
+
+	// Content stream
+	content := this.content.Bytes()
+	filter := ""
+	if compress {
+		b := new(bytes.Buffer)
+		z := zlib.NewWriter(b)
+		if _, err := z.Write(content); err != nil {
+			return err
+		} else if err := z.Close(); err != nil {
+			return err
+		}
+		content, filter = b.Bytes(), " /Filter /FlateDecode"
+	}
+
+	// Write objects
+	if err := doc.object("<< /Type /Catalog /Pages 2 0 R >>"); err != nil {
+		return err
+	}
+	if err := doc.object("<< /Type /Pages /Kids [3 0 R] /Count 1 >>"); err != nil {
+		return err
+	}
+	if err := doc.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s] /Resources <<%s >> /Contents 4 0 R >>", pdfNumbers(page.W, page.H), resources)); err != nil {
+		return err
+	}
+	if err := doc.object(fmt.Sprintf("<< /Length %d%s >>\nstream\n%s\nendstream", len(content), filter, content)); err != nil {
+		return err
+	}
+	if title != "" {
+		if err := doc.object("<< /Title " + pdfText(title) + " /Producer (github.com/djthorpe/data) >>"); err != nil {
+			return err
+		}
+	} else if err := doc.object("<< /Producer (github.com/djthorpe/data) >>"); err != nil {
+		return err
+	}
+	for _, font := range this.fonts {
+		encoding := " /Encoding /WinAnsiEncoding"
+		if font == "Symbol" || font == "ZapfDingbats" {
+			encoding = ""
+		}
+		if err := doc.object("<< /Type /Font /Subtype /Type1 /BaseFont /" + font + encoding + " >>"); err != nil {
+			return err
+		}
+	}
+	for _, state := range this.states {
+		blend := ""
+		if state.blend != data.BlendNormal {
+			blend = " /BM /" + pdfBlendMode(state.blend)
+		}
+		if err := doc.object("<< /Type /ExtGState /ca " + pdfNumbers(state.fill) + " /CA " + pdfNumbers(state.stroke) + blend + " >>"); err != nil {
+			return err
+		}
+	}
+
+	// Write cross-reference table and trailer
+	xref := doc.n
+	if err := doc.printf("xref\n0 %d\n0000000000 65535 f \n", len(doc.offsets)+1); err != nil {
+		return err
+	}
+	for _, offset := range doc.offsets {
+		if err := doc.printf("%010d 00000 n \n", offset); err != nil {
+			return err
+		}
+	}
+	if err := doc.printf("trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(doc.offsets)+1, xref); err != nil {
+		return err
+	}
+
+	// Return success
+	return nil
+}
+
+// object writes the next numbered object
+func (this *pdfobjects) object(value string) error {
+	this.offsets = append(this.offsets, this.n)
+	return this.printf("%d 0 obj\n%s\nendobj\n", len(this.offsets), value)
+}
+
+func (this *pdfobjects) printf(format string, args ...interface{}) error {
+	n, err := fmt.Fprintf(this.w, format, args...)
+	this.n += int64(n)
+	return err
+}
+
+// titleString returns the canvas title or an empty string
+func (this *Canvas) titleString() string {
+	for _, title := range this.Document.GetElementsByTagNameNS("title", data.XmlNamespaceSVG) {
+		for _, child := range title.Children() {
+			if cdata := strings.TrimSpace(child.Cdata()); cdata != "" {
+				return cdata
+			}
+		}
+	}
+	return ""
+}
+
+// writePath writes the path construction operators for a path
+func (this *pdfwriter) writePath(path []pathop) {
+	var start, pt data.Point
+	for _, seg := range path {
+		switch seg.op {
+		case 'M':
+			this.content.WriteString(pdfNumbers(seg.pts[0].X, seg.pts[0].Y) + " m\n")
+			start = seg.pts[0]
+		case 'L':
+			this.content.WriteString(pdfNumbers(seg.pts[0].X, seg.pts[0].Y) + " l\n")
+		case 'Q':
+			// Convert quadratic curves to cubic curves
+			c, p := seg.pts[0], seg.pts[1]
+			c1 := data.Point{pt.X + 2*(c.X-pt.X)/3, pt.Y + 2*(c.Y-pt.Y)/3}
+			c2 := data.Point{p.X + 2*(c.X-p.X)/3, p.Y + 2*(c.Y-p.Y)/3}
+			this.content.WriteString(pdfNumbers(c1.X, c1.Y, c2.X, c2.Y, p.X, p.Y) + " c\n")
+		case 'C':
+			this.content.WriteString(pdfNumbers(seg.pts[0].X, seg.pts[0].Y, seg.pts[1].X, seg.pts[1].Y, seg.pts[2].X, seg.pts[2].Y) + " c\n")
+		case 'Z':
+			this.content.WriteString("h\n")
+			pt = start
+			continue
+		}
+		pt = seg.pts[len(seg.pts)-1]
+	}
+}
+
+// pdfNumbers returns numbers separated by spaces
+func pdfNumbers(values ...float32) string {
+	str := make([]string, len(values))
+	for i, v := range values {
+		str[i] = strconv.FormatFloat(float64(v), 'f', 4, 32)
+		str[i] = strings.TrimRight(strings.TrimRight(str[i], "0"), ".")
+		if str[i] == "-0" || str[i] == "" {
+			str[i] = "0"
+		}
+	}
+	return strings.Join(str, " ")
+}
+
+// pdfColor returns RGB color components between 0 and 1
+func pdfColor(c data.Color) string {
+	return pdfNumbers(float32(c.R)/255, float32(c.G)/255, float32(c.B)/255)
+}
+
+func pdfLineCap(cap data.LineCap) int {
+	switch cap {
+	case data.CapRound:
+		return 1
+	case data.CapSquare:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// pdfBlendMode returns the name of a blend mode in PDF documents
+func pdfBlendMode(mode data.BlendMode) string {
+	name := ""
+	for _, word := range strings.Split(mode.String(), "-") {
+		name += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return name
+}
+
+func pdfLineJoin(join data.LineJoin) int {
+	switch join {
+	case data.JoinRound:
+		return 1
+	case data.JoinBevel:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// pdfText returns a string literal in WinAnsi encoding, or a hex string
+// in UTF-16 encoding when there are characters outside the Latin-1 range
+func pdfText(value string) string {
+	b := new(strings.Builder)
+	b.WriteString("(")
+	for _, r := range value {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteString("\\" + string(r))
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(b, "\\%03o", r)
+		default:
+			return pdfTextUTF16(value)
+		}
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// pdfGlyphs returns a string literal in WinAnsi encoding for drawing
+// text, replacing characters outside the Latin-1 range
+func pdfGlyphs(value string) string {
+	b := new(strings.Builder)
+	b.WriteString("(")
+	for _, r := range value {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteString("\\" + string(r))
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(b, "\\%03o", r)
+		default:
+			b.WriteString("?")
+		}
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func pdfTextUTF16(value string) string {
+	b := new(strings.Builder)
+	b.WriteString("<FEFF")
+	for _, v := range utf16.Encode([]rune(value)) {
+		fmt.Fprintf(b, "%04X", v)
+	}
+	b.WriteString(">")
+	return b.String()
+}
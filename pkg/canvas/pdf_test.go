@@ -0,0 +1,99 @@
+package canvas_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_PDF_001(t *testing.T) {
+	c := canvas.NewCanvas(data.A4PortraitSize, data.MM).Title("Hello, World")
+	c.Rect(data.ZeroPoint, data.Size{10, 10}).Style(c.Fill(color.Red, 1))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PDF, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.HasPrefix(str, "%PDF-1.4") == false {
+		t.Error("Unexpected header")
+	}
+	if strings.Contains(str, "/MediaBox [0 0 595.2756 841.8898]") == false {
+		t.Error("Unexpected media box for A4 paper size")
+	}
+	if strings.Contains(str, "/Title (Hello, World)") == false {
+		t.Error("Missing title")
+	}
+	if strings.Contains(str, "1 0 0 rg") == false || strings.Contains(str, "\nf\n") == false {
+		t.Error("Missing fill")
+	}
+	if strings.HasSuffix(str, "%%EOF\n") == false {
+		t.Error("Unexpected trailer")
+	}
+}
+
+func Test_PDF_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Group(
+		c.Text(data.Point{10, 20}, false, c.TextSpan("(Hello)")),
+	).Style(
+		c.FontFamily("Arial, sans-serif"),
+		c.FontVariant(data.Bold),
+		c.FontSize(12, data.PX),
+	).Transform(c.Rotate(90))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PDF, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Contains(str, "/BaseFont /Helvetica-Bold") == false {
+		t.Error("Missing font")
+	}
+	if strings.Contains(str, "/F1 12 Tf") == false || strings.Contains(str, `(\(Hello\)) Tj`) == false {
+		t.Error("Missing text")
+	}
+	if strings.Contains(str, "0 1 -1 0 0 0 cm") == false {
+		t.Error("Missing transform")
+	}
+}
+
+func Test_PDF_003(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Circle(data.Point{50, 50}, 10).Style(c.Stroke(color.Black, 0.5), c.NoFill())
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PDF|data.Minify, b); err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(b.String(), "/Filter /FlateDecode") {
+		t.Error("Unexpected compressed content stream")
+	}
+	b.Reset()
+	if err := c.Write(data.PDF|data.Compress, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Contains(str, "/Filter /FlateDecode") == false {
+		t.Error("Expected compressed content stream")
+	}
+	if strings.Contains(str, "/CA 0.5") == false {
+		t.Error("Missing stroke opacity")
+	}
+}
+
+func Test_PDF_004(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Line(data.ZeroPoint, data.Point{100, 0}).Style(c.Stroke(color.Black, 1), c.StrokeDash(3, 1.5), c.StrokeDashOffset(2))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PDF, b); err != nil {
+		t.Fatal(err)
+	}
+	if str := b.String(); strings.Contains(str, "[3 1.5] 2 d") == false {
+		t.Error("Missing dash pattern")
+	}
+}
@@ -0,0 +1,430 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// pngwriter renders canvas elements onto a bitmap
+type pngwriter struct {
+	canvas *Canvas
+	img    *image.RGBA
+	stack  []matrix
+	clips  []*image.Alpha // Clipping mask for each transform, or nil
+	layers []*image.RGBA  // Bitmaps beneath layers drawn for masks, opacity and blending
+	groups []pnggroup     // Opacity and blend mode of layers which are not masks
+	raster *rasterizer
+	depth  int // Depth of pattern tiles and masks
+}
+
+// pnggroup is the opacity and blend mode used to composite a layer
+type pnggroup struct {
+	opacity float32
+	blend   data.BlendMode
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Maximum distance in pixels between curves and flattened curves
+	rasterTolerance = 0.2
+)
+
+/////////////////////////////////////////////////////////////////////
+// WRITE CANVAS
+
+func (this *Canvas) writePNG(fmt data.Writer, w io.Writer) error {
+	// Determine the size of the bitmap
+	size, err := sizeFromAttr(this.Document, this.size)
+	if err != nil {
+		return err
+	} else if size.W <= 0 || size.H <= 0 {
+		return data.ErrBadParameter.WithPrefix("writePNG: Invalid size")
+	}
+
+	// Render onto the bitmap
+	img := image.NewRGBA(image.Rect(0, 0, int(f32.Ceil(size.W)), int(f32.Ceil(size.H))))
+	if err := this.render(newPNGWriter(this, img, viewBoxMatrix(this.origin, this.size, size))); err != nil {
+		return err
+	}
+
+	// Encode the bitmap
+	return png.Encode(w, img)
+}
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func newPNGWriter(canvas *Canvas, img *image.RGBA, m matrix) *pngwriter {
+	return &pngwriter{
+		canvas: canvas,
+		img:    img,
+		stack:  []matrix{m},
+		clips:  []*image.Alpha{nil},
+		raster: newRasterizer(img.Bounds()),
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// RENDERER METHODS
+
+func (this *pngwriter) push(m matrix) error {
+	this.stack = append(this.stack, this.ctm().multiply(m))
+	this.clips = append(this.clips, this.clips[len(this.clips)-1])
+	return nil
+}
+
+func (this *pngwriter) pop() error {
+	if len(this.stack) <= 1 {
+		return data.ErrInternalAppError.WithPrefix("pop")
+	}
+	this.stack = this.stack[:len(this.stack)-1]
+	this.clips = this.clips[:len(this.clips)-1]
+	return nil
+}
+
+func (this *pngwriter) path(path []pathop, style *renderstyle) error {
+	m := this.ctm()
+	scale := m.scale()
+	if scale == 0 {
+		return nil
+	}
+
+	// Flatten curves in user space, within the tolerance in pixels
+	tolerance := rasterTolerance / scale
+	paths := flatten(path, tolerance)
+
+	// Fill
+	if src := this.paint(style.fill, style.fillRef, style.fillOpacity, m, paths); src != nil {
+		this.raster.reset()
+		for _, path := range paths {
+			this.raster.addPolygon(transformPoints(m, path.pts))
+		}
+		this.composite(this.raster.mask(style.fillRule), src)
+	}
+
+	// Stroke
+	if style.strokeWidth <= 0 {
+		return nil
+	} else if src := this.paint(style.stroke, style.strokeRef, style.strokeOpacity, m, paths); src != nil {
+		stroker := newStroker(style.strokeWidth, style.lineCap, style.lineJoin, style.miterLimit, tolerance)
+		this.raster.reset()
+		for _, poly := range stroker.stroke(dash(paths, style.dashes, style.dashOffset)) {
+			this.raster.addPolygon(transformPoints(m, poly))
+		}
+		this.composite(this.raster.mask(data.NonZero), src)
+	}
+
+	// Return success
+	return nil
+}
+
+func (this *pngwriter) text(pt data.Point, value string, style *renderstyle) error {
+	// Text is not rendered onto bitmaps
+	return nil
+}
+
+func (this *pngwriter) image(pt data.Point, size data.Size, href string, aspect aspectratio) error {
+	// Only images embedded as data URIs are rendered onto bitmaps, and
+	// images which cannot be decoded are not drawn
+	if size.W <= 0 || size.H <= 0 || isDataURI(href) == false {
+		return nil
+	}
+	img, err := decodeImage(href)
+	if err != nil {
+		return nil
+	}
+	bounds := img.Bounds()
+	pixels := data.Size{float32(bounds.Dx()), float32(bounds.Dy())}
+	inverse, ok := this.ctm().multiply(aspect.matrix(pt, size, pixels)).invert()
+	if ok == false {
+		return nil
+	}
+
+	// Rasterize the area of the viewport covered by the image
+	origin, visible := aspect.visible(pt, size, pixels)
+	this.raster.reset()
+	this.raster.addPolygon(transformPoints(this.ctm(), rectPoints(origin, visible)))
+	mask := this.raster.mask(data.NonZero)
+	if mask == nil {
+		return nil
+	}
+
+	// Sample the nearest image pixel at the centre of each bitmap pixel
+	r := mask.Bounds()
+	src := image.NewRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if mask.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			p := inverse.apply(data.Point{float32(x) + 0.5, float32(y) + 0.5})
+			ix := bounds.Min.X + int(f32.Max(0, f32.Min(f32.Floor(p.X), pixels.W-1)))
+			iy := bounds.Min.Y + int(f32.Max(0, f32.Min(f32.Floor(p.Y), pixels.H-1)))
+			src.Set(x, y, img.At(ix, iy))
+		}
+	}
+	this.composite(mask, src)
+
+	// Return success
+	return nil
+}
+
+func (this *pngwriter) clip(shapes []clipshape) error {
+	m := this.ctm()
+	mask := image.NewAlpha(this.img.Bounds())
+
+	// Compute the union of the shapes
+	if scale := m.scale(); scale != 0 {
+		for _, shape := range shapes {
+			this.raster.reset()
+			for _, path := range flatten(shape.path, rasterTolerance/scale) {
+				this.raster.addPolygon(transformPoints(m, path.pts))
+			}
+			if coverage := this.raster.mask(shape.rule); coverage != nil {
+				r := coverage.Bounds()
+				for y := r.Min.Y; y < r.Max.Y; y++ {
+					for x := r.Min.X; x < r.Max.X; x++ {
+						if a := coverage.AlphaAt(x, y).A; a > mask.AlphaAt(x, y).A {
+							mask.SetAlpha(x, y, color.Alpha{a})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Intersect with the current clipping mask
+	if clip := this.clips[len(this.clips)-1]; clip != nil {
+		intersect(mask, clip)
+	}
+	this.clips[len(this.clips)-1] = mask
+
+	// Return success
+	return nil
+}
+
+func (this *pngwriter) beginMask() error {
+	this.layers = append(this.layers, this.img)
+	this.img = image.NewRGBA(this.img.Bounds())
+	return nil
+}
+
+func (this *pngwriter) endMask(mask *Element, m matrix) error {
+	if len(this.layers) == 0 {
+		return data.ErrInternalAppError.WithPrefix("endMask")
+	}
+	layer := this.img
+	this.img = this.layers[len(this.layers)-1]
+	this.layers = this.layers[:len(this.layers)-1]
+	if this.depth >= patternMaxDepth {
+		return nil
+	}
+
+	// Render the contents of the mask, with the style inherited from
+	// the mask element
+	img := image.NewRGBA(this.img.Bounds())
+	writer := newPNGWriter(this.canvas, img, this.ctm().multiply(m))
+	writer.depth = this.depth + 1
+	style := this.canvas.inheritedStyle(mask.Node)
+	for _, child := range mask.Children() {
+		if err := this.canvas.renderNode(writer, child, style); err != nil {
+			return err
+		}
+	}
+
+	// Composite the layer through the luminance of the mask
+	alpha := image.NewAlpha(img.Bounds())
+	for i := 0; i < len(alpha.Pix); i++ {
+		r, g, b := float32(img.Pix[i*4]), float32(img.Pix[i*4+1]), float32(img.Pix[i*4+2])
+		alpha.Pix[i] = uint8(0.2125*r + 0.7154*g + 0.0721*b + 0.5)
+	}
+	draw.DrawMask(this.img, this.img.Bounds(), layer, image.Point{}, alpha, image.Point{}, draw.Over)
+
+	// Return success
+	return nil
+}
+
+func (this *pngwriter) beginLayer(opacity float32, blend data.BlendMode) error {
+	this.layers = append(this.layers, this.img)
+	this.groups = append(this.groups, pnggroup{opacity, blend})
+	this.img = image.NewRGBA(this.img.Bounds())
+	return nil
+}
+
+func (this *pngwriter) endLayer() error {
+	if len(this.layers) == 0 || len(this.groups) == 0 {
+		return data.ErrInternalAppError.WithPrefix("endLayer")
+	}
+	layer, group := this.img, this.groups[len(this.groups)-1]
+	this.img = this.layers[len(this.layers)-1]
+	this.layers = this.layers[:len(this.layers)-1]
+	this.groups = this.groups[:len(this.groups)-1]
+
+	// Composite the layer with opacity and blending
+	blend(this.img, layer, group.opacity, group.blend)
+
+	// Return success
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// ctm returns the current transformation matrix
+func (this *pngwriter) ctm() matrix {
+	return this.stack[len(this.stack)-1]
+}
+
+// paint returns the source image for a fill or stroke, which is either
+// a gradient or pattern referenced by identifier or a color with opacity.
+// Returns nil if there is nothing to paint
+func (this *pngwriter) paint(c *data.Color, ref string, opacity float32, m matrix, paths []subpath) image.Image {
+	if opacity <= 0 {
+		return nil
+	}
+	if ref != "" {
+		if g := this.canvas.gradient(ref); g != nil {
+			origin, size := pathBounds(paths)
+			return g.paint(m, size, origin, opacity)
+		} else if node := this.canvas.Document.GetElementById(ref); node != nil {
+			if elem := (&Element{node, this.canvas}); elem.isElement("pattern") {
+				return this.pattern(elem, opacity, m, paths)
+			}
+		}
+	}
+	if c == nil {
+		return nil
+	}
+	return image.NewUniform(color.NRGBA{c.R, c.G, c.B, uint8(opacity*0xFF + 0.5)})
+}
+
+// composite a source image through a coverage mask
+func (this *pngwriter) composite(mask *image.Alpha, src image.Image) {
+	if mask == nil {
+		return
+	}
+	if clip := this.clips[len(this.clips)-1]; clip != nil {
+		intersect(mask, clip)
+	}
+	draw.DrawMask(this.img, mask.Bounds(), src, mask.Bounds().Min, mask, mask.Bounds().Min, draw.Over)
+}
+
+// blend composites a layer onto a bitmap of the same size with an
+// opacity, where the color of the layer is mixed with the color of
+// the bitmap beneath according to a blend mode
+func blend(dst, src *image.RGBA, opacity float32, mode data.BlendMode) {
+	if opacity <= 0 {
+		return
+	}
+	for i := 0; i < len(src.Pix) && i < len(dst.Pix); i += 4 {
+		as := float32(src.Pix[i+3]) / 0xFF * opacity
+		if as == 0 {
+			continue
+		}
+		ab := float32(dst.Pix[i+3]) / 0xFF
+		for j := 0; j < 3; j++ {
+			// Premultiplied source and backdrop colors
+			sc, bc := float32(src.Pix[i+j])/0xFF*opacity, float32(dst.Pix[i+j])/0xFF
+			c := sc + bc*(1-as)
+			if mode != data.BlendNormal && ab > 0 {
+				c = sc*(1-ab) + bc*(1-as) + as*ab*blendChannel(mode, f32.Min(bc/ab, 1), f32.Min(sc/as, 1))
+			}
+			dst.Pix[i+j] = uint8(f32.Max(0, f32.Min(c, 1))*0xFF + 0.5)
+		}
+		dst.Pix[i+3] = uint8(f32.Min(as+ab*(1-as), 1)*0xFF + 0.5)
+	}
+}
+
+// blendChannel returns the color for a blend mode from the color of
+// the backdrop and the source for one channel, between 0 and 1
+func blendChannel(mode data.BlendMode, cb, cs float32) float32 {
+	switch mode {
+	case data.BlendMultiply:
+		return cb * cs
+	case data.BlendScreen:
+		return cb + cs - cb*cs
+	case data.BlendOverlay:
+		return blendChannel(data.BlendHardLight, cs, cb)
+	case data.BlendDarken:
+		return f32.Min(cb, cs)
+	case data.BlendLighten:
+		return f32.Max(cb, cs)
+	case data.BlendColorDodge:
+		if cb == 0 {
+			return 0
+		} else if cs >= 1 {
+			return 1
+		}
+		return f32.Min(1, cb/(1-cs))
+	case data.BlendColorBurn:
+		if cb >= 1 {
+			return 1
+		} else if cs == 0 {
+			return 0
+		}
+		return 1 - f32.Min(1, (1-cb)/cs)
+	case data.BlendHardLight:
+		if cs <= 0.5 {
+			return cb * 2 * cs
+		}
+		return blendChannel(data.BlendScreen, cb, 2*cs-1)
+	case data.BlendSoftLight:
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		d := f32.Sqrt(cb)
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		}
+		return cb + (2*cs-1)*(d-cb)
+	case data.BlendDifference:
+		return f32.Abs(cb - cs)
+	case data.BlendExclusion:
+		return cb + cs - 2*cb*cs
+	default:
+		return cs
+	}
+}
+
+// intersect multiplies the coverage of a mask by a clipping mask
+func intersect(mask, clip *image.Alpha) {
+	r := mask.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			a := uint32(mask.AlphaAt(x, y).A) * uint32(clip.AlphaAt(x, y).A)
+			mask.SetAlpha(x, y, color.Alpha{uint8((a + 127) / 255)})
+		}
+	}
+}
+
+// transformPoints returns points transformed by a matrix
+func transformPoints(m matrix, pts []data.Point) []data.Point {
+	result := make([]data.Point, len(pts))
+	for i, pt := range pts {
+		result[i] = m.apply(pt)
+	}
+	return result
+}
+
+// viewBoxMatrix returns the transform from the viewBox onto a viewport
+// of a given size, preserving the aspect ratio and centering the viewBox
+func viewBoxMatrix(origin data.Point, viewBox, viewport data.Size) matrix {
+	if viewBox.W == 0 || viewBox.H == 0 {
+		return identity
+	}
+	scale := f32.Min(viewport.W/f32.Abs(viewBox.W), viewport.H/f32.Abs(viewBox.H))
+	tx := (viewport.W-f32.Abs(viewBox.W)*scale)/2 - origin.X*scale
+	ty := (viewport.H-f32.Abs(viewBox.H)*scale)/2 - origin.Y*scale
+	return matrix{scale, 0, 0, scale, tx, ty}
+}
@@ -0,0 +1,170 @@
+package canvas_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_PNG_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{16, 8}, data.PX)
+	c.Rect(data.ZeroPoint, data.Size{8, 8}).Style(c.Fill(color.Red, 1))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sz := img.Bounds().Size(); sz.X != 16 || sz.Y != 8 {
+		t.Error("Unexpected bitmap size: ", sz)
+	}
+	if r, g, b, a := img.At(4, 4).RGBA(); r != 0xFFFF || g != 0 || b != 0 || a != 0xFFFF {
+		t.Error("Unexpected color inside rect: ", img.At(4, 4))
+	}
+	if _, _, _, a := img.At(12, 4).RGBA(); a != 0 {
+		t.Error("Unexpected color outside rect: ", img.At(12, 4))
+	}
+}
+
+func Test_PNG_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{20, 20}, data.PX)
+	c.Group(
+		c.Path(
+			c.MoveTo(data.Point{0, 0}),
+			c.LineTo(data.Point{10, 0}),
+			c.LineTo(data.Point{10, 10}),
+			c.LineTo(data.Point{0, 10}),
+			c.ClosePath(),
+			c.MoveTo(data.Point{2, 2}),
+			c.LineTo(data.Point{8, 2}),
+			c.LineTo(data.Point{8, 8}),
+			c.LineTo(data.Point{2, 8}),
+			c.ClosePath(),
+		).Style(c.Fill(color.Blue, 1), c.FillRule(data.EvenOdd)),
+	).Transform(c.Translate(data.Point{5, 5}))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, b, a := img.At(6, 6).RGBA(); b != 0xFFFF || a != 0xFFFF {
+		t.Error("Unexpected color on path: ", img.At(6, 6))
+	}
+	if _, _, _, a := img.At(10, 10).RGBA(); a != 0 {
+		t.Error("Unexpected color within hole: ", img.At(10, 10))
+	}
+}
+
+func Test_PNG_003(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{20, 20}, data.PX)
+	c.Line(data.Point{0, 10}, data.Point{20, 10}).Style(
+		c.Stroke(color.Black, 1),
+		c.StrokeWidth(4),
+	)
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, a := img.At(10, 9).RGBA(); a != 0xFFFF {
+		t.Error("Unexpected color on stroke: ", img.At(10, 9))
+	}
+	if _, _, _, a := img.At(10, 5).RGBA(); a != 0 {
+		t.Error("Unexpected color outside stroke: ", img.At(10, 5))
+	}
+}
+
+func Test_PNG_004(t *testing.T) {
+	// Pie slice of a quarter circle with centre (10,10) and radius 10
+	c := canvas.NewCanvas(data.Size{20, 20}, data.PX)
+	c.Path(
+		c.MoveTo(data.Point{10, 10}),
+		c.HorizontalToRel(10),
+		c.ArcTo(data.Point{10, 20}, data.Size{10, 10}, 0, false, true),
+		c.ClosePath(),
+	).Style(c.Fill(color.Green, 1))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, a := img.At(16, 16).RGBA(); a != 0xFFFF {
+		t.Error("Expected color inside arc: ", img.At(16, 16))
+	}
+	for _, pt := range [][2]int{{19, 19}, {5, 15}, {15, 5}} {
+		if _, _, _, a := img.At(pt[0], pt[1]).RGBA(); a != 0 {
+			t.Error("Unexpected color outside arc: ", pt, img.At(pt[0], pt[1]))
+		}
+	}
+}
+
+func Test_PNG_005(t *testing.T) {
+	// Horizontal line with dashes of 4 and gaps of 2, starting 1 into the pattern
+	r := strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg" width="24" height="4"><line x1="0" y1="2" x2="24" y2="2" stroke="black" stroke-width="2" style="stroke-dasharray: 4, 2; stroke-dashoffset: 1"></line></svg>`)
+	c, err := canvas.Read(data.SVG, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 24; x++ {
+		_, _, _, a := img.At(x, 2).RGBA()
+		if (x+1)%6 < 4 && a != 0xFFFF {
+			t.Error("Expected dash at ", x, ": ", img.At(x, 2))
+		} else if (x+1)%6 >= 4 && a != 0 {
+			t.Error("Expected gap at ", x, ": ", img.At(x, 2))
+		}
+	}
+}
+
+func Test_PNG_006(t *testing.T) {
+	// Markers are drawn at the vertices of a path, clipped to the
+	// marker viewport
+	c := canvas.NewCanvas(data.Size{20, 20}, data.PX)
+	c.Defs(c.Marker(data.Point{2, 2}, data.Size{4, 4}, c.Rect(data.ZeroPoint, data.Size{10, 10}).Style(c.Fill(color.Red, 1))).Id("box"))
+	c.Line(data.Point{0, 10}, data.Point{10, 10}).Style(c.UseMarker(data.End, "url(#box)"))
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, a := img.At(10, 10).RGBA(); r != 0xFFFF || a != 0xFFFF {
+		t.Error("Expected marker at 10,10: ", img.At(10, 10))
+	}
+	for _, pt := range []data.Point{{2, 10}, {14, 14}, {17, 9}} {
+		if _, _, _, a := img.At(int(pt.X), int(pt.Y)).RGBA(); a != 0 {
+			t.Error("Unexpected color at ", pt, ": ", img.At(int(pt.X), int(pt.Y)))
+		}
+	}
+}
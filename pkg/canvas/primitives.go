@@ -102,7 +102,7 @@ func (this *Canvas) Rect(pt data.Point, sz data.Size) data.CanvasElement {
 	return elem
 }
 
-func (this *Canvas) Image(pt data.Point, sz data.Size, u string) data.CanvasElement {
+func (this *Canvas) Image(pt data.Point, sz data.Size, u string) data.CanvasImage {
 	if url, err := url.Parse(u); err != nil {
 		return nil
 	} else if elem, err := this.NewElement("image"); err != nil {
@@ -117,6 +117,22 @@ func (this *Canvas) Image(pt data.Point, sz data.Size, u string) data.CanvasElem
 	}
 }
 
+func (this *Canvas) Use(id string, pt data.Point) data.CanvasElement {
+	if id = strings.TrimPrefix(strings.TrimSpace(id), "#"); id == "" {
+		return nil
+	} else if elem, err := this.NewElement("use"); err != nil {
+		return nil
+	} else if err := elem.SetAttrNS("href", data.XmlNamespaceXLink, "#"+id); err != nil {
+		return nil
+	} else {
+		if pt != data.ZeroPoint {
+			elem.SetAttr("x", f32.String(pt.X))
+			elem.SetAttr("y", f32.String(pt.Y))
+		}
+		return elem
+	}
+}
+
 func (this *Canvas) Path(paths ...data.CanvasPath) data.CanvasElement {
 	// Get path elements into a string array
 	d := make([]string, 0, len(paths))
@@ -0,0 +1,187 @@
+package canvas
+
+import (
+	"image"
+	"sort"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// rasterizer is a scanline polygon rasterizer which renders the
+// coverage of polygons into an alpha mask, anti-aliased using a number
+// of sub-scanlines per pixel and exact horizontal coverage
+type rasterizer struct {
+	bounds image.Rectangle
+	edges  []edge
+}
+
+type edge struct {
+	x0, y0, x1, y1 float32
+	dir            int
+}
+
+type crossing struct {
+	x   float32
+	dir int
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	rasterSubsamples = 4
+)
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func newRasterizer(bounds image.Rectangle) *rasterizer {
+	return &rasterizer{bounds: bounds}
+}
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// reset removes all polygons
+func (this *rasterizer) reset() {
+	this.edges = this.edges[:0]
+}
+
+// addPolygon adds a closed polygon
+func (this *rasterizer) addPolygon(pts []data.Point) {
+	for i := range pts {
+		p0, p1 := pts[i], pts[(i+1)%len(pts)]
+		if p0.Y == p1.Y || f32.IsNaN(p0.X+p0.Y+p1.X+p1.Y) {
+			continue
+		}
+		if p0.Y < p1.Y {
+			this.edges = append(this.edges, edge{p0.X, p0.Y, p1.X, p1.Y, 1})
+		} else {
+			this.edges = append(this.edges, edge{p1.X, p1.Y, p0.X, p0.Y, -1})
+		}
+	}
+}
+
+// mask returns the coverage of the polygons, or nil if nothing is covered
+func (this *rasterizer) mask(rule data.FillRule) *image.Alpha {
+	if len(this.edges) == 0 {
+		return nil
+	}
+
+	// Determine the bounds of the edges
+	minx, miny, maxx, maxy := this.edges[0].x0, this.edges[0].y0, this.edges[0].x0, this.edges[0].y1
+	for _, e := range this.edges {
+		minx = f32.Min(minx, e.x0, e.x1)
+		maxx = f32.Max(maxx, e.x0, e.x1)
+		miny = f32.Min(miny, e.y0)
+		maxy = f32.Max(maxy, e.y1)
+	}
+	r := image.Rect(int(f32.Floor(minx)), int(f32.Floor(miny)), int(f32.Ceil(maxx))+1, int(f32.Ceil(maxy))+1).Intersect(this.bounds)
+	if r.Empty() {
+		return nil
+	}
+
+	// Sort edges by top co-ordinate
+	sort.Slice(this.edges, func(i, j int) bool {
+		return this.edges[i].y0 < this.edges[j].y0
+	})
+
+	mask := image.NewAlpha(r)
+	acc := make([]float32, r.Dx()+1)
+	active := make([]*edge, 0, len(this.edges))
+	crossings := make([]crossing, 0, len(this.edges))
+	next := 0
+	weight := float32(1) / rasterSubsamples
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for i := range acc {
+			acc[i] = 0
+		}
+		for s := 0; s < rasterSubsamples; s++ {
+			sy := float32(y) + (float32(s)+0.5)/rasterSubsamples
+
+			// Add new edges and remove finished edges
+			for next < len(this.edges) && this.edges[next].y0 <= sy {
+				active = append(active, &this.edges[next])
+				next++
+			}
+			j := 0
+			for _, e := range active {
+				if e.y1 > sy {
+					active[j] = e
+					j++
+				}
+			}
+			active = active[:j]
+
+			// Determine crossings
+			crossings = crossings[:0]
+			for _, e := range active {
+				if e.y0 <= sy {
+					x := e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+					crossings = append(crossings, crossing{x, e.dir})
+				}
+			}
+			sort.Slice(crossings, func(i, j int) bool {
+				return crossings[i].x < crossings[j].x
+			})
+
+			// Accumulate spans which are inside the polygon
+			winding := 0
+			for i, c := range crossings {
+				winding += c.dir
+				if i+1 < len(crossings) && inside(winding, rule) {
+					this.span(acc, r, c.x, crossings[i+1].x, weight)
+				}
+			}
+		}
+
+		// Set coverage
+		row := mask.Pix[(y-r.Min.Y)*mask.Stride:]
+		for x := 0; x < r.Dx(); x++ {
+			if a := acc[x]; a >= 1 {
+				row[x] = 0xFF
+			} else if a > 0 {
+				row[x] = uint8(a*0xFF + 0.5)
+			}
+		}
+	}
+
+	// Return the coverage
+	return mask
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// span adds coverage between x0 and x1 on a sub-scanline
+func (this *rasterizer) span(acc []float32, r image.Rectangle, x0, x1, weight float32) {
+	x0 = f32.Max(x0, float32(r.Min.X)) - float32(r.Min.X)
+	x1 = f32.Min(x1, float32(r.Max.X)) - float32(r.Min.X)
+	if x1 <= x0 {
+		return
+	}
+	i0, i1 := int(x0), int(x1)
+	if i0 == i1 {
+		acc[i0] += (x1 - x0) * weight
+		return
+	}
+	acc[i0] += (float32(i0+1) - x0) * weight
+	for i := i0 + 1; i < i1; i++ {
+		acc[i] += weight
+	}
+	acc[i1] += (x1 - float32(i1)) * weight
+}
+
+// inside returns true if the winding number is inside the polygon
+// for a fill rule
+func inside(winding int, rule data.FillRule) bool {
+	if rule == data.EvenOdd {
+		return winding%2 != 0
+	} else {
+		return winding != 0
+	}
+}
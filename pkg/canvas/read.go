@@ -2,12 +2,15 @@ package canvas
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/djthorpe/data"
 	"github.com/djthorpe/data/pkg/dom"
+	"github.com/djthorpe/data/pkg/stylesheet"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -15,11 +18,47 @@ import (
 
 var (
 	tags = map[xml.Name]data.DOMValidateNodeFunc{
-		{data.XmlNamespaceSVG, "svg"}:   tagSVG,
-		{data.XmlNamespaceSVG, "title"}: tagTitle,
-		{data.XmlNamespaceSVG, "path"}:  tagPath,
-		{data.XmlNamespaceSVG, "g"}:     tagGroup,
-		{data.XmlNamespaceSVG, "desc"}:  tagDesc,
+		{data.XmlNamespaceSVG, "svg"}:              tagSVG,
+		{data.XmlNamespaceSVG, "title"}:            tagNone,
+		{data.XmlNamespaceSVG, "desc"}:             tagNone,
+		{data.XmlNamespaceSVG, "metadata"}:         tagNone,
+		{data.XmlNamespaceSVG, "a"}:                tagLink,
+		{data.XmlNamespaceSVG, "style"}:            tagStyle,
+		{data.XmlNamespaceSVG, "g"}:                tagNone,
+		{data.XmlNamespaceSVG, "defs"}:             tagNone,
+		{data.XmlNamespaceSVG, "symbol"}:           tagSymbol,
+		{data.XmlNamespaceSVG, "marker"}:           tagMarker,
+		{data.XmlNamespaceSVG, "pattern"}:          tagPattern,
+		{data.XmlNamespaceSVG, "clipPath"}:         tagNone,
+		{data.XmlNamespaceSVG, "mask"}:             tagMask,
+		{data.XmlNamespaceSVG, "use"}:              tagUse,
+		{data.XmlNamespaceSVG, "path"}:             tagPath,
+		{data.XmlNamespaceSVG, "rect"}:             tagRect,
+		{data.XmlNamespaceSVG, "circle"}:           tagCircle,
+		{data.XmlNamespaceSVG, "ellipse"}:          tagEllipse,
+		{data.XmlNamespaceSVG, "line"}:             tagLine,
+		{data.XmlNamespaceSVG, "polyline"}:         tagPoly,
+		{data.XmlNamespaceSVG, "polygon"}:          tagPoly,
+		{data.XmlNamespaceSVG, "text"}:             tagText,
+		{data.XmlNamespaceSVG, "tspan"}:            tagTextSpan,
+		{data.XmlNamespaceSVG, "textPath"}:         tagTextPath,
+		{data.XmlNamespaceSVG, "image"}:            tagImage,
+		{data.XmlNamespaceSVG, "linearGradient"}:   tagLinearGradient,
+		{data.XmlNamespaceSVG, "radialGradient"}:   tagRadialGradient,
+		{data.XmlNamespaceSVG, "stop"}:             tagStop,
+		{data.XmlNamespaceSVG, "filter"}:           tagNone,
+		{data.XmlNamespaceSVG, "feGaussianBlur"}:   tagFilterPrimitive,
+		{data.XmlNamespaceSVG, "feOffset"}:         tagFilterPrimitive,
+		{data.XmlNamespaceSVG, "feColorMatrix"}:    tagFilterPrimitive,
+		{data.XmlNamespaceSVG, "feFlood"}:          tagFilterPrimitive,
+		{data.XmlNamespaceSVG, "feComposite"}:      tagFilterPrimitive,
+		{data.XmlNamespaceSVG, "feMerge"}:          tagFilterPrimitive,
+		{data.XmlNamespaceSVG, "feMergeNode"}:      tagMergeNode,
+		{data.XmlNamespaceSVG, "animate"}:          tagAnimate,
+		{data.XmlNamespaceSVG, "animateTransform"}: tagAnimate,
+		{data.XmlNamespaceSVG, "animateMotion"}:    tagAnimate,
+		{data.XmlNamespaceSVG, "set"}:              tagAnimate,
+		{data.XmlNamespaceSVG, "mpath"}:            tagNone,
 	}
 )
 
@@ -28,6 +67,7 @@ var (
 
 func Read(fmt data.Writer, r io.Reader) (data.Canvas, error) {
 	this := new(Canvas)
+	this.precision = defaultPrecision
 
 	switch fmt {
 	case data.SVG:
@@ -48,52 +88,393 @@ func Read(fmt data.Writer, r io.Reader) (data.Canvas, error) {
 func (this *Canvas) readSVG(r io.Reader) error {
 	if document, err := dom.ReadEx(r, this.validateSVG); err != nil {
 		return err
+	} else if document.Name() != (xml.Name{data.XmlNamespaceSVG, "svg"}) {
+		return data.ErrBadParameter.WithPrefix("Not an SVG document: ", strconv.Quote(document.Name().Local))
 	} else {
 		this.Document = document
+		this.Element = &Element{document, this}
 	}
 
-	// Set viewbox
+	// Set viewbox, or use the width and height when there is no viewbox
 	if origin, size, err := viewBoxFromAttr(this.Document); err != nil {
 		return err
 	} else if size != data.ZeroSize {
 		this.origin = origin
 		this.size = size
+	} else if size, err := sizeFromAttr(this.Document, data.ZeroSize); err != nil {
+		return err
+	} else {
+		this.size = size
 	}
 
 	// Success
 	return nil
 }
 
+// validateSVG checks each element once it has been read. Elements in
+// other namespaces (for example, editor metadata) are retained without
+// validation
 func (this *Canvas) validateSVG(node data.Node) error {
 	name := node.Name()
-	if fn, exists := tags[name]; exists {
-		return fn(node)
-	} else {
+	if name.Space != data.XmlNamespaceSVG {
+		return nil
+	}
+	if fn, exists := tags[name]; exists == false {
 		return data.ErrBadParameter.WithPrefix("Unsupported tag: ", strconv.Quote(name.Local))
+	} else if err := fn(node); err != nil {
+		return err
+	}
+
+	// Check opacity, blending, visibility and display on any element
+	if err := checkProperties(node); err != nil {
+		return data.ErrBadParameter.WithPrefix("<", name.Local, "> ", err)
 	}
+
+	// Check transforms on any element
+	for _, attr := range []string{"transform", "gradientTransform", "patternTransform"} {
+		if attr, exists := node.Attr(attr); exists {
+			if _, err := parseTransform(attr.Value); err != nil {
+				return data.ErrBadParameter.WithPrefix("<", name.Local, "> ", err)
+			}
+		}
+	}
+
+	// Return success
+	return nil
 }
 
-func tagSVG(node data.Node) error {
-	fmt.Println("VALIDATE SVG", node)
+func tagNone(node data.Node) error {
 	return nil
 }
 
-func tagTitle(node data.Node) error {
-	fmt.Println("VALIDATE TITLE", node)
+func tagSVG(node data.Node) error {
+	if _, _, err := viewBoxFromAttr(node); err != nil {
+		return data.ErrBadParameter.WithPrefix("<svg> ", err)
+	}
+	return checkLengths(node, "width", "height")
+}
+
+func tagStyle(node data.Node) error {
+	if isStyleSheet(node) {
+		if err := stylesheet.NewStyleSheet().Read(strings.NewReader(textContent(node))); err != nil {
+			return badParameter("<style> ", err)
+		}
+	}
 	return nil
 }
 
+func tagSymbol(node data.Node) error {
+	if _, _, err := viewBoxFromAttr(node); err != nil {
+		return data.ErrBadParameter.WithPrefix("<symbol> ", err)
+	}
+	return checkLengths(node, "x", "y", "width", "height")
+}
+
+func tagMarker(node data.Node) error {
+	if _, _, err := viewBoxFromAttr(node); err != nil {
+		return data.ErrBadParameter.WithPrefix("<marker> ", err)
+	}
+	return checkLengths(node, "refX", "refY", "markerWidth", "markerHeight")
+}
+
+func tagPattern(node data.Node) error {
+	if _, _, err := viewBoxFromAttr(node); err != nil {
+		return data.ErrBadParameter.WithPrefix("<pattern> ", err)
+	}
+	return checkLengths(node, "x", "y", "width", "height")
+}
+
+func tagMask(node data.Node) error {
+	return checkLengths(node, "x", "y", "width", "height")
+}
+
+func tagUse(node data.Node) error {
+	if _, exists := attrHref(node); exists == false {
+		return data.ErrBadParameter.WithPrefix("<use> Missing href")
+	}
+	return checkLengths(node, "x", "y", "width", "height")
+}
+
 func tagPath(node data.Node) error {
-	fmt.Println("VALIDATE PATH", node)
+	if attr, exists := node.Attr("d"); exists {
+		if _, err := parsePathData(attr.Value); err != nil {
+			return data.ErrBadParameter.WithPrefix("<path> ", err)
+		}
+	}
+	return nil
+}
+
+func tagRect(node data.Node) error {
+	return checkLengths(node, "x", "y", "width", "height", "rx", "ry")
+}
+
+func tagCircle(node data.Node) error {
+	return checkLengths(node, "cx", "cy", "r")
+}
+
+func tagEllipse(node data.Node) error {
+	return checkLengths(node, "cx", "cy", "rx", "ry")
+}
+
+func tagLine(node data.Node) error {
+	return checkLengths(node, "x1", "y1", "x2", "y2")
+}
+
+func tagPoly(node data.Node) error {
+	if attr, exists := node.Attr("points"); exists {
+		if _, err := parsePoints(attr.Value); err != nil {
+			return data.ErrBadParameter.WithPrefix("<", node.Name().Local, "> ", err)
+		}
+	}
+	return nil
+}
+
+func tagText(node data.Node) error {
+	for _, name := range []string{"x", "y", "dx", "dy"} {
+		if attr, exists := node.Attr(name); exists {
+			for _, value := range strings.FieldsFunc(attr.Value, isListSeparator) {
+				if _, err := parseLengthOrPercent(value); err != nil {
+					return data.ErrBadParameter.WithPrefix("<", node.Name().Local, "> ", name, ": ", err)
+				}
+			}
+		}
+	}
+	return checkLengths(node, "textLength")
+}
+
+func tagTextSpan(node data.Node) error {
+	if parent := node.Parent(); parent == nil || parent.Name().Space != data.XmlNamespaceSVG {
+		return data.ErrBadParameter.WithPrefix("<tspan> Outside of text")
+	} else if parent.Name().Local != "text" && parent.Name().Local != "tspan" && parent.Name().Local != "textPath" && parent.Name().Local != "a" {
+		return data.ErrBadParameter.WithPrefix("<tspan> Outside of text")
+	}
+	return tagText(node)
+}
+
+func tagTextPath(node data.Node) error {
+	if parent := node.Parent(); parent == nil || parent.Name() != (xml.Name{data.XmlNamespaceSVG, "text"}) {
+		return data.ErrBadParameter.WithPrefix("<textPath> Outside of text")
+	} else if _, exists := attrHref(node); exists == false {
+		if _, exists := node.Attr("path"); exists == false {
+			return data.ErrBadParameter.WithPrefix("<textPath> Missing href")
+		}
+	}
+	if attr, exists := node.Attr("path"); exists {
+		if _, err := parsePathData(attr.Value); err != nil {
+			return data.ErrBadParameter.WithPrefix("<textPath> ", err)
+		}
+	}
+	for name, values := range map[string][]string{"method": {"align", "stretch"}, "spacing": {"auto", "exact"}, "side": {"left", "right"}} {
+		if attr, exists := node.Attr(name); exists && stringInList(strings.TrimSpace(attr.Value), values) == false {
+			return data.ErrBadParameter.WithPrefix("<textPath> Invalid ", name, ": ", strconv.Quote(attr.Value))
+		}
+	}
+	return checkLengths(node, "startOffset", "textLength")
+}
+
+func tagImage(node data.Node) error {
+	if attr, exists := node.Attr("preserveAspectRatio"); exists {
+		if _, err := parseAspectRatio(attr.Value); err != nil {
+			return badParameter("<image> ", err)
+		}
+	}
+
+	// Check embedded PNG and JPEG images can be decoded
+	if attr, exists := attrHref(node); exists && isDataURI(attr.Value) {
+		if mediatype, _, err := parseDataURI(attr.Value); err != nil {
+			return badParameter("<image> ", err)
+		} else if mediatype == data.ImagePNG.String() || mediatype == data.ImageJPEG.String() {
+			if _, err := decodeImage(attr.Value); err != nil {
+				return badParameter("<image> ", err)
+			}
+		}
+	}
+	return checkLengths(node, "x", "y", "width", "height")
+}
+
+func tagLinearGradient(node data.Node) error {
+	return checkLengths(node, "x1", "y1", "x2", "y2")
+}
+
+func tagRadialGradient(node data.Node) error {
+	return checkLengths(node, "cx", "cy", "r", "fx", "fy", "fr")
+}
+
+func tagStop(node data.Node) error {
+	if parent := node.Parent(); parent == nil || parent.Name().Space != data.XmlNamespaceSVG {
+		return data.ErrBadParameter.WithPrefix("<stop> Outside of gradient")
+	} else if parent.Name().Local != "linearGradient" && parent.Name().Local != "radialGradient" {
+		return data.ErrBadParameter.WithPrefix("<stop> Outside of gradient")
+	}
+	if attr, exists := node.Attr("offset"); exists {
+		if _, err := parseOpacity(strings.TrimSpace(attr.Value)); err != nil {
+			return data.ErrBadParameter.WithPrefix("<stop> Invalid offset: ", strconv.Quote(attr.Value))
+		}
+	}
 	return nil
 }
 
-func tagGroup(node data.Node) error {
-	fmt.Println("VALIDATE GROUP", node)
+func tagFilterPrimitive(node data.Node) error {
+	if parent := node.Parent(); parent == nil || parent.Name() != (xml.Name{data.XmlNamespaceSVG, "filter"}) {
+		return data.ErrBadParameter.WithPrefix("<", node.Name().Local, "> Outside of filter")
+	}
+	return checkLengths(node, "x", "y", "width", "height")
+}
+
+func tagLink(node data.Node) error {
+	if attr, exists := attrHref(node); exists && strings.TrimSpace(attr.Value) == "" {
+		return data.ErrBadParameter.WithPrefix("<a> Empty href")
+	}
 	return nil
 }
 
-func tagDesc(node data.Node) error {
-	fmt.Println("VALIDATE DESC", node)
+func tagMergeNode(node data.Node) error {
+	if parent := node.Parent(); parent == nil || parent.Name() != (xml.Name{data.XmlNamespaceSVG, "feMerge"}) {
+		return data.ErrBadParameter.WithPrefix("<", node.Name().Local, "> Outside of feMerge")
+	}
 	return nil
 }
+
+func tagAnimate(node data.Node) error {
+	name := node.Name().Local
+	for _, attr := range []string{"dur", "repeatDur"} {
+		if attr, exists := node.Attr(attr); exists {
+			if value := strings.TrimSpace(attr.Value); value != "indefinite" && value != "media" {
+				if d, err := parseClock(value); err != nil || d < 0 {
+					return data.ErrBadParameter.WithPrefix("<", name, "> Invalid ", attr.Name.Local, ": ", strconv.Quote(attr.Value))
+				}
+			}
+		}
+	}
+	if attr, exists := node.Attr("repeatCount"); exists {
+		if value := strings.TrimSpace(attr.Value); value != "indefinite" {
+			if v, err := strconv.ParseFloat(value, 32); err != nil || v <= 0 {
+				return data.ErrBadParameter.WithPrefix("<", name, "> Invalid repeatCount: ", strconv.Quote(attr.Value))
+			}
+		}
+	}
+	if attr, exists := node.Attr("calcMode"); exists && stringInList(strings.TrimSpace(attr.Value), []string{"discrete", "linear", "paced", "spline"}) == false {
+		return data.ErrBadParameter.WithPrefix("<", name, "> Invalid calcMode: ", strconv.Quote(attr.Value))
+	}
+	if attr, exists := node.Attr("fill"); exists && stringInList(strings.TrimSpace(attr.Value), []string{"freeze", "remove"}) == false {
+		return data.ErrBadParameter.WithPrefix("<", name, "> Invalid fill: ", strconv.Quote(attr.Value))
+	}
+	for _, attr := range []string{"keySplines", "keyTimes", "keyPoints"} {
+		if attr, exists := node.Attr(attr); exists {
+			values := strings.FieldsFunc(attr.Value, func(r rune) bool { return r == ';' || isListSeparator(r) })
+			if attr.Name.Local == "keySplines" && len(values)%4 != 0 {
+				return data.ErrBadParameter.WithPrefix("<", name, "> Invalid keySplines: ", strconv.Quote(attr.Value))
+			}
+			for _, value := range values {
+				if _, err := parseOpacity(value); err != nil {
+					return data.ErrBadParameter.WithPrefix("<", name, "> Invalid ", attr.Name.Local, ": ", strconv.Quote(attr.Value))
+				}
+			}
+		}
+	}
+	if attr, exists := node.Attr("type"); exists && name == "animateTransform" {
+		if _, exists := animateTransformTypes[strings.TrimSpace(attr.Value)]; exists == false {
+			return data.ErrBadParameter.WithPrefix("<", name, "> Invalid type: ", strconv.Quote(attr.Value))
+		}
+	}
+	if attr, exists := node.Attr("path"); exists && name == "animateMotion" {
+		if _, err := parsePathData(attr.Value); err != nil {
+			return data.ErrBadParameter.WithPrefix("<", name, "> ", err)
+		}
+	}
+	return nil
+}
+
+// checkProperties returns an error if the opacity, blend mode,
+// visibility or display of an element is invalid, either as a
+// presentation attribute or within the style attribute
+func checkProperties(node data.Node) error {
+	decls := [][2]string{}
+	for _, name := range []string{"opacity", "visibility", "display"} {
+		if attr, exists := node.Attr(name); exists {
+			decls = append(decls, [2]string{name, attr.Value})
+		}
+	}
+	if attr, exists := node.Attr("style"); exists {
+		decls = append(decls, parseStyleAttr(attr.Value)...)
+	}
+	for _, decl := range decls {
+		value, _ := importantValue(strings.TrimSpace(decl[1]))
+		if value == "inherit" || value == "initial" {
+			continue
+		}
+		valid := true
+		switch decl[0] {
+		case "opacity":
+			_, err := parseOpacity(value)
+			valid = err == nil
+		case "mix-blend-mode":
+			_, valid = blendModes[value]
+		case "visibility":
+			valid = stringInList(value, []string{"visible", "hidden", "collapse"})
+		case "display":
+			valid = stringInList(value, displayValues)
+		}
+		if valid == false {
+			return data.ErrBadParameter.WithPrefix("Invalid ", decl[0], ": ", strconv.Quote(decl[1]))
+		}
+	}
+	return nil
+}
+
+// badParameter returns ErrBadParameter with a prefix and the cause of
+// an error, or adds the prefix to an error which is ErrBadParameter
+func badParameter(prefix string, err error) error {
+	if errors.Is(err, data.ErrBadParameter) {
+		return fmt.Errorf("%s%w", prefix, err)
+	}
+	return data.ErrBadParameter.WithPrefix(prefix, err)
+}
+
+// checkLengths returns an error if any of the named attributes exist
+// and are not lengths or percentages
+func checkLengths(node data.Node, names ...string) error {
+	for _, name := range names {
+		if attr, exists := node.Attr(name); exists {
+			if _, err := parseLengthOrPercent(attr.Value); err != nil {
+				return data.ErrBadParameter.WithPrefix("<", node.Name().Local, "> ", name, ": ", err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseLengthOrPercent returns a length in pixels, or a percentage
+// as a number
+func parseLengthOrPercent(value string) (float32, error) {
+	if value = strings.TrimSpace(value); strings.HasSuffix(value, "%") {
+		return parseUnitValue(strings.TrimSuffix(value, "%"))
+	} else {
+		return parseUnitValue(value)
+	}
+}
+
+// attrHref returns the href attribute, or the xlink:href attribute
+// used in SVG 1.1 documents
+func attrHref(node data.Node) (xml.Attr, bool) {
+	if attr, exists := node.Attr("href"); exists {
+		return attr, true
+	} else {
+		return node.AttrNS("href", data.XmlNamespaceXLink)
+	}
+}
+
+// stringInList returns true if a value is in a list of values
+func stringInList(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isListSeparator returns true for whitespace and comma
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
@@ -1,13 +1,18 @@
 package canvas_test
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 
 	data "github.com/djthorpe/data"
 	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
 )
 
 const (
@@ -121,3 +126,123 @@ func Test_Read_007(t *testing.T) {
 		t.Log(c1)
 	}
 }
+
+func Test_Read_008(t *testing.T) {
+	for _, path := range []string{SVGFILE_D, SVGFILE_E, SVGFILE_F} {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Read and write the canvas, which should have the same elements,
+		// attributes and text as the original file
+		c1, err := canvas.Read(data.SVG, bytes.NewReader(src))
+		if err != nil {
+			t.Fatal(path, ": ", err)
+		}
+		b1 := new(strings.Builder)
+		if err := c1.Write(data.SVG, b1); err != nil {
+			t.Fatal(err)
+		}
+		if n1, n2 := normaliseXML(t, string(src)), normaliseXML(t, b1.String()); len(n1) != len(n2) {
+			t.Errorf("%v: Round trip is not lossless, %v nodes read and %v nodes written", path, len(n1), len(n2))
+		} else {
+			for i := range n1 {
+				if n1[i] != n2[i] {
+					t.Errorf("%v: Round trip is not lossless, read %q and wrote %q", path, n1[i], n2[i])
+					break
+				}
+			}
+		}
+
+		// Read the canvas again, which should be identical
+		c2, err := canvas.Read(data.SVG, strings.NewReader(b1.String()))
+		if err != nil {
+			t.Fatal(path, ": ", err)
+		}
+		b2 := new(strings.Builder)
+		if err := c2.Write(data.SVG, b2); err != nil {
+			t.Fatal(err)
+		}
+		if b1.String() != b2.String() {
+			t.Error(path, ": Second round trip is not identical")
+		}
+		if c1.Size() != c2.Size() || c1.Origin() != c2.Origin() {
+			t.Error(path, ": Unexpected viewBox ", c2.Size())
+		}
+	}
+}
+
+// normaliseXML returns the elements of an XML document with their
+// namespaces and sorted attributes, and the text between them with
+// whitespace trimmed, ignoring namespace declarations
+func normaliseXML(t *testing.T, doc string) []string {
+	t.Helper()
+	result := []string{}
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return result
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			attrs := []string{}
+			for _, attr := range token.Attr {
+				if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+					attrs = append(attrs, fmt.Sprintf("%v:%v=%q", attr.Name.Space, attr.Name.Local, attr.Value))
+				}
+			}
+			sort.Strings(attrs)
+			result = append(result, fmt.Sprintf("<%v:%v %v>", token.Name.Space, token.Name.Local, strings.Join(attrs, " ")))
+		case xml.EndElement:
+			result = append(result, fmt.Sprintf("</%v:%v>", token.Name.Space, token.Name.Local))
+		case xml.CharData:
+			if text := strings.TrimSpace(string(token)); text != "" {
+				result = append(result, text)
+			}
+		}
+	}
+}
+
+func Test_Read_009(t *testing.T) {
+	c1 := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c1.Defs(c1.Marker(data.Point{5, 5}, data.Size{10, 10}, c1.Circle(data.Point{5, 5}, 5)).Id("dot"))
+	c1.Group(
+		c1.Rect(data.Point{10, 10}, data.Size{20, 20}).Style(c1.Fill(color.Red, 0.5)),
+		c1.Ellipse(data.Point{50, 50}, data.Size{10, 20}),
+		c1.Line(data.Point{0, 0}, data.Point{100, 100}).Style(c1.UseMarker(data.End, "dot")),
+		c1.Polyline(data.Point{0, 0}, data.Point{10, 10}, data.Point{20, 0}),
+		c1.Polygon(data.Point{0, 0}, data.Point{10, 10}, data.Point{20, 0}),
+		c1.Text(data.Point{10, 90}, false, c1.TextSpan("Hello"), c1.TextSpan("World").Offset(data.Point{5, 0})),
+		c1.Image(data.Point{0, 0}, data.Size{10, 10}, "image.png"),
+	).Style(c1.StrokeWidth(2)).Transform(c1.Rotate(45), c1.SkewX(10))
+
+	b := new(strings.Builder)
+	if err := c1.Write(data.SVG, b); err != nil {
+		t.Fatal(err)
+	}
+	if c2, err := canvas.Read(data.SVG, strings.NewReader(b.String())); err != nil {
+		t.Fatal(err)
+	} else if fmt.Sprint(c1) != fmt.Sprint(c2) {
+		t.Error("Unexpected return: ", c2)
+	}
+}
+
+func Test_Read_010(t *testing.T) {
+	for _, svg := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"><unknown/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><circle r="x"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><g transform="rotate(x)"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><polygon points="0 0 a"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><stop offset="0"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><use/></svg>`,
+		`<html xmlns="http://www.w3.org/1999/xhtml"></html>`,
+	} {
+		if _, err := canvas.Read(data.SVG, strings.NewReader(svg)); err == nil {
+			t.Error("Expected error for ", svg)
+		}
+	}
+}
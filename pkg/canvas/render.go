@@ -0,0 +1,970 @@
+package canvas
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/color"
+	"github.com/djthorpe/data/pkg/f32"
+	"github.com/djthorpe/data/pkg/geom"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// renderer is implemented by the output formats which draw the
+// canvas elements rather than writing the document
+type renderer interface {
+	// Push a transform for an element and pop it when the element
+	// and any children have been drawn
+	push(matrix) error
+	pop() error
+
+	// Draw a path with computed style
+	path([]pathop, *renderstyle) error
+
+	// Draw text at a position with computed style
+	text(data.Point, string, *renderstyle) error
+
+	// Draw an image referenced by a URL within a rectangle, fitted
+	// according to the aspect ratio
+	image(data.Point, data.Size, string, aspectratio) error
+
+	// Clip drawing to the union of shapes until the transform is popped
+	clip([]clipshape) error
+
+	// Draw onto a layer, which is composited through a mask element
+	// with a transform for the mask contents when the layer ends
+	beginMask() error
+	endMask(*Element, matrix) error
+
+	// Draw onto a layer, which is composited with an opacity and blend
+	// mode when the layer ends
+	beginLayer(float32, data.BlendMode) error
+	endLayer() error
+}
+
+// renderstyle is the computed style for an element, with values
+// inherited from the parent element
+type renderstyle struct {
+	fill          *data.Color
+	fillRef       string
+	fillOpacity   float32
+	fillRule      data.FillRule
+	stroke        *data.Color
+	strokeRef     string
+	strokeOpacity float32
+	strokeWidth   float32
+	lineCap       data.LineCap
+	lineJoin      data.LineJoin
+	miterLimit    float32
+	dashes        []float32
+	dashOffset    float32
+	fontFamily    string
+	fontSize      float32
+	fontWeight    string
+	fontStyle     string
+	textAnchor    data.Align
+	markerStart   string
+	markerMid     string
+	markerEnd     string
+	opacity       float32
+	blend         data.BlendMode
+	hidden        bool             // Visibility is hidden or collapse
+	displayNone   bool             // Element and children are not drawn
+	uses          int              // Depth of use elements being drawn
+	markers       int              // Depth of markers being drawn
+	rules         []data.StyleRule // Style sheet rules for the canvas
+}
+
+// vertex is a point on a path where a marker is drawn, with the
+// direction of the path into and out of the point in degrees
+type vertex struct {
+	pt      data.Point
+	in, out float32
+}
+
+// textlayout positions text runs within a text element
+type textlayout struct {
+	canvas *Canvas
+	pt     data.Point
+	runs   []textrun
+	chunks []int
+}
+
+// textrun is a string positioned on the canvas, or positioned along
+// a path where the x co-ordinate is the distance along the path
+type textrun struct {
+	pt    data.Point
+	value string
+	style *renderstyle
+	path  []data.Point
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Maximum depth of use elements which reference other use elements
+	useMaxDepth = 16
+
+	// Maximum depth of markers drawn within markers
+	markerMaxDepth = 4
+
+	// Default size of a marker viewport
+	markerSize = 3
+
+	// Maximum distance between curves and flattened curves in user
+	// space for text on a path
+	textPathTolerance = 0.05
+)
+
+var (
+	// Presentation attributes which can be used in place of style
+	presentationAttrs = []string{
+		"fill", "fill-opacity", "fill-rule",
+		"stroke", "stroke-opacity", "stroke-width",
+		"stroke-linecap", "stroke-linejoin", "stroke-miterlimit",
+		"stroke-dasharray", "stroke-dashoffset",
+		"font-family", "font-size", "font-weight", "font-style", "text-anchor",
+		"marker-start", "marker-mid", "marker-end",
+		"clip-path", "clip-rule", "mask", "filter", "overflow",
+		"stop-color", "stop-opacity",
+		"opacity", "visibility", "display",
+	}
+
+	// Blend modes for the mix-blend-mode property, where modes which
+	// are not separable are drawn with normal blending
+	blendModes = map[string]data.BlendMode{
+		"normal":      data.BlendNormal,
+		"multiply":    data.BlendMultiply,
+		"screen":      data.BlendScreen,
+		"overlay":     data.BlendOverlay,
+		"darken":      data.BlendDarken,
+		"lighten":     data.BlendLighten,
+		"color-dodge": data.BlendColorDodge,
+		"color-burn":  data.BlendColorBurn,
+		"hard-light":  data.BlendHardLight,
+		"soft-light":  data.BlendSoftLight,
+		"difference":  data.BlendDifference,
+		"exclusion":   data.BlendExclusion,
+		"hue":         data.BlendNormal,
+		"saturation":  data.BlendNormal,
+		"color":       data.BlendNormal,
+		"luminosity":  data.BlendNormal,
+	}
+
+	// Values for the display property
+	displayValues = []string{
+		"none", "inline", "block", "inline-block", "contents", "flow-root",
+		"list-item", "run-in", "table", "flex", "inline-flex", "grid", "inline-grid",
+	}
+)
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// newRenderStyle returns the initial values for a style
+func newRenderStyle() *renderstyle {
+	black := data.Color{}
+	return &renderstyle{
+		fill:          &black,
+		fillOpacity:   1,
+		fillRule:      data.NonZero,
+		stroke:        nil,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		lineCap:       data.CapButt,
+		lineJoin:      data.JoinMiter,
+		miterLimit:    4,
+		fontFamily:    "serif",
+		fontSize:      16,
+		fontWeight:    "normal",
+		fontStyle:     "normal",
+		textAnchor:    data.Start,
+		opacity:       1,
+		blend:         data.BlendNormal,
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// render draws the elements of the canvas
+func (this *Canvas) render(r renderer) error {
+	return this.renderNode(r, this.Document, this.rootStyle())
+}
+
+// rootStyle returns the initial values for a style, with the style
+// sheet rules which apply to elements of the canvas
+func (this *Canvas) rootStyle() *renderstyle {
+	style := newRenderStyle()
+	style.rules = this.styleRules()
+	return style
+}
+
+func (this *Canvas) renderNode(r renderer, node data.Node, parent *renderstyle) error {
+	elem := &Element{node, this}
+	if elem.isElement("svg", "g", "a", "use", "rect", "circle", "ellipse", "line", "polyline", "polygon", "path", "text", "image") == false {
+		return nil
+	}
+
+	// Compute style and transform. Elements which are not displayed
+	// are not drawn, nor are their children
+	style := parent.inherit(node)
+	if style.displayNone {
+		return nil
+	}
+	m := identity
+	if attr, exists := node.Attr("transform"); exists {
+		if m_, err := parseTransform(attr.Value); err != nil {
+			return err
+		} else {
+			m = m_
+		}
+	}
+	if elem.isElement("use") {
+		m = m.multiply(translateMatrix(elem.attrFloat("x", 0), elem.attrFloat("y", 0)))
+	}
+	if err := r.push(m); err != nil {
+		return err
+	}
+
+	// Draw onto a layer for opacity and blending, clip to a clipping
+	// path, and draw onto a layer for a mask
+	layer := style.opacity < 1 || style.blend != data.BlendNormal
+	if layer {
+		if err := r.beginLayer(style.opacity, style.blend); err != nil {
+			return err
+		}
+	}
+	if shapes, exists := this.clipShapes(elem, style.rules); exists {
+		if err := r.clip(shapes); err != nil {
+			return err
+		}
+	}
+	mask, maskm := this.maskFor(elem, style.rules)
+	if mask != nil {
+		if err := r.beginMask(); err != nil {
+			return err
+		}
+	}
+
+	// Draw the element
+	if err := this.renderElement(r, elem, style); err != nil {
+		return err
+	}
+	if mask != nil {
+		if err := r.endMask(mask, maskm); err != nil {
+			return err
+		}
+	}
+	if layer {
+		if err := r.endLayer(); err != nil {
+			return err
+		}
+	}
+
+	// Return success
+	return r.pop()
+}
+
+// renderElement draws the geometry, text and children of an element,
+// where geometry and images are not drawn when the element is hidden
+func (this *Canvas) renderElement(r renderer, elem *Element, style *renderstyle) error {
+	// Draw geometry
+	if path, err := elem.geometry(); err != nil {
+		return err
+	} else if len(path) > 0 && style.hidden == false {
+		if err := r.path(path, style); err != nil {
+			return err
+		} else if err := this.renderMarkers(r, path, style); err != nil {
+			return err
+		}
+	}
+
+	// Draw text, images and referenced elements
+	if elem.isElement("text") {
+		return this.renderText(r, elem.Node, style)
+	} else if elem.isElement("image") {
+		if style.hidden {
+			return nil
+		}
+		href, _ := attrHref(elem.Node)
+		pt := data.Point{elem.attrFloat("x", 0), elem.attrFloat("y", 0)}
+		size := data.Size{elem.attrFloat("width", 0), elem.attrFloat("height", 0)}
+		aspect := defaultAspectRatio
+		if attr, exists := elem.Attr("preserveAspectRatio"); exists {
+			if value, err := parseAspectRatio(attr.Value); err == nil {
+				aspect = value
+			}
+		}
+		return r.image(pt, size, strings.TrimSpace(href.Value), aspect)
+	} else if elem.isElement("use") {
+		return this.renderUse(r, elem, style)
+	}
+
+	// Draw children
+	for _, child := range elem.Children() {
+		if err := this.renderNode(r, child, style); err != nil {
+			return err
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+// renderUse draws the element referenced by a use element, where a
+// symbol is drawn within a viewport at the position of the use element
+func (this *Canvas) renderUse(r renderer, elem *Element, style *renderstyle) error {
+	attr, exists := attrHref(elem.Node)
+	if exists == false || strings.HasPrefix(attr.Value, "#") == false || style.uses >= useMaxDepth {
+		return nil
+	}
+	node := this.Document.GetElementById(strings.TrimPrefix(attr.Value, "#"))
+	if node == nil {
+		return nil
+	}
+
+	// Ignore references to the use element or any parent
+	for parent := elem.Node; parent != nil; parent = parent.Parent() {
+		if parent == node {
+			return nil
+		}
+	}
+
+	// Draw an element other than a symbol
+	style = style.use()
+	target := &Element{node, this}
+	if target.isElement("symbol") == false {
+		return this.renderNode(r, node, style)
+	}
+
+	// Determine the viewport for a symbol, where the width and height
+	// of the use element take precedence
+	size := data.Size{f32.Abs(this.size.W), f32.Abs(this.size.H)}
+	size.W = elem.attrFloat("width", target.attrFloat("width", size.W))
+	size.H = elem.attrFloat("height", target.attrFloat("height", size.H))
+	m := translateMatrix(target.attrFloat("x", 0), target.attrFloat("y", 0))
+	if origin, viewBox, err := viewBoxFromAttr(node); err == nil && viewBox != data.ZeroSize {
+		m = m.multiply(viewBoxMatrix(origin, viewBox, size))
+	}
+	if err := r.push(m); err != nil {
+		return err
+	}
+
+	// Draw the children of the symbol
+	style = style.inherit(node)
+	for _, child := range target.Children() {
+		if err := this.renderNode(r, child, style); err != nil {
+			return err
+		}
+	}
+
+	// Return success
+	return r.pop()
+}
+
+// renderMarkers draws the markers referenced by the style of a shape
+// at the vertices of its path
+func (this *Canvas) renderMarkers(r renderer, path []pathop, style *renderstyle) error {
+	if style.markerStart == "" && style.markerMid == "" && style.markerEnd == "" {
+		return nil
+	} else if style.markers >= markerMaxDepth {
+		return nil
+	}
+	vertices := markerVertices(path)
+	for i, v := range vertices {
+		id := style.markerMid
+		if i == 0 {
+			id = style.markerStart
+		} else if i == len(vertices)-1 {
+			id = style.markerEnd
+		}
+		if id == "" {
+			continue
+		}
+		if err := this.renderMarker(r, id, v, i == 0, style); err != nil {
+			return err
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+// renderMarker draws a marker at a vertex, oriented and scaled according
+// to the marker, and clipped to the marker viewport unless the overflow
+// is visible
+func (this *Canvas) renderMarker(r renderer, id string, v vertex, start bool, style *renderstyle) error {
+	node := this.Document.GetElementById(id)
+	if node == nil {
+		return nil
+	}
+	elem := &Element{node, this}
+	if elem.isElement("marker") == false {
+		return nil
+	}
+
+	// Determine the viewport and the transform of the marker contents
+	size := data.Size{elem.attrFloat("markerWidth", markerSize), elem.attrFloat("markerHeight", markerSize)}
+	if size.W <= 0 || size.H <= 0 {
+		return nil
+	}
+	vb := identity
+	if origin, viewBox, err := viewBoxFromAttr(node); err == nil && viewBox != data.ZeroSize {
+		vb = viewBoxMatrix(origin, viewBox, size)
+	}
+	ref := vb.apply(data.Point{elem.attrFloat("refX", 0), elem.attrFloat("refY", 0)})
+
+	// Determine the orientation and scale of the marker
+	orient := float32(0)
+	if value, exists := attrOrStyle(node, "orient"); exists {
+		switch value {
+		case "auto":
+			orient = bisect(v.in, v.out)
+		case "auto-start-reverse":
+			if orient = bisect(v.in, v.out); start {
+				orient += 180
+			}
+		default:
+			if a, err := strconv.ParseFloat(strings.TrimSuffix(value, "deg"), 32); err == nil {
+				orient = float32(a)
+			}
+		}
+	}
+	scale := float32(1)
+	if value, exists := attrOrStyle(node, "markerUnits"); exists == false || value != data.UserSpaceOnUse.String() {
+		scale = style.strokeWidth
+	}
+
+	// Place the viewport at the vertex and clip to the viewport
+	m := translateMatrix(v.pt.X, v.pt.Y).multiply(rotateMatrix(orient))
+	m = m.multiply(scaleMatrix(scale, scale)).multiply(translateMatrix(-ref.X, -ref.Y))
+	if err := r.push(m); err != nil {
+		return err
+	}
+	if value, exists := attrOrStyle(node, "overflow"); exists == false || (value != "visible" && value != "auto") {
+		if err := r.clip([]clipshape{{rectPath(data.ZeroPoint, size, data.ZeroSize), data.NonZero}}); err != nil {
+			return err
+		}
+	}
+
+	// Draw the contents of the marker, which inherit their style from
+	// the marker rather than the shape
+	if err := r.push(vb); err != nil {
+		return err
+	}
+	contents := this.inheritedStyle(node)
+	contents.uses, contents.markers = style.uses, style.markers+1
+	for _, child := range elem.Children() {
+		if err := this.renderNode(r, child, contents); err != nil {
+			return err
+		}
+	}
+	if err := r.pop(); err != nil {
+		return err
+	}
+
+	// Return success
+	return r.pop()
+}
+
+// markerVertices returns the vertices of a path, with the direction of
+// the path into and out of each vertex
+func markerVertices(path []pathop) []vertex {
+	var vertices []vertex
+	var start, pt data.Point
+	first := 0
+	for _, seg := range path {
+		var end, in, out data.Point
+		switch seg.op {
+		case 'M':
+			vertices = append(vertices, vertex{pt: seg.pts[0]})
+			start, pt, first = seg.pts[0], seg.pts[0], len(vertices)-1
+			continue
+		case 'L':
+			end = seg.pts[0]
+			in, out = direction(pt, end), direction(pt, end)
+		case 'Q':
+			end = seg.pts[1]
+			in, out = direction(pt, seg.pts[0], end), direction(end, seg.pts[0], pt)
+			out = data.Point{-out.X, -out.Y}
+		case 'C':
+			end = seg.pts[2]
+			in, out = direction(pt, seg.pts[0], seg.pts[1], end), direction(end, seg.pts[1], seg.pts[0], pt)
+			out = data.Point{-out.X, -out.Y}
+		case 'Z':
+			end = start
+			in, out = direction(pt, end), direction(pt, end)
+		}
+
+		// The segment leaves the previous vertex, which is entered in the
+		// same direction when it starts a subpath
+		if n := len(vertices); n > 0 {
+			vertices[n-1].out = angle(in)
+			if n-1 == first {
+				vertices[n-1].in = vertices[n-1].out
+			}
+		}
+		vertices = append(vertices, vertex{end, angle(out), angle(out)})
+		pt = end
+	}
+	return vertices
+}
+
+// direction returns the vector from a point to the first of the other
+// points which is distinct from it
+func direction(p0 data.Point, pts ...data.Point) data.Point {
+	for _, pt := range pts {
+		if pt != p0 {
+			return data.Point{pt.X - p0.X, pt.Y - p0.Y}
+		}
+	}
+	return data.Point{}
+}
+
+// angle returns the direction of a vector in degrees
+func angle(v data.Point) float32 {
+	return float32(math.Atan2(float64(v.Y), float64(v.X)) * 180 / math.Pi)
+}
+
+// bisect returns the angle which bisects two angles in degrees
+func bisect(in, out float32) float32 {
+	a, b := float64(in)*math.Pi/180, float64(out)*math.Pi/180
+	return float32(math.Atan2(math.Sin(a)+math.Sin(b), math.Cos(a)+math.Cos(b)) * 180 / math.Pi)
+}
+
+// renderText draws the text spans within a text element, where each
+// chunk of text starting at an absolute position is aligned according
+// to the text anchor
+func (this *Canvas) renderText(r renderer, node data.Node, style *renderstyle) error {
+	layout := &textlayout{canvas: this}
+	layout.layout(node, style)
+	for _, runs := range layout.chunked() {
+		if runs[0].path != nil {
+			if err := this.renderTextPath(r, runs); err != nil {
+				return err
+			}
+			continue
+		}
+		shift := this.anchorShift(runs)
+		for _, run := range runs {
+			if run.style.hidden {
+				continue
+			} else if err := r.text(data.Point{run.pt.X + shift, run.pt.Y}, run.value, run.style); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+// anchorShift returns the distance to shift a chunk of text runs so the
+// chunk is aligned according to the text anchor of the first run
+func (this *Canvas) anchorShift(runs []textrun) float32 {
+	last := runs[len(runs)-1]
+	width := last.pt.X + this.measure(last.style, last.value) - runs[0].pt.X
+	switch runs[0].style.textAnchor {
+	case data.Middle:
+		return -width / 2
+	case data.End:
+		return -width
+	default:
+		return 0
+	}
+}
+
+// renderTextPath draws text runs along a path, where each glyph is
+// rotated to the direction of the path at the middle of the glyph.
+// Glyphs beyond the ends of the path are not drawn
+func (this *Canvas) renderTextPath(r renderer, runs []textrun) error {
+	shift := this.anchorShift(runs)
+	for _, run := range runs {
+		d := run.pt.X + shift
+		for _, glyph := range run.value {
+			value := string(glyph)
+			w := this.measure(run.style, value)
+			if run.style.hidden {
+				// Hidden glyphs are not drawn
+			} else if pt, angle, ok := geom.PointAtLength(d+w/2, run.path); ok {
+				m := translateMatrix(pt.X, pt.Y).multiply(rotateMatrix(angle)).multiply(translateMatrix(-w/2, run.pt.Y))
+				if err := r.push(m); err != nil {
+					return err
+				} else if err := r.text(data.ZeroPoint, value, run.style); err != nil {
+					return err
+				} else if err := r.pop(); err != nil {
+					return err
+				}
+			}
+			d += w
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+// chunked returns the text runs in each chunk, omitting empty chunks
+func (this *textlayout) chunked() [][]textrun {
+	var chunks [][]textrun
+	for i, start := range this.chunks {
+		end := len(this.runs)
+		if i+1 < len(this.chunks) {
+			end = this.chunks[i+1]
+		}
+		if start < end {
+			chunks = append(chunks, this.runs[start:end])
+		}
+	}
+	return chunks
+}
+
+// layout adds text runs for a text or tspan element and any children
+func (this *textlayout) layout(node data.Node, style *renderstyle) {
+	elem := &Element{node, nil}
+	if xs, ys := elem.attrFloat("x", f32.NaN()), elem.attrFloat("y", f32.NaN()); f32.IsNaN(xs) == false || f32.IsNaN(ys) == false || len(this.chunks) == 0 {
+		if f32.IsNaN(xs) == false {
+			this.pt.X = xs
+		}
+		if f32.IsNaN(ys) == false {
+			this.pt.Y = ys
+		}
+		this.chunks = append(this.chunks, len(this.runs))
+	}
+	this.pt.X += elem.attrFloat("dx", 0)
+	this.pt.Y += elem.attrFloat("dy", 0)
+	for _, child := range node.Children() {
+		if child.Name().Local == "" {
+			if value := child.Cdata(); value != "" {
+				this.runs = append(this.runs, textrun{this.pt, value, style, nil})
+				this.pt.X += this.canvas.measure(style, value)
+			}
+		} else if (&Element{child, nil}).isElement("tspan") {
+			this.layout(child, style.inherit(child))
+		} else if (&Element{child, nil}).isElement("textPath") {
+			this.layoutPath(child, style.inherit(child))
+		}
+	}
+}
+
+// layoutPath adds text runs for a textPath element in a new chunk, where
+// positions are distances along the path
+func (this *textlayout) layoutPath(node data.Node, style *renderstyle) {
+	path, length := this.textPath(node)
+	if path == nil {
+		return
+	}
+	elem := &Element{node, this.canvas}
+	offset := float32(0)
+	if attr, exists := elem.Attr("startOffset"); exists {
+		if value := strings.TrimSpace(attr.Value); strings.HasSuffix(value, "%") {
+			if v, err := parseUnitValue(strings.TrimSuffix(value, "%")); err == nil {
+				offset = v * length / 100
+			}
+		} else if v, err := parseUnitValue(value); err == nil {
+			offset = v
+		}
+	}
+
+	// Add text from the element and any spans, ignoring positions
+	this.chunks = append(this.chunks, len(this.runs))
+	pt := data.Point{offset, 0}
+	var walk func(node data.Node, style *renderstyle)
+	walk = func(node data.Node, style *renderstyle) {
+		for _, child := range node.Children() {
+			if child.Name().Local == "" {
+				if value := child.Cdata(); value != "" {
+					this.runs = append(this.runs, textrun{pt, value, style, path})
+					pt.X += this.canvas.measure(style, value)
+				}
+			} else if (&Element{child, nil}).isElement("tspan") {
+				walk(child, style.inherit(child))
+			}
+		}
+	}
+	walk(node, style)
+
+	// Continue any following text from the end of the path
+	if end, _, ok := geom.PointAtLength(pt.X, path); ok {
+		this.pt = end
+	}
+	this.chunks = append(this.chunks, len(this.runs))
+}
+
+// textPath returns the flattened path for a textPath element, from the
+// path attribute or a referenced element, and the length of the path
+func (this *textlayout) textPath(node data.Node) ([]data.Point, float32) {
+	var path []pathop
+	if attr, exists := node.Attr("path"); exists {
+		if ops, err := parsePathData(attr.Value); err == nil {
+			path = ops
+		}
+	} else if attr, exists := attrHref(node); exists && this.canvas != nil && strings.HasPrefix(attr.Value, "#") {
+		if target := this.canvas.Document.GetElementById(strings.TrimPrefix(attr.Value, "#")); target != nil {
+			elem := &Element{target, this.canvas}
+			if ops, err := elem.geometry(); err == nil {
+				path = ops
+				if attr, exists := target.Attr("transform"); exists {
+					if m, err := parseTransform(attr.Value); err == nil {
+						path = transformPath(m, path)
+					}
+				}
+			}
+		}
+	}
+
+	// Join the subpaths of the flattened path
+	pts := []data.Point{}
+	for _, subpath := range flatten(path, textPathTolerance) {
+		pts = append(pts, subpath.pts...)
+		if subpath.closed && len(subpath.pts) > 0 {
+			pts = append(pts, subpath.pts[0])
+		}
+	}
+	if len(pts) < 2 {
+		return nil, 0
+	}
+	return pts, geom.Length(pts)
+}
+
+// inherit returns a new style from the parent style, with the
+// presentation attributes, style sheet rules and style attribute of
+// a node applied in order of precedence. Properties which are not
+// inherited have their initial value unless declared
+func (this *renderstyle) inherit(node data.Node) *renderstyle {
+	style := *this
+	style.opacity, style.blend, style.displayNone = 1, data.BlendNormal, false
+	for _, decl := range cascade(this.rules, node) {
+		switch decl[1] {
+		case "inherit":
+			switch decl[0] {
+			case "opacity":
+				style.opacity = this.opacity
+			case "mix-blend-mode":
+				style.blend = this.blend
+			case "display":
+				style.displayNone = this.displayNone
+			}
+		case "initial":
+			if value, exists := initialValues[decl[0]]; exists {
+				style.set(decl[0], value)
+			}
+		default:
+			style.set(decl[0], decl[1])
+		}
+	}
+	return &style
+}
+
+// use returns a new style for an element referenced by a use element
+func (this *renderstyle) use() *renderstyle {
+	style := *this
+	style.uses++
+	return &style
+}
+
+// set a style property, ignoring any which are invalid
+func (this *renderstyle) set(name, value string) {
+	value = strings.TrimSpace(value)
+	switch name {
+	case "fill":
+		if paint, ref, ok := parsePaint(value); ok {
+			this.fill, this.fillRef = paint, ref
+		}
+	case "stroke":
+		if paint, ref, ok := parsePaint(value); ok {
+			this.stroke, this.strokeRef = paint, ref
+		}
+	case "fill-opacity":
+		if v, err := parseOpacity(value); err == nil {
+			this.fillOpacity = v
+		}
+	case "stroke-opacity":
+		if v, err := parseOpacity(value); err == nil {
+			this.strokeOpacity = v
+		}
+	case "stroke-width":
+		if v, err := parseLength(value); err == nil && v >= 0 {
+			this.strokeWidth = v
+		}
+	case "stroke-miterlimit":
+		if v, err := parseLength(value); err == nil && v >= 1 {
+			this.miterLimit = v
+		}
+	case "stroke-dasharray":
+		if value == "none" {
+			this.dashes = nil
+		} else if v, err := parseDashArray(value); err == nil {
+			this.dashes = v
+		}
+	case "stroke-dashoffset":
+		if v, err := parseLength(value); err == nil {
+			this.dashOffset = v
+		}
+	case "font-family":
+		this.fontFamily = value
+	case "font-size":
+		if v, err := parseUnitValue(value); err == nil && v >= 0 {
+			this.fontSize = v
+		}
+	case "font-weight":
+		this.fontWeight = value
+	case "font-style":
+		this.fontStyle = value
+	case "text-anchor":
+		for _, align := range []data.Align{data.Start, data.Middle, data.End} {
+			if value == align.String() {
+				this.textAnchor = align
+			}
+		}
+	case "fill-rule":
+		switch value {
+		case "evenodd":
+			this.fillRule = data.EvenOdd
+		case "nonzero":
+			this.fillRule = data.NonZero
+		}
+	case "stroke-linecap":
+		for _, cap := range []data.LineCap{data.CapButt, data.CapRound, data.CapSquare} {
+			if value == cap.String() {
+				this.lineCap = cap
+			}
+		}
+	case "stroke-linejoin":
+		for _, join := range []data.LineJoin{data.JoinMiter, data.JoinMiterClip, data.JoinArcs, data.JoinRound, data.JoinBevel} {
+			if value == join.String() {
+				this.lineJoin = join
+			}
+		}
+	case "opacity":
+		if v, err := parseOpacity(value); err == nil {
+			this.opacity = v
+		}
+	case "mix-blend-mode":
+		if mode, exists := blendModes[value]; exists {
+			this.blend = mode
+		}
+	case "visibility":
+		switch value {
+		case "visible":
+			this.hidden = false
+		case "hidden", "collapse":
+			this.hidden = true
+		}
+	case "display":
+		this.displayNone = value == "none"
+	case "marker":
+		this.set("marker-start", value)
+		this.set("marker-mid", value)
+		this.set("marker-end", value)
+	case "marker-start", "marker-mid", "marker-end":
+		if _, ref, ok := parsePaint(value); ok && (ref != "" || value == "none") {
+			switch name {
+			case "marker-start":
+				this.markerStart = ref
+			case "marker-mid":
+				this.markerMid = ref
+			case "marker-end":
+				this.markerEnd = ref
+			}
+		}
+	}
+}
+
+// parseStyleAttr returns name and value pairs from a style attribute
+// in the form "<name>: <value>; <name>: <value>"
+func parseStyleAttr(value string) [][2]string {
+	result := [][2]string{}
+	for _, decl := range strings.Split(value, ";") {
+		if kv := strings.SplitN(decl, ":", 2); len(kv) == 2 {
+			if name := strings.ToLower(strings.TrimSpace(kv[0])); name != "" {
+				result = append(result, [2]string{name, strings.TrimSpace(kv[1])})
+			}
+		}
+	}
+	return result
+}
+
+// parsePaint returns a color or nil for "none", and the identifier of
+// a paint server for "url(#id)" with an optional fallback color. Returns
+// false if the value cannot be parsed
+func parsePaint(value string) (*data.Color, string, bool) {
+	switch {
+	case value == "none":
+		return nil, "", true
+	case strings.HasPrefix(value, "url("):
+		end := strings.Index(value, ")")
+		if end < 0 {
+			return nil, "", false
+		}
+		ref := strings.Trim(strings.TrimSpace(value[4:end]), "\"'")
+		if strings.HasPrefix(ref, "#") == false {
+			return nil, "", false
+		}
+		if fallback := strings.TrimSpace(value[end+1:]); fallback == "" || fallback == "none" {
+			return nil, ref[1:], true
+		} else if c, err := color.Parse(fallback); err != nil {
+			return nil, "", false
+		} else {
+			return &c, ref[1:], true
+		}
+	default:
+		if c, err := color.Parse(value); err != nil {
+			return nil, "", false
+		} else {
+			return &c, "", true
+		}
+	}
+}
+
+// parseOpacity returns a value between 0 and 1 from a number or percentage
+func parseOpacity(value string) (float32, error) {
+	scale := float32(1)
+	if strings.HasSuffix(value, "%") {
+		value, scale = strings.TrimSuffix(value, "%"), 0.01
+	}
+	if v, err := strconv.ParseFloat(value, 32); err != nil {
+		return 0, err
+	} else {
+		return f32.Max(f32.Min(float32(v)*scale, 1), 0), nil
+	}
+}
+
+// parseDashArray returns dash and gap lengths from a list of lengths,
+// repeated when there is an odd number of lengths. Returns nil when the
+// lengths are all zero, in which case the stroke is solid
+func parseDashArray(value string) ([]float32, error) {
+	result := []float32{}
+	total := float32(0)
+	for _, field := range strings.FieldsFunc(value, isListSeparator) {
+		if v, err := parseLength(field); err != nil {
+			return nil, err
+		} else if v < 0 {
+			return nil, data.ErrBadParameter.WithPrefix("Invalid dash array: ", strconv.Quote(value))
+		} else {
+			result = append(result, v)
+			total += v
+		}
+	}
+	if total == 0 {
+		return nil, nil
+	} else if len(result)%2 == 1 {
+		result = append(result, result...)
+	}
+	return result, nil
+}
+
+// parseLength returns a length in user units
+func parseLength(value string) (float32, error) {
+	if v, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 32); err != nil {
+		return 0, err
+	} else {
+		return float32(v), nil
+	}
+}
@@ -0,0 +1,173 @@
+package canvas
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// scanner reads numbers and commands from attribute values such as
+// path data, points and transforms, which allow the compact number
+// syntax (for example "1.5.5-2" is three numbers)
+type scanner struct {
+	value string
+	pos   int
+}
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func newScanner(value string) *scanner {
+	return &scanner{value: value}
+}
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// skip whitespace and an optional comma
+func (this *scanner) skip() {
+	comma := false
+	for this.pos < len(this.value) {
+		switch this.value[this.pos] {
+		case ' ', '\t', '\r', '\n':
+			this.pos++
+		case ',':
+			if comma {
+				return
+			}
+			comma = true
+			this.pos++
+		default:
+			return
+		}
+	}
+}
+
+// eof returns true when there is nothing more to read
+func (this *scanner) eof() bool {
+	this.skip()
+	return this.pos >= len(this.value)
+}
+
+// isNumber returns true if the next token is a number
+func (this *scanner) isNumber() bool {
+	if this.eof() {
+		return false
+	}
+	switch c := this.value[this.pos]; {
+	case c >= '0' && c <= '9', c == '-', c == '+', c == '.':
+		return true
+	default:
+		return false
+	}
+}
+
+// command returns the next non-numeric character or zero
+func (this *scanner) command() byte {
+	if this.eof() || this.isNumber() {
+		return 0
+	}
+	c := this.value[this.pos]
+	this.pos++
+	return c
+}
+
+// number returns the next number
+func (this *scanner) number() (float32, error) {
+	if this.isNumber() == false {
+		return 0, this.errorf("Expected number")
+	}
+	start := this.pos
+	if c := this.value[this.pos]; c == '-' || c == '+' {
+		this.pos++
+	}
+	digits, dot := false, false
+	for this.pos < len(this.value) {
+		c := this.value[this.pos]
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && dot == false {
+			dot = true
+		} else {
+			break
+		}
+		this.pos++
+	}
+	// Exponent
+	if digits && this.pos < len(this.value) && (this.value[this.pos] == 'e' || this.value[this.pos] == 'E') {
+		pos := this.pos + 1
+		if pos < len(this.value) && (this.value[pos] == '-' || this.value[pos] == '+') {
+			pos++
+		}
+		if pos < len(this.value) && this.value[pos] >= '0' && this.value[pos] <= '9' {
+			for pos < len(this.value) && this.value[pos] >= '0' && this.value[pos] <= '9' {
+				pos++
+			}
+			this.pos = pos
+		}
+	}
+	if digits == false {
+		return 0, this.errorf("Invalid number")
+	}
+	if v, err := strconv.ParseFloat(this.value[start:this.pos], 32); err != nil {
+		return 0, this.errorf("Invalid number")
+	} else {
+		return float32(v), nil
+	}
+}
+
+// flag returns a single "0" or "1" character, which may not be
+// separated from the following number
+func (this *scanner) flag() (bool, error) {
+	if this.eof() {
+		return false, this.errorf("Expected flag")
+	}
+	switch this.value[this.pos] {
+	case '0':
+		this.pos++
+		return false, nil
+	case '1':
+		this.pos++
+		return true, nil
+	default:
+		return false, this.errorf("Expected flag")
+	}
+}
+
+func (this *scanner) errorf(reason string) error {
+	return data.ErrBadParameter.WithPrefix(reason, " at position ", this.pos, " in ", strconv.Quote(this.value))
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// parseNumbers returns all numbers from a string
+func parseNumbers(value string) ([]float32, error) {
+	s := newScanner(strings.TrimSpace(value))
+	result := []float32{}
+	for s.eof() == false {
+		if v, err := s.number(); err != nil {
+			return nil, err
+		} else {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// parsePoints returns points from a "points" attribute value
+func parsePoints(value string) ([]data.Point, error) {
+	values, err := parseNumbers(value)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]data.Point, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		result = append(result, data.Point{values[i], values[i+1]})
+	}
+	return result, nil
+}
@@ -0,0 +1,266 @@
+package canvas
+
+import (
+	"math"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+	"github.com/djthorpe/data/pkg/geom"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// stroker converts polylines into polygons which cover the stroked
+// area. Each polygon is oriented clockwise so that the union can be
+// filled using the non-zero rule
+type stroker struct {
+	width     float32
+	cap       data.LineCap
+	join      data.LineJoin
+	limit     float32
+	tolerance float32
+	polys     [][]data.Point
+}
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func newStroker(width float32, cap data.LineCap, join data.LineJoin, limit, tolerance float32) *stroker {
+	return &stroker{
+		width:     f32.Abs(width),
+		cap:       cap,
+		join:      join,
+		limit:     limit,
+		tolerance: tolerance,
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// stroke returns the polygons for a set of polylines
+func (this *stroker) stroke(paths []subpath) [][]data.Point {
+	this.polys = nil
+	if this.width == 0 {
+		return nil
+	}
+	for _, path := range paths {
+		this.strokePath(path)
+	}
+	return this.polys
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *stroker) strokePath(path subpath) {
+	hw := this.width / 2
+
+	// Remove duplicate points
+	pts := make([]data.Point, 0, len(path.pts))
+	for _, pt := range path.pts {
+		if len(pts) == 0 || pts[len(pts)-1] != pt {
+			pts = append(pts, pt)
+		}
+	}
+	if path.closed && len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+
+	// Zero-length paths only draw round or square caps
+	if len(pts) == 1 {
+		switch this.cap {
+		case data.CapRound:
+			this.add(this.circle(pts[0], hw))
+		case data.CapSquare:
+			pt := pts[0]
+			this.add([]data.Point{{pt.X - hw, pt.Y - hw}, {pt.X + hw, pt.Y - hw}, {pt.X + hw, pt.Y + hw}, {pt.X - hw, pt.Y + hw}})
+		}
+		return
+	} else if len(pts) == 0 {
+		return
+	}
+
+	// Segments
+	n := len(pts) - 1
+	if path.closed && len(pts) > 2 {
+		n = len(pts)
+	}
+	for i := 0; i < n; i++ {
+		p0, p1 := pts[i], pts[(i+1)%len(pts)]
+		nx, ny := normal(p0, p1, hw)
+		this.add([]data.Point{
+			{p0.X + nx, p0.Y + ny}, {p1.X + nx, p1.Y + ny},
+			{p1.X - nx, p1.Y - ny}, {p0.X - nx, p0.Y - ny},
+		})
+	}
+
+	// Joins
+	for i := 1; i < len(pts); i++ {
+		if i == len(pts)-1 && n != len(pts) {
+			break
+		}
+		this.addJoin(pts[i-1], pts[i], pts[(i+1)%len(pts)], hw)
+	}
+	if n == len(pts) {
+		this.addJoin(pts[len(pts)-1], pts[0], pts[1], hw)
+		return
+	}
+
+	// Caps
+	this.addCap(pts[1], pts[0], hw)
+	this.addCap(pts[len(pts)-2], pts[len(pts)-1], hw)
+}
+
+// addJoin adds the join at p1 between segments p0-p1 and p1-p2
+func (this *stroker) addJoin(p0, p1, p2 data.Point, hw float32) {
+	ax, ay := normal(p0, p1, hw)
+	bx, by := normal(p1, p2, hw)
+
+	// Determine the outer side of the join
+	cross := (p1.X-p0.X)*(p2.Y-p1.Y) - (p1.Y-p0.Y)*(p2.X-p1.X)
+	if cross == 0 && (p1.X-p0.X)*(p2.X-p1.X)+(p1.Y-p0.Y)*(p2.Y-p1.Y) > 0 {
+		return
+	}
+	side := float32(1)
+	if cross > 0 {
+		side = -1
+	}
+	a := data.Point{p1.X + side*ax, p1.Y + side*ay}
+	b := data.Point{p1.X + side*bx, p1.Y + side*by}
+
+	switch this.join {
+	case data.JoinRound:
+		this.add(this.circle(p1, hw))
+	case data.JoinBevel:
+		this.add([]data.Point{p1, a, b})
+	default:
+		// Miter length ratio is 1 / sin(theta/2) where theta is the
+		// angle between the segments
+		mx, my := (ax+bx)/2, (ay+by)/2
+		ml := hypot(mx, my)
+		if ml == 0 || hw/ml > this.limit || this.limit < 1 {
+			this.add([]data.Point{p1, a, b})
+		} else {
+			k := hw * hw / (ml * ml)
+			m := data.Point{p1.X + side*mx*k, p1.Y + side*my*k}
+			this.add([]data.Point{p1, a, m, b})
+		}
+	}
+}
+
+// addCap adds a line cap at p1 for the segment p0-p1
+func (this *stroker) addCap(p0, p1 data.Point, hw float32) {
+	switch this.cap {
+	case data.CapRound:
+		this.add(this.circle(p1, hw))
+	case data.CapSquare:
+		nx, ny := normal(p0, p1, hw)
+		dx, dy := ny, -nx
+		this.add([]data.Point{
+			{p1.X + nx, p1.Y + ny}, {p1.X + nx + dx, p1.Y + ny + dy},
+			{p1.X - nx + dx, p1.Y - ny + dy}, {p1.X - nx, p1.Y - ny},
+		})
+	}
+}
+
+// circle returns a polygon approximating a circle within tolerance
+func (this *stroker) circle(c data.Point, r float32) []data.Point {
+	n := 8
+	if r > this.tolerance {
+		n = int(math.Ceil(math.Pi / math.Acos(1-float64(this.tolerance/r))))
+	}
+	if n < 8 {
+		n = 8
+	}
+	pts := make([]data.Point, n)
+	for i := range pts {
+		theta := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = data.Point{c.X + r*float32(math.Cos(theta)), c.Y + r*float32(math.Sin(theta))}
+	}
+	return pts
+}
+
+// add a polygon, ensuring a consistent orientation
+func (this *stroker) add(poly []data.Point) {
+	if geom.Area(poly) < 0 {
+		for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+			poly[i], poly[j] = poly[j], poly[i]
+		}
+	}
+	this.polys = append(this.polys, poly)
+}
+
+// normal returns the left-hand normal of a segment with length hw
+func normal(p0, p1 data.Point, hw float32) (float32, float32) {
+	dx, dy := p1.X-p0.X, p1.Y-p0.Y
+	if l := hypot(dx, dy); l == 0 {
+		return 0, 0
+	} else {
+		return dy * hw / l, -dx * hw / l
+	}
+}
+
+// dash returns open paths for the dashes along flattened paths, where
+// the dash and gap lengths alternate and the pattern starts at an offset.
+// Returns the paths unchanged when there are no dashes
+func dash(paths []subpath, dashes []float32, offset float32) []subpath {
+	total := float32(0)
+	for _, v := range dashes {
+		total += v
+	}
+	if total <= 0 {
+		return paths
+	}
+
+	result := []subpath{}
+	for _, path := range paths {
+		pts := path.pts
+		if len(pts) == 0 {
+			continue
+		} else if path.closed && pts[0] != pts[len(pts)-1] {
+			pts = append(append([]data.Point{}, pts...), pts[0])
+		}
+
+		// Determine the dash at the start of the path
+		i, d := 0, offset-total*f32.Floor(offset/total)
+		for k := 0; k < len(dashes) && d >= dashes[i]; k++ {
+			d -= dashes[i]
+			i = (i + 1) % len(dashes)
+		}
+		remaining, on := dashes[i]-d, i%2 == 0
+		var cur []data.Point
+		if on {
+			cur = []data.Point{pts[0]}
+		}
+
+		// Split segments at the end of each dash and gap
+		for j := 1; j < len(pts); j++ {
+			p0, p1 := pts[j-1], pts[j]
+			length, pos := hypot(p1.X-p0.X, p1.Y-p0.Y), float32(0)
+			for length-pos > remaining {
+				pos += remaining
+				pt := data.Point{p0.X + (p1.X-p0.X)*pos/length, p0.Y + (p1.Y-p0.Y)*pos/length}
+				if on {
+					result = append(result, subpath{pts: append(cur, pt)})
+					cur = nil
+				} else {
+					cur = []data.Point{pt}
+				}
+				i = (i + 1) % len(dashes)
+				remaining, on = dashes[i], i%2 == 0
+			}
+			remaining -= length - pos
+			if on {
+				cur = append(cur, p1)
+			}
+		}
+		if on && len(cur) > 1 {
+			result = append(result, subpath{pts: cur})
+		}
+	}
+
+	// Return dashes
+	return result
+}
@@ -32,6 +32,9 @@ type styledef struct {
 	Style   data.FontVariant
 	Weight  data.FontVariant
 	Uri     string
+	Dashes  []float32
+	Blend   data.BlendMode
+	Visible bool
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -57,9 +60,18 @@ const (
 	fontSize
 	fontWeight
 	fontStyle
+	strokeDashArray
+	strokeDashOffset
+	clipPath
+	maskRef
+	filterRef
+	opacity
+	blendMode
+	visibility
+	display
 	styleNone styleop = 0
 	styleMin          = fillNone
-	styleMax          = fontStyle
+	styleMax          = display
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -120,6 +132,14 @@ func (*Canvas) Fill(color data.Color, opacity float32) data.CanvasStyle {
 	return &styledef{Op: fillColor | fillOpacity, Color: color, Opacity: opacity}
 }
 
+func (*Canvas) FillGradient(id string) data.CanvasStyle {
+	return &styledef{Op: fillColor, Uri: urlForId(id)}
+}
+
+func (*Canvas) FillPattern(id string) data.CanvasStyle {
+	return &styledef{Op: fillColor, Uri: urlForId(id)}
+}
+
 func (*Canvas) FillRule(rule data.FillRule) data.CanvasStyle {
 	return &styledef{Op: fillRule, Rule: rule}
 }
@@ -128,6 +148,14 @@ func (*Canvas) Stroke(color data.Color, opacity float32) data.CanvasStyle {
 	return &styledef{Op: strokeColor | strokeOpacity, Color: color, Opacity: opacity}
 }
 
+func (*Canvas) StrokeGradient(id string) data.CanvasStyle {
+	return &styledef{Op: strokeColor, Uri: urlForId(id)}
+}
+
+func (*Canvas) StrokePattern(id string) data.CanvasStyle {
+	return &styledef{Op: strokeColor, Uri: urlForId(id)}
+}
+
 func (*Canvas) StrokeWidth(width float32) data.CanvasStyle {
 	if width == 0 {
 		return &styledef{Op: strokeNone}
@@ -148,6 +176,18 @@ func (*Canvas) MiterLimit(limit float32) data.CanvasStyle {
 	return &styledef{Op: miterLimit, Width: limit}
 }
 
+func (*Canvas) StrokeDash(dashes ...float32) data.CanvasStyle {
+	def := &styledef{Op: strokeDashArray}
+	for _, dash := range dashes {
+		def.Dashes = append(def.Dashes, f32.Abs(dash))
+	}
+	return def
+}
+
+func (*Canvas) StrokeDashOffset(offset float32) data.CanvasStyle {
+	return &styledef{Op: strokeDashOffset, Width: offset}
+}
+
 func (this *Canvas) UseMarker(pos data.Align, uri string) data.CanvasStyle {
 	// if uri is #?([a-zA-Z\-]+[a-zA-Z0-9\-]*) then wrap it in url(#id)
 	if this.isAttrId(uri) {
@@ -166,6 +206,30 @@ func (this *Canvas) UseMarker(pos data.Align, uri string) data.CanvasStyle {
 	return &styledef{Op: op, Uri: uri}
 }
 
+func (*Canvas) Clip(id string) data.CanvasStyle {
+	return &styledef{Op: clipPath, Uri: urlForId(id)}
+}
+
+func (*Canvas) MaskWith(id string) data.CanvasStyle {
+	return &styledef{Op: maskRef, Uri: urlForId(id)}
+}
+
+func (*Canvas) Opacity(value float32) data.CanvasStyle {
+	return &styledef{Op: opacity, Opacity: f32.Max(0, f32.Min(value, 1))}
+}
+
+func (*Canvas) Blend(mode data.BlendMode) data.CanvasStyle {
+	return &styledef{Op: blendMode, Blend: mode}
+}
+
+func (*Canvas) Visibility(visible bool) data.CanvasStyle {
+	return &styledef{Op: visibility, Visible: visible}
+}
+
+func (*Canvas) Display(visible bool) data.CanvasStyle {
+	return &styledef{Op: display, Visible: visible}
+}
+
 func (*Canvas) FontSize(width float32, unit data.Unit) data.CanvasStyle {
 	return &styledef{
 		Op:    fontSize,
@@ -287,6 +351,24 @@ func (f styleop) String() string {
 		return "font-weight"
 	case fontStyle:
 		return "font-style"
+	case strokeDashArray:
+		return "stroke-dasharray"
+	case strokeDashOffset:
+		return "stroke-dashoffset"
+	case clipPath:
+		return "clip-path"
+	case maskRef:
+		return "mask"
+	case filterRef:
+		return "filter"
+	case opacity:
+		return "opacity"
+	case blendMode:
+		return "mix-blend-mode"
+	case visibility:
+		return "visibility"
+	case display:
+		return "display"
 	default:
 		return "[?? invalid styleop value]"
 	}
@@ -302,11 +384,11 @@ func (f styleop) StyleString(ops styleop, args *styledef) (string, error) {
 		if isFillNone == false {
 			return f.StyleStringEx(args)
 		}
-	case strokeColor, strokeOpacity, strokeWidth, miterLimit, lineCap, lineJoin:
+	case strokeColor, strokeOpacity, strokeWidth, miterLimit, lineCap, lineJoin, strokeDashArray, strokeDashOffset:
 		if isStrokeNone == false {
 			return f.StyleStringEx(args)
 		}
-	case markerStart, markerMid, markerEnd:
+	case markerStart, markerMid, markerEnd, clipPath, maskRef, filterRef:
 		return fmt.Sprint(f, ": ", args.Uri, ";"), nil
 	case fontSize:
 		return fmt.Sprint(f, ": ", f32.String(args.Width), args.Unit, ";"), nil
@@ -318,6 +400,22 @@ func (f styleop) StyleString(ops styleop, args *styledef) (string, error) {
 		return fmt.Sprint(f, ": ", args.Style, ";"), nil
 	case textAnchor:
 		return fmt.Sprint(f, ": ", args.Align, ";"), nil
+	case opacity:
+		return fmt.Sprint(f, ": ", f32.String(args.Opacity), ";"), nil
+	case blendMode:
+		return fmt.Sprint(f, ": ", args.Blend, ";"), nil
+	case visibility:
+		if args.Visible {
+			return fmt.Sprint(f, ": visible;"), nil
+		} else {
+			return fmt.Sprint(f, ": hidden;"), nil
+		}
+	case display:
+		if args.Visible {
+			return fmt.Sprint(f, ": inline;"), nil
+		} else {
+			return fmt.Sprint(f, ": none;"), nil
+		}
 	default:
 		return "", data.ErrBadParameter.WithPrefix("SetStyle: ", f)
 	}
@@ -327,10 +425,12 @@ func (f styleop) StyleString(ops styleop, args *styledef) (string, error) {
 
 func (f styleop) StyleStringEx(args *styledef) (string, error) {
 	switch f {
-	case fillColor:
-		return fmt.Sprint(f, ": ", color.String(args.Color), ";"), nil
-	case strokeColor:
-		return fmt.Sprint(f, ": ", color.String(args.Color), ";"), nil
+	case fillColor, strokeColor:
+		if args.Uri != "" {
+			return fmt.Sprint(f, ": ", args.Uri, ";"), nil
+		} else {
+			return fmt.Sprint(f, ": ", color.String(args.Color), ";"), nil
+		}
 	case fillOpacity:
 		return fmt.Sprint(f, ": ", f32.String(args.Opacity), ";"), nil
 	case strokeOpacity:
@@ -345,11 +445,24 @@ func (f styleop) StyleStringEx(args *styledef) (string, error) {
 		return fmt.Sprint(f, ": ", args.Join, ";"), nil
 	case fillRule:
 		return fmt.Sprint(f, ": ", args.Rule, ";"), nil
+	case strokeDashArray:
+		if len(args.Dashes) == 0 {
+			return fmt.Sprint(f, ": none;"), nil
+		} else {
+			return fmt.Sprint(f, ": ", f32.Join(args.Dashes, " "), ";"), nil
+		}
+	case strokeDashOffset:
+		return fmt.Sprint(f, ": ", f32.String(args.Width), ";"), nil
 	default:
 		return "", data.ErrBadParameter.WithPrefix("SetStyle: ", f)
 	}
 }
 
+// urlForId returns a reference to an element with an identifier
+func urlForId(id string) string {
+	return "url(#" + strings.TrimPrefix(strings.TrimSpace(id), "#") + ")"
+}
+
 func (s *Style) String() string {
 	attrs := make([]string, 0, len(s.defs))
 	for v := styleMin; v <= styleMax; v <<= 1 {
@@ -0,0 +1,101 @@
+package canvas
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/stylesheet"
+)
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+var (
+	styleName = xml.Name{data.XmlNamespaceSVG, "style"}
+)
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// StyleSheet replaces the style sheets at the head of the canvas with
+// a style element containing the rules of a style sheet, after any
+// title and description. A nil style sheet removes the style sheets
+func (this *Canvas) StyleSheet(sheet data.StyleSheet) data.Canvas {
+	// Remove existing style sheets
+	for _, child := range elementChildren(this.Document) {
+		if child.Name() == styleName {
+			if err := this.Document.RemoveChild(child); err != nil {
+				return nil
+			}
+		}
+	}
+	if sheet == nil {
+		return this
+	}
+
+	// Create a style element
+	var css strings.Builder
+	if err := sheet.Write(&css); err != nil {
+		return nil
+	}
+	style := this.Document.CreateElementNS("style", data.XmlNamespaceSVG)
+	if err := style.AddChild(this.Document.CreateText(css.String())); err != nil {
+		return nil
+	}
+
+	// Insert the style element after the title and description
+	var ref data.Node
+	for _, child := range elementChildren(this.Document) {
+		if name := child.Name(); name.Space != data.XmlNamespaceSVG || (name.Local != "title" && name.Local != "desc" && name.Local != "metadata") {
+			ref = child
+			break
+		}
+	}
+	if ref == nil {
+		if err := this.Document.AddChild(style); err != nil {
+			return nil
+		}
+	} else if err := this.Document.InsertChildBefore(style, ref); err != nil {
+		return nil
+	}
+
+	// Return success
+	return this
+}
+
+// Rules returns the rules from the style sheets in the canvas which
+// match a tag, class, id and state, in document order. Where any
+// argument is empty any rule can match
+func (this *Canvas) Rules(tag, class, id, state string) []data.StyleRule {
+	if sheet, err := this.styleSheet(); err != nil {
+		return []data.StyleRule{}
+	} else {
+		return sheet.Rules(tag, class, id, state)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// styleSheet returns the rules of all style elements in the canvas
+func (this *Canvas) styleSheet() (data.StyleSheet, error) {
+	sheet := stylesheet.NewStyleSheet()
+	var result error
+	walkElements(this.Document, func(node data.Node) {
+		if result == nil && isStyleSheet(node) {
+			result = sheet.Read(strings.NewReader(textContent(node)))
+		}
+	})
+	return sheet, result
+}
+
+// isStyleSheet returns true if a node is a style element containing CSS
+func isStyleSheet(node data.Node) bool {
+	if node.Name() != styleName {
+		return false
+	} else if attr, exists := node.Attr("type"); exists && strings.TrimSpace(attr.Value) != "" && strings.TrimSpace(attr.Value) != "text/css" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package canvas_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	stylesheet "github.com/djthorpe/data/pkg/stylesheet"
+)
+
+func Test_StyleSheet_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX).Title("Graph Paper")
+	c.Line(data.Point{0, 50}, data.Point{100, 50}).Class(data.ClassGraphPaperYMajor)
+	s := stylesheet.NewStyleSheet()
+	if err := s.Add("line."+data.ClassGraphPaperYMajor, "stroke: #ccc; stroke-width: 0.5"); err != nil {
+		t.Fatal(err)
+	}
+	if c.StyleSheet(s) == nil {
+		t.Fatal("Unexpected nil canvas")
+	}
+
+	// The style sheet is written after the title
+	b := new(strings.Builder)
+	if err := c.Write(data.SVG|data.Minify, b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); strings.Contains(str, "<title>Graph Paper</title><style>line.majory { stroke: #ccc; stroke-width: 0.5; }\n</style><line") == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+
+	// Rules are read back
+	if c2, err := canvas.Read(data.SVG, strings.NewReader(b.String())); err != nil {
+		t.Fatal(err)
+	} else if rules := c2.Rules("line", data.ClassGraphPaperYMajor, "", ""); len(rules) != 2 {
+		t.Error("Unexpected rules, got: ", rules)
+	} else if rules[1].Name() != "stroke-width" || rules[1].Value() != "0.5" {
+		t.Error("Unexpected rule, got: ", rules[1])
+	} else if rules := c2.Rules("line", data.ClassGraphPaperXMajor, "", ""); len(rules) != 0 {
+		t.Error("Unexpected rules, got: ", rules)
+	}
+
+	// Replace and remove the style sheet
+	s2 := stylesheet.NewStyleSheet()
+	s2.Add("*", "fill: none")
+	if rules := c.StyleSheet(s2).Rules("", "", "", ""); len(rules) != 1 {
+		t.Error("Unexpected rules, got: ", rules)
+	}
+	if rules := c.StyleSheet(nil).Rules("", "", "", ""); len(rules) != 0 {
+		t.Error("Unexpected rules, got: ", rules)
+	} else if str := fmt.Sprint(c.DOM()); strings.Contains(str, "<style") {
+		t.Error("Unexpected style sheet, got: ", str)
+	}
+}
+
+func Test_StyleSheet_002(t *testing.T) {
+	// Invalid style sheets are not read
+	for _, css := range []string{"rect { fill: red", "rect { fill red }"} {
+		svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><style>` + css + `</style></svg>`
+		if _, err := canvas.Read(data.SVG, strings.NewReader(svg)); err == nil {
+			t.Error("Expected error for: ", css)
+		} else if strings.Count(err.Error(), "ErrBadParameter") != 1 {
+			t.Error("Unexpected error: ", err)
+		}
+	}
+
+	// Rule sets with unsupported selectors are skipped and written out
+	// unchanged
+	css := `.a .b, rect { fill: red } g > rect { fill: blue } @media print { rect { fill: none } }`
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><style>` + css + `</style></svg>`
+	if c, err := canvas.Read(data.SVG, strings.NewReader(svg)); err != nil {
+		t.Error(err)
+	} else if rules := c.Rules("", "", "", ""); len(rules) != 1 || rules[0].Tag() != "rect" {
+		t.Error("Unexpected rules, got: ", rules)
+	} else if str := fmt.Sprint(c); strings.Contains(str, `<style>.a .b, rect { fill: red } g &gt; rect { fill: blue } @media print { rect { fill: none } }</style>`) == false {
+		t.Error("Unexpected style sheet, got: ", str)
+	}
+
+	// Style sheets of other types are ignored
+	svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><style type="text/other">g > rect</style></svg>`
+	if c, err := canvas.Read(data.SVG, strings.NewReader(svg)); err != nil {
+		t.Error(err)
+	} else if rules := c.Rules("", "", "", ""); len(rules) != 0 {
+		t.Error("Unexpected rules, got: ", rules)
+	}
+}
@@ -59,10 +59,16 @@ func (this *Canvas) TextSpan(value string) data.CanvasText {
 	return elem
 }
 
-func (this *Canvas) TextPath(value string) data.CanvasText {
+func (this *Canvas) TextPath(id, value string) data.CanvasText {
+	id = strings.TrimPrefix(strings.TrimSpace(id), "#")
+	if id == "" {
+		return nil
+	}
 	elem, err := this.NewElement("textPath")
 	if err != nil {
 		return nil
+	} else if err := elem.SetAttrNS("href", data.XmlNamespaceXLink, "#"+id); err != nil {
+		return nil
 	}
 
 	// Add cdata
@@ -100,6 +106,61 @@ func (this *Element) Offset(pt data.Point) data.CanvasText {
 	return this.Origin(pt, true)
 }
 
+func (this *Element) StartOffset(offset float32, percent bool) data.CanvasText {
+	// Only possible on textPath elements
+	if this.isElement("textPath") == false {
+		return nil
+	}
+
+	// Set attributes
+	value := f32.String(offset)
+	if percent {
+		value += "%"
+	}
+	if offset == 0 {
+		this.RemoveAttr("startOffset")
+	} else if err := this.SetAttr("startOffset", value); err != nil {
+		return nil
+	}
+
+	// Success
+	return this
+}
+
+func (this *Element) PathMethod(method data.TextMethod) data.CanvasText {
+	// Only possible on textPath elements
+	if this.isElement("textPath") == false {
+		return nil
+	}
+
+	// Set attributes
+	if method == data.MethodAlign {
+		this.RemoveAttr("method")
+	} else if err := this.SetAttr("method", method.String()); err != nil {
+		return nil
+	}
+
+	// Success
+	return this
+}
+
+func (this *Element) PathSpacing(spacing data.TextSpacing) data.CanvasText {
+	// Only possible on textPath elements
+	if this.isElement("textPath") == false {
+		return nil
+	}
+
+	// Set attributes
+	if spacing == data.SpacingExact {
+		this.RemoveAttr("spacing")
+	} else if err := this.SetAttr("spacing", spacing.String()); err != nil {
+		return nil
+	}
+
+	// Success
+	return this
+}
+
 func (this *Element) Length(length float32, adjust data.Adjust) data.CanvasText {
 	// Only possible on text, textPath and tspan elements
 	if this.isElement("tspan", "textPath", "text") == false {
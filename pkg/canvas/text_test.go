@@ -0,0 +1,121 @@
+package canvas_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	f32 "github.com/djthorpe/data/pkg/f32"
+	font "github.com/djthorpe/data/pkg/font"
+)
+
+func Test_Text_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	p := c.TextPath("#arc", "Hello").StartOffset(50, true).PathMethod(data.MethodStretch).PathSpacing(data.SpacingAuto)
+	if p == nil {
+		t.Fatal("Unexpected nil from c.TextPath")
+	} else if str := fmt.Sprint(p); str != `<textPath xlink:href="#arc" startOffset="50%" method="stretch" spacing="auto">Hello</textPath>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if p.StartOffset(0, false).PathMethod(data.MethodAlign).PathSpacing(data.SpacingExact) == nil {
+		t.Error("Unexpected nil from p.StartOffset")
+	} else if str := fmt.Sprint(p); str != `<textPath xlink:href="#arc">Hello</textPath>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if p := c.TextPath("", "Hello"); p != nil {
+		t.Error("Expected nil for missing id")
+	}
+	if s := c.TextSpan("Hello").StartOffset(10, false); s != nil {
+		t.Error("Expected nil for start offset on text span")
+	}
+}
+
+func Test_Text_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Defs(c.Path(c.MoveTo(data.Point{10, 50}), c.LineTo(data.Point{90, 50})).Id("baseline"))
+	c.Text(data.ZeroPoint, false, c.TextPath("baseline", "AB").StartOffset(5, false))
+
+	// Write and read back the canvas
+	b := new(bytes.Buffer)
+	if err := c.Write(data.SVG, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if strings.Contains(str, `<textPath xlink:href="#baseline" startOffset="5">AB</textPath>`) == false {
+		t.Error("Unexpected output: ", str)
+	}
+	c2, err := canvas.Read(data.SVG, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2 := new(bytes.Buffer)
+	if err := c2.Write(data.SVG, b2); err != nil {
+		t.Fatal(err)
+	} else if b2.String() != str {
+		t.Error("Unexpected difference after reading canvas:\n", str, "\n", b2.String())
+	}
+
+	// Glyphs are drawn along the path in PDF documents
+	b.Reset()
+	if err := c2.Write(data.PDF, b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); strings.Contains(str, "1 0 0 1 15 50 cm") == false {
+		t.Error("Expected first glyph at start offset")
+	}
+}
+
+func Test_Text_003(t *testing.T) {
+	for _, svg := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"><textPath href="#p">Hello</textPath></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><text><textPath>Hello</textPath></text></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><text><textPath href="#p" method="bend">Hello</textPath></text></svg>`,
+	} {
+		if _, err := canvas.Read(data.SVG, strings.NewReader(svg)); err == nil {
+			t.Error("Expected error reading: ", svg)
+		}
+	}
+}
+
+func Test_Text_004(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	f := c.Font("sans-serif", data.Regular)
+	if f == nil || f.Family() != "Helvetica" {
+		t.Fatal("Unexpected font, got: ", f)
+	}
+	if sz := c.MeasureText(f, 10, "Hello"); f32.Abs(sz.W-22.78) > 0.001 || f32.Abs(sz.H-11.17) > 0.001 {
+		t.Error("Unexpected size, got: ", sz)
+	}
+
+	// Bounding box of anchored text
+	text := c.Text(data.Point{50, 50}, false, c.TextSpan("Hello")).Style(
+		c.FontFamily("sans-serif"), c.FontSize(10, data.PX), c.TextAnchor(data.Middle),
+	)
+	origin, size := c.TextBounds(text)
+	if f32.Abs(origin.X-38.61) > 0.001 || f32.Abs(origin.Y-40.95) > 0.001 {
+		t.Error("Unexpected origin, got: ", origin)
+	}
+	if f32.Abs(size.W-22.78) > 0.001 || f32.Abs(size.H-11.17) > 0.001 {
+		t.Error("Unexpected size, got: ", size)
+	}
+	if _, size := c.TextBounds(c.Rect(data.ZeroPoint, data.Size{10, 10})); size != data.ZeroSize {
+		t.Error("Expected zero size for rect, got: ", size)
+	}
+
+	// Registered fonts are used in preference to bundled metrics
+	if err := c.AddFont(nil); err == nil {
+		t.Error("Expected error for nil font")
+	}
+	mono := font.Generic("monospace", data.Regular)
+	if err := c.AddFont(mono); err != nil {
+		t.Fatal(err)
+	} else if f := c.Font("courier", data.Bold); f != mono {
+		t.Error("Expected registered font, got: ", f)
+	}
+	text.Style(c.FontFamily("Courier"), c.FontSize(10, data.PX))
+	if _, size := c.TextBounds(text); f32.Abs(size.W-30) > 0.001 {
+		t.Error("Unexpected size, got: ", size)
+	}
+}
@@ -3,6 +3,7 @@ package canvas
 import (
 	"github.com/djthorpe/data"
 	"github.com/djthorpe/data/pkg/f32"
+	"github.com/djthorpe/data/pkg/geom"
 )
 
 type TransformOperation string
@@ -76,3 +77,36 @@ func (*Canvas) SkewY(deg float32) data.CanvasTransform {
 		return NewTransformOperation("skewy", deg)
 	}
 }
+
+func (*Canvas) Matrix(m [6]float32) data.CanvasTransform {
+	if geom.Matrix(m).IsIdentity() {
+		return NilTransformOperation
+	} else {
+		return NewTransformOperation("matrix", m[:]...)
+	}
+}
+
+// ElementTransform returns the transform from the co-ordinate system of
+// an element onto the co-ordinate system of the canvas, which combines
+// the transforms of the element and any groups which contain it
+func ElementTransform(elem data.CanvasElement) (geom.Matrix, error) {
+	elem_, ok := elem.(*Element)
+	if ok == false || elem_ == nil {
+		return geom.IdentityMatrix, data.ErrBadParameter.WithPrefix("ElementTransform")
+	}
+	m := geom.IdentityMatrix
+	name := elem_.transformAttr()
+	for node := elem_.Node; node != nil; node = node.Parent() {
+		if attr, exists := node.Attr(name); exists {
+			if n, err := geom.ParseTransform(attr.Value); err != nil {
+				return geom.IdentityMatrix, err
+			} else {
+				m = n.Multiply(m)
+			}
+		}
+		name = "transform"
+	}
+
+	// Return success
+	return m, nil
+}
@@ -0,0 +1,83 @@
+package canvas_test
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	canvas "github.com/djthorpe/data/pkg/canvas"
+	color "github.com/djthorpe/data/pkg/color"
+)
+
+func Test_Use_001(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	s := c.Symbol(data.Point{-5, -5}, data.Size{10, 10}, c.Circle(data.ZeroPoint, 5))
+	if s == nil {
+		t.Fatal("Unexpected nil from c.Symbol")
+	} else if s.Id("dot") == nil {
+		t.Fatal("Unexpected nil from s.Id")
+	} else if str := fmt.Sprint(c.DOM().FirstChild()); str != `<defs><symbol viewBox="-5 -5 10 10" width="10" height="10" id="dot"><circle cx="0" cy="0" r="5"></circle></symbol></defs>` {
+		t.Error("Expected symbol in defs, got: ", str)
+	}
+	if u := c.Use("#dot", data.Point{10, 20}); u == nil {
+		t.Error("Unexpected nil from c.Use")
+	} else if str := fmt.Sprint(u); str != `<use xlink:href="#dot" x="10" y="20"></use>` {
+		t.Error("Unexpected return, got: ", str)
+	}
+	if u := c.Use("", data.ZeroPoint); u != nil {
+		t.Error("Expected nil for missing id")
+	}
+}
+
+func Test_Use_002(t *testing.T) {
+	c := canvas.NewCanvas(data.Size{100, 100}, data.PX)
+	c.Symbol(data.ZeroPoint, data.Size{10, 10}, c.Rect(data.ZeroPoint, data.Size{10, 10})).Id("box")
+	c.Use("box", data.Point{50, 50})
+
+	// Write and read back the canvas
+	b := new(bytes.Buffer)
+	if err := c.Write(data.SVG, b); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	c2, err := canvas.Read(data.SVG, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2 := new(bytes.Buffer)
+	if err := c2.Write(data.SVG, b2); err != nil {
+		t.Fatal(err)
+	} else if b2.String() != str {
+		t.Error("Unexpected difference after reading canvas:\n", str, "\n", b2.String())
+	}
+}
+
+func Test_Use_003(t *testing.T) {
+	// Draw a symbol three times, and a group once
+	c := canvas.NewCanvas(data.Size{40, 10}, data.PX)
+	c.Symbol(data.Point{-5, -5}, data.Size{10, 10}, c.Rect(data.Point{-5, -5}, data.Size{10, 10})).Id("box")
+	c.Defs(c.Group(c.Rect(data.ZeroPoint, data.Size{10, 10})).Id("group").Style(c.Fill(color.Red, 1)))
+	for x := float32(0); x < 30; x += 10 {
+		c.Use("box", data.Point{x, 0})
+	}
+	c.Use("group", data.Point{30, 0})
+
+	b := new(bytes.Buffer)
+	if err := c.Write(data.PNG, b); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 5; x < 30; x += 10 {
+		if r, _, _, a := img.At(x, 5).RGBA(); r != 0 || a != 0xFFFF {
+			t.Error("Expected symbol at ", x, ": ", img.At(x, 5))
+		}
+	}
+	if r, _, _, a := img.At(35, 5).RGBA(); r != 0xFFFF || a != 0xFFFF {
+		t.Error("Expected group at 35: ", img.At(35, 5))
+	}
+}
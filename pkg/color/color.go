@@ -6,6 +6,7 @@ import (
 	"math"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -310,12 +311,15 @@ var (
 		"Brown":                SwatchColor{Brown, 138, data.ColorBrown},
 		"Maroon":               SwatchColor{Maroon, 139, data.ColorBrown},
 	}
-	colorSync sync.Once
-	colorHash = make(map[string]string, len(colorNames))
+	colorSync  sync.Once
+	colorHash  = make(map[string]string, len(colorNames))
+	colorLower = make(map[string]data.Color, len(colorNames))
 )
 
 var (
 	reWords = regexp.MustCompile("[A-Z][^A-Z]*")
+	reHash  = regexp.MustCompile("^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$")
+	reRGB   = regexp.MustCompile("^rgb\\(\\s*([0-9.]+%?)\\s*,\\s*([0-9.]+%?)\\s*,\\s*([0-9.]+%?)\\s*\\)$")
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -327,6 +331,7 @@ func colorInit() {
 		for name, value := range colorNames {
 			key := HashString(value.Color)
 			colorHash[key] = name
+			colorLower[strings.ToLower(name)] = value.Color
 		}
 	})
 }
@@ -360,6 +365,39 @@ func Name(color data.Color) string {
 	}
 }
 
+// Parse returns a color from a color name, a hash in the form #RGB or
+// #RRGGBB or a functional notation in the form rgb(r,g,b)
+func Parse(value string) (data.Color, error) {
+	colorInit()
+	value = strings.TrimSpace(value)
+	if color, exists := colorLower[strings.ToLower(value)]; exists {
+		return color, nil
+	}
+	if match := reHash.FindStringSubmatch(value); match != nil {
+		hex := match[1]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if v, err := strconv.ParseUint(hex, 16, 32); err != nil {
+			return data.Color{}, err
+		} else {
+			return data.Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+		}
+	}
+	if match := reRGB.FindStringSubmatch(strings.ToLower(value)); match != nil {
+		var rgb [3]uint8
+		for i, v := range match[1:] {
+			if value, err := parseComponent(v); err != nil {
+				return data.Color{}, err
+			} else {
+				rgb[i] = value
+			}
+		}
+		return data.Color{rgb[0], rgb[1], rgb[2]}, nil
+	}
+	return data.Color{}, data.ErrBadParameter.WithPrefix("Parse: ", strconv.Quote(value))
+}
+
 // Palette returns colors in palette which adhere to a given
 // set of swatches
 func Palette(data.ColorSwatch) []data.Color {
@@ -415,6 +453,24 @@ func YCbCr(c data.Color) color.YCbCr {
 	return color.YCbCr{Y, Cb, Cr}
 }
 
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// parseComponent returns a color component from a decimal value between
+// 0 and 255 or a percentage
+func parseComponent(value string) (uint8, error) {
+	scale := 1.0
+	if strings.HasSuffix(value, "%") {
+		value = strings.TrimSuffix(value, "%")
+		scale = 2.55
+	}
+	if v, err := strconv.ParseFloat(value, 32); err != nil {
+		return 0, err
+	} else {
+		return uint8(math.Min(math.Max(math.Round(v*scale), 0), 255)), nil
+	}
+}
+
 /////////////////////////////////////////////////////////////////////
 // SWATCH METHODS
 
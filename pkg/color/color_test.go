@@ -54,3 +54,25 @@ func Test_Color_004(t *testing.T) {
 		}
 	}
 }
+
+func Test_Color_005(t *testing.T) {
+	// Parse names, hashes and rgb() values
+	tests := map[string]data.Color{
+		"black":            color.Black,
+		"LightSteelBlue":   color.LightSteelBlue,
+		"#FF0000":          color.Red,
+		"#0f0":             color.Lime,
+		"rgb(0, 0, 255)":   color.Blue,
+		"rgb(100%,100%,0)": color.Yellow,
+	}
+	for value, expected := range tests {
+		if c, err := color.Parse(value); err != nil {
+			t.Error(err)
+		} else if c != expected {
+			t.Errorf("Unexpected return for %q: %v", value, c)
+		}
+	}
+	if _, err := color.Parse("nocolor"); err == nil {
+		t.Error("Expected error for invalid color")
+	}
+}
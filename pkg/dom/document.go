@@ -77,7 +77,11 @@ func (this *Document) GetElementById(value string) data.Node {
 	return this.GetElementByIdNS(value, "")
 }
 func (this *Document) GetElementByIdNS(value, ns string) data.Node {
-	return this.getAttrId(value, ns)
+	if element := this.getAttrId(value, ns); element != nil {
+		return element
+	} else {
+		return nil
+	}
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -188,15 +192,12 @@ func (this *Document) setTagNS(element *Element, ns string) string {
 }
 
 func (this *Document) newTagNS(element *Element, ns string) string {
-	var prefix string
-	if element.parent == nil {
-		prefix = ""
-	} else {
-		prefix = "ns"
-	}
-	if this.hasTagNS(prefix) == false {
-		return prefix
+	// The first element without a parent uses the default namespace
+	if element.parent == nil && this.hasTagNS("") == false {
+		return ""
 	}
+	// Use a well-known prefix for the namespace, or else "ns"
+	prefix := "ns"
 	if tag, exists := xmlNs[ns]; exists {
 		prefix = tag
 	}
@@ -211,6 +212,18 @@ func (this *Document) newTagNS(element *Element, ns string) string {
 	}
 }
 
+// declareTagNS sets the prefix for a namespace when it has not already
+// been registered, so prefixes declared in a document are retained
+func (this *Document) declareTagNS(ns, prefix string) {
+	if _, exists := this.tag[ns]; exists || ns == "" {
+		return
+	} else if prefix == "" || this.hasTagNS(prefix) {
+		return
+	} else {
+		this.tag[ns] = prefix
+	}
+}
+
 func (this *Document) hasTagNS(tag string) bool {
 	for _, v := range this.tag {
 		if tag == v {
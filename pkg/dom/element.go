@@ -2,7 +2,7 @@ package dom
 
 import (
 	"encoding/xml"
-	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -63,7 +63,8 @@ func (this *Element) Name() xml.Name {
 func (this *Element) Attrs() []xml.Attr {
 	attrs := make([]xml.Attr, 0, len(this.attro))
 
-	// Add namespace attributes onto the attributes for the root element
+	// Add namespace attributes onto the attributes for the root element,
+	// sorted by prefix so that output is stable
 	if this.IsRootElement() {
 		for ns, tag := range this.document.tag {
 			if tag != "" {
@@ -73,6 +74,9 @@ func (this *Element) Attrs() []xml.Attr {
 				})
 			}
 		}
+		sort.Slice(attrs, func(i, j int) bool {
+			return attrs[i].Name.Local < attrs[j].Name.Local
+		})
 	}
 
 	// Convert attribute names to shorten the namespace prefix
@@ -85,9 +89,10 @@ func (this *Element) Attrs() []xml.Attr {
 				// Let's just not clean up if there are errors
 				attrs = append(attrs, *attr)
 			} else {
-				// Or else append a new shortened tag with prefix
+				// Or else append a new shortened tag with prefix, where the
+				// namespace is declared on the root element
 				attrs = append(attrs, xml.Attr{
-					Name:  name,
+					Name:  xml.Name{Local: name.Local},
 					Value: attr.Value,
 				})
 			}
@@ -214,8 +219,7 @@ func (this *Element) SetAttrNS(name, ns string, value string) error {
 
 	// Register tag for ns
 	if ns != "" {
-		prefix := this.document.setTagNS(this, ns)
-		fmt.Println("TODO SET NS for ATTR", name, "=>", ns, "=>", prefix)
+		this.document.setTagNS(this, ns)
 	}
 
 	// Add attribute to order
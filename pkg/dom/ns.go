@@ -22,5 +22,7 @@ var (
 		"http://www.w3.org/2000/01/rdf-schema":       "rdf",
 		"http://www.w3.org/2001/SMIL20":              "smil",
 		"http://www.w3.org/1998/Math/MathML":         "m",
+		"http://purl.org/dc/elements/1.1/":           "dc",
+		"http://creativecommons.org/ns#":             "cc",
 	}
 )
@@ -3,7 +3,6 @@ package dom
 import (
 	"bytes"
 	"encoding/xml"
-	"fmt"
 	"io"
 	"strconv"
 
@@ -94,19 +93,31 @@ func (ctx *context) Pop() *Element {
 }
 
 func (ctx *context) StartElement(start xml.StartElement, node *Element) error {
-	// Namespace
-	fmt.Println("space=", start.Name.Space)
-
 	// Create element
 	if node == nil {
 		node = NewElementNS(start.Name.Local, start.Name.Space, nil, ctx.document)
 	} else {
 		node.XMLName = start.Name
+		if start.Name.Space != "" {
+			ctx.document.setTagNS(node, start.Name.Space)
+		}
+	}
+
+	// Retain namespace prefixes, which are declared on the root element
+	// when the document is written
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" {
+			ctx.document.declareTagNS(attr.Value, attr.Name.Local)
+		}
 	}
 
 	// Copy attributes
 	for _, attr := range start.Attr {
-		node.SetAttrNS(attr.Name.Local, attr.Name.Space, attr.Value)
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		} else if err := node.SetAttrNS(attr.Name.Local, attr.Name.Space, attr.Value); err != nil {
+			return err
+		}
 	}
 
 	// Add element to parent
@@ -0,0 +1,144 @@
+package font
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Font contains the metrics of a font, in font units
+type Font struct {
+	family                   string
+	variant                  data.FontVariant
+	units                    float32 // Units per em
+	ascent, descent, lineGap int16
+	capHeight, xHeight       int16
+	widths                   map[rune]uint16
+	width                    uint16 // Width of characters not in the font
+	kerning                  map[[2]rune]int16
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Ellipsis appended to truncated text
+	Ellipsis = "…"
+)
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Open reads the metrics of a TrueType or OpenType font file. For a
+// font collection, the first font in the collection is read
+func Open(path string) (data.Font, error) {
+	if fh, err := os.Open(path); err != nil {
+		return nil, err
+	} else {
+		defer fh.Close()
+		return Read(fh)
+	}
+}
+
+// Read reads the metrics of a TrueType or OpenType font from a data
+// stream
+func Read(r io.Reader) (data.Font, error) {
+	if buf, err := ioutil.ReadAll(r); err != nil {
+		return nil, err
+	} else if font, err := parse(buf); err != nil {
+		return nil, err
+	} else {
+		return font, nil
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// MeasureText returns the advance width of a string and the height
+// from the ascent to the descent of a font for a font size
+func MeasureText(font data.Font, size float32, value string) data.Size {
+	if font == nil {
+		font = Generic("serif", data.Regular)
+	}
+	metrics := font.Metrics(size)
+	return data.Size{font.Width(size, value), metrics.Ascent + metrics.Descent}
+}
+
+// Truncate returns a string which fits within a width for a font size,
+// replacing any characters which do not fit with an ellipsis. Returns
+// an empty string if the ellipsis does not fit
+func Truncate(font data.Font, size float32, value string, width float32) string {
+	if font == nil {
+		font = Generic("serif", data.Regular)
+	}
+	if font.Width(size, value) <= width {
+		return value
+	}
+	runes := []rune(value)
+	for i := len(runes) - 1; i >= 0; i-- {
+		if str := string(runes[:i]) + Ellipsis; font.Width(size, str) <= width {
+			return str
+		}
+	}
+	return ""
+}
+
+/////////////////////////////////////////////////////////////////////
+// FONT METHODS
+
+func (this *Font) Family() string {
+	return this.family
+}
+
+func (this *Font) Variant() data.FontVariant {
+	return this.variant
+}
+
+func (this *Font) Metrics(size float32) data.FontMetrics {
+	scale := size / this.units
+	return data.FontMetrics{
+		Ascent:    float32(this.ascent) * scale,
+		Descent:   float32(this.descent) * scale,
+		LineGap:   float32(this.lineGap) * scale,
+		CapHeight: float32(this.capHeight) * scale,
+		XHeight:   float32(this.xHeight) * scale,
+	}
+}
+
+func (this *Font) Width(size float32, value string) float32 {
+	var width int32
+	prev := rune(-1)
+	for _, r := range value {
+		if w, exists := this.widths[r]; exists {
+			width += int32(w)
+		} else {
+			width += int32(this.width)
+		}
+		if prev >= 0 && this.kerning != nil {
+			width += int32(this.kerning[[2]rune{prev, r}])
+		}
+		prev = r
+	}
+	return f32.Max(float32(width), 0) * size / this.units
+}
+
+/////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *Font) String() string {
+	str := "<font"
+	str += fmt.Sprintf(" family=%q", this.family)
+	if this.variant != 0 {
+		str += fmt.Sprintf(" variant=%v", this.variant)
+	}
+	str += fmt.Sprintf(" units=%v", this.units)
+	return str + ">"
+}
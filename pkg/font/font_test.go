@@ -0,0 +1,140 @@
+package font_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	font "github.com/djthorpe/data/pkg/font"
+)
+
+func Test_Font_001(t *testing.T) {
+	f := font.Generic("Arial", data.Regular)
+	if f == nil {
+		t.Fatal("Unexpected nil from Generic")
+	} else if f.Family() != "Helvetica" {
+		t.Error("Unexpected family, got: ", f.Family())
+	}
+	if w := f.Width(10, "AV"); w != 13.34 {
+		t.Error("Unexpected width, got: ", w)
+	}
+	if m := f.Metrics(10); m.Ascent != 9.05 || m.Descent != 2.12 {
+		t.Error("Unexpected metrics, got: ", m)
+	}
+	if f := font.Generic("unknown", data.Bold|data.Italic); f == nil || f.Family() != "Times" || f.Variant() != data.Bold|data.Italic {
+		t.Error("Unexpected fallback font, got: ", f)
+	}
+	if name, exists := font.StandardName("monospace", data.Oblique); name != "Courier-Oblique" || exists == false {
+		t.Error("Unexpected standard name, got: ", name)
+	}
+	if f := font.Standard("Symbol"); f == nil {
+		t.Error("Unexpected nil from Standard")
+	} else if f := font.Standard("Arial"); f != nil {
+		t.Error("Expected nil from Standard")
+	}
+}
+
+func Test_Font_002(t *testing.T) {
+	f := font.Generic("monospace", data.Regular)
+	if sz := font.MeasureText(f, 10, "Hello"); sz.W != 30 || sz.H != 11.33 {
+		t.Error("Unexpected size, got: ", sz)
+	}
+	if str := font.Truncate(f, 10, "Hello", 30); str != "Hello" {
+		t.Error("Unexpected truncation, got: ", str)
+	}
+	if str := font.Truncate(f, 10, "Hello, World", 30); str != "Hell"+font.Ellipsis {
+		t.Error("Unexpected truncation, got: ", str)
+	}
+	if str := font.Truncate(f, 10, "Hello", 5); str != "" {
+		t.Error("Unexpected truncation, got: ", str)
+	}
+}
+
+func Test_Font_003(t *testing.T) {
+	f, err := font.Read(bytes.NewReader(testFont()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Family() != "Test" {
+		t.Error("Unexpected family, got: ", f.Family())
+	}
+	if f.Variant() != data.Bold|data.Italic {
+		t.Error("Unexpected variant, got: ", f.Variant())
+	}
+	if m := f.Metrics(20); m.Ascent != 16 || m.Descent != 4 || m.LineGap != 2 {
+		t.Error("Unexpected metrics, got: ", m)
+	}
+
+	// A and B have glyphs, C uses the missing glyph and AB is kerned
+	if w := f.Width(20, "A"); w != 10 {
+		t.Error("Unexpected width, got: ", w)
+	}
+	if w := f.Width(20, "B"); w != 12 {
+		t.Error("Unexpected width, got: ", w)
+	}
+	if w := f.Width(20, "C"); w != 5 {
+		t.Error("Unexpected width, got: ", w)
+	}
+	if w := f.Width(20, "AB"); w != 21 {
+		t.Error("Unexpected width, got: ", w)
+	}
+	if w := f.Width(20, "BA"); w != 22 {
+		t.Error("Unexpected width, got: ", w)
+	}
+}
+
+func Test_Font_004(t *testing.T) {
+	for _, buf := range [][]byte{nil, []byte("not a font at all"), testFont()[:40]} {
+		if _, err := font.Read(bytes.NewReader(buf)); err == nil {
+			t.Error("Expected error for invalid font data")
+		}
+	}
+	if _, err := font.Open("nonexistent.ttf"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// TEST FONT
+
+// testFont returns a font with 1000 units per em and three glyphs: the
+// missing glyph and glyphs for A and B
+func testFont() []byte {
+	tables := map[string][]byte{
+		"head": be(make([]byte, 18), uint16(1000), make([]byte, 24), uint16(3), make([]byte, 8)),
+		"hhea": be(make([]byte, 4), int16(800), int16(-200), int16(100), make([]byte, 24), uint16(3)),
+		"maxp": be(uint32(0x5000), uint16(3)),
+		"hmtx": be(uint16(250), int16(0), uint16(500), int16(0), uint16(600), int16(0)),
+		"cmap": be(uint16(0), uint16(1), uint16(3), uint16(1), uint32(12),
+			// Format 4 with segments for A-B and the end segment
+			uint16(4), uint16(32), uint16(0), uint16(4), uint16(4), uint16(1), uint16(0),
+			uint16('B'), uint16(0xFFFF), uint16(0), uint16('A'), uint16(0xFFFF),
+			int16(1-'A'), int16(1), uint16(0), uint16(0)),
+		"name": be(uint16(0), uint16(1), uint16(18), uint16(1), uint16(0), uint16(0), uint16(1), uint16(4), uint16(0), []byte("Test")),
+		"kern": be(uint16(0), uint16(1), uint16(0), uint16(20), uint16(1), uint16(1), make([]byte, 6), uint16(1), uint16(2), int16(-50)),
+	}
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	offset := 12 + 16*len(tags)
+	header := be(uint32(0x00010000), uint16(len(tags)), make([]byte, 6))
+	var body []byte
+	for _, tag := range tags {
+		header = append(header, be([]byte(tag), uint32(0), uint32(offset+len(body)), uint32(len(tables[tag])))...)
+		body = append(body, tables[tag]...)
+	}
+	return append(header, body...)
+}
+
+// be returns values encoded as big-endian bytes
+func be(values ...interface{}) []byte {
+	buf := new(bytes.Buffer)
+	for _, value := range values {
+		binary.Write(buf, binary.BigEndian, value)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,377 @@
+package font
+
+import (
+	"encoding/binary"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// sfnt is the table directory of a TrueType or OpenType font
+type sfnt map[string][]byte
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Name identifiers for the family name
+	nameFamily            = 1
+	nameTypographicFamily = 16
+
+	// Flags in the OS/2 table
+	fsItalic         = 1 << 0
+	fsUseTypoMetrics = 1 << 7
+	fsOblique        = 1 << 9
+)
+
+var (
+	// Font weights in hundreds, from 100 to 900
+	weights = []data.FontVariant{
+		data.Thin, data.ExtraLight, data.Light, data.Regular, data.Medium,
+		data.SemiBold, data.Bold, data.ExtraBold, data.Black,
+	}
+)
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// parse returns the metrics for a font from the font data
+func parse(buf []byte) (*Font, error) {
+	tables, err := readTables(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for required tables
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap"} {
+		if _, exists := tables[tag]; exists == false {
+			return nil, data.ErrBadParameter.WithPrefix("font: Missing table ", strconv.Quote(tag))
+		}
+	}
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, data.ErrBadParameter.WithPrefix("font: Invalid table")
+	}
+
+	// Set vertical metrics
+	font := &Font{
+		units:   float32(u16(head, 18)),
+		ascent:  i16(hhea, 4),
+		descent: -i16(hhea, 6),
+		lineGap: i16(hhea, 8),
+	}
+	if font.units == 0 {
+		return nil, data.ErrBadParameter.WithPrefix("font: Invalid units per em")
+	}
+	font.capHeight, font.xHeight = int16(font.units*0.7), int16(font.units*0.5)
+
+	// Set variant from the style flags, or from the OS/2 table
+	if style := u16(head, 44); style&1 != 0 {
+		font.variant |= data.Bold
+	}
+	if style := u16(head, 44); style&2 != 0 {
+		font.variant |= data.Italic
+	}
+	if os2 := tables["OS/2"]; len(os2) >= 78 {
+		font.variant = weightVariant(u16(os2, 4))
+		selection := u16(os2, 62)
+		switch {
+		case selection&fsOblique != 0:
+			font.variant |= data.Oblique
+		case selection&fsItalic != 0:
+			font.variant |= data.Italic
+		}
+		if selection&fsUseTypoMetrics != 0 {
+			font.ascent, font.descent, font.lineGap = i16(os2, 68), -i16(os2, 70), i16(os2, 72)
+		}
+		if u16(os2, 0) >= 2 && len(os2) >= 90 && i16(os2, 86) > 0 && i16(os2, 88) > 0 {
+			font.xHeight, font.capHeight = i16(os2, 86), i16(os2, 88)
+		}
+	}
+
+	// Set family name
+	font.family = familyName(tables["name"])
+
+	// Map characters onto glyph advance widths
+	advances := glyphAdvances(tables["hmtx"], int(u16(hhea, 34)), int(u16(maxp, 4)))
+	if len(advances) == 0 {
+		return nil, data.ErrBadParameter.WithPrefix("font: Invalid horizontal metrics")
+	}
+	glyphs, err := characterMap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	font.width = advances[0]
+	font.widths = make(map[rune]uint16, len(glyphs))
+	for r, glyph := range glyphs {
+		if int(glyph) < len(advances) {
+			font.widths[r] = advances[glyph]
+		}
+	}
+
+	// Set kerning between pairs of characters
+	font.kerning = kerningPairs(tables["kern"], glyphs)
+
+	// Return success
+	return font, nil
+}
+
+// readTables returns the tables in a font, or the first font in a font
+// collection
+func readTables(buf []byte) (sfnt, error) {
+	if len(buf) < 12 {
+		return nil, data.ErrBadParameter.WithPrefix("font: Invalid font data")
+	}
+	offset := 0
+	if string(buf[0:4]) == "ttcf" {
+		if len(buf) < 16 {
+			return nil, data.ErrBadParameter.WithPrefix("font: Invalid font collection")
+		}
+		offset = int(u32(buf, 12))
+	}
+	if offset+12 > len(buf) {
+		return nil, data.ErrBadParameter.WithPrefix("font: Invalid font data")
+	}
+	switch version := string(buf[offset : offset+4]); version {
+	case "\x00\x01\x00\x00", "true", "OTTO":
+		break
+	default:
+		return nil, data.ErrBadParameter.WithPrefix("font: Unsupported font format ", strconv.Quote(version))
+	}
+
+	// Read table records
+	tables := make(sfnt)
+	count := int(u16(buf, offset+4))
+	for i := 0; i < count; i++ {
+		record := offset + 12 + i*16
+		if record+16 > len(buf) {
+			return nil, data.ErrBadParameter.WithPrefix("font: Invalid table directory")
+		}
+		tag := string(buf[record : record+4])
+		start, length := int(u32(buf, record+8)), int(u32(buf, record+12))
+		if start < 0 || length < 0 || start+length > len(buf) {
+			return nil, data.ErrBadParameter.WithPrefix("font: Invalid table ", strconv.Quote(tag))
+		}
+		tables[tag] = buf[start : start+length]
+	}
+
+	// Return success
+	return tables, nil
+}
+
+// glyphAdvances returns the advance width for each glyph
+func glyphAdvances(hmtx []byte, metrics, glyphs int) []uint16 {
+	if metrics*4 > len(hmtx) {
+		metrics = len(hmtx) / 4
+	}
+	if glyphs < metrics {
+		glyphs = metrics
+	}
+	advances := make([]uint16, glyphs)
+	for i := range advances {
+		if i < metrics {
+			advances[i] = u16(hmtx, i*4)
+		} else if metrics > 0 {
+			advances[i] = advances[metrics-1]
+		}
+	}
+	return advances
+}
+
+// characterMap returns the glyph for each character from the best
+// unicode subtable of the character map
+func characterMap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, data.ErrBadParameter.WithPrefix("font: Invalid character map")
+	}
+	best, score := -1, 0
+	for i := 0; i < int(u16(cmap, 2)); i++ {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			break
+		}
+		platform, encoding, offset := u16(cmap, record), u16(cmap, record+2), int(u32(cmap, record+4))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		s := 0
+		switch format := u16(cmap, offset); {
+		case format == 12 && (platform == 0 || (platform == 3 && encoding == 10)):
+			s = 3
+		case format == 4 && (platform == 0 || (platform == 3 && encoding == 1)):
+			s = 2
+		case format == 4 && platform == 3 && encoding == 0:
+			s = 1
+		}
+		if s > score {
+			best, score = offset, s
+		}
+	}
+	if best < 0 {
+		return nil, data.ErrBadParameter.WithPrefix("font: Missing unicode character map")
+	}
+
+	glyphs := make(map[rune]uint16)
+	table := cmap[best:]
+	switch u16(table, 0) {
+	case 4:
+		if len(table) < 14 {
+			return nil, data.ErrBadParameter.WithPrefix("font: Invalid character map")
+		}
+		segments := int(u16(table, 6)) / 2
+		ends, starts, deltas, ranges := 14, 16+segments*2, 16+segments*4, 16+segments*6
+		if ranges+segments*2 > len(table) {
+			return nil, data.ErrBadParameter.WithPrefix("font: Invalid character map")
+		}
+		for i := 0; i < segments; i++ {
+			end, start := int(u16(table, ends+i*2)), int(u16(table, starts+i*2))
+			delta, rangeOffset := u16(table, deltas+i*2), int(u16(table, ranges+i*2))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				glyph := uint16(0)
+				if rangeOffset == 0 {
+					glyph = uint16(c) + delta
+				} else if addr := ranges + i*2 + rangeOffset + (c-start)*2; addr+2 <= len(table) {
+					if glyph = u16(table, addr); glyph != 0 {
+						glyph += delta
+					}
+				}
+				if glyph != 0 {
+					glyphs[rune(c)] = glyph
+				}
+			}
+		}
+	case 12:
+		if len(table) < 16 {
+			return nil, data.ErrBadParameter.WithPrefix("font: Invalid character map")
+		}
+		for i := 0; i < int(u32(table, 12)); i++ {
+			group := 16 + i*12
+			if group+12 > len(table) {
+				break
+			}
+			start, end, glyph := u32(table, group), u32(table, group+4), u32(table, group+8)
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				if g := glyph + c - start; g != 0 && g <= 0xFFFF {
+					glyphs[rune(c)] = uint16(g)
+				}
+			}
+		}
+	}
+
+	// Return success
+	return glyphs, nil
+}
+
+// kerningPairs returns kerning adjustments between pairs of characters
+// from horizontal kerning subtables in format 0, or nil if there are none
+func kerningPairs(kern []byte, glyphs map[rune]uint16) map[[2]rune]int16 {
+	if len(kern) < 4 || u16(kern, 0) != 0 {
+		return nil
+	}
+
+	// Map glyphs onto characters
+	chars := make(map[uint16][]rune, len(glyphs))
+	for r, glyph := range glyphs {
+		chars[glyph] = append(chars[glyph], r)
+	}
+
+	pairs := make(map[[2]rune]int16)
+	offset := 4
+	for i := 0; i < int(u16(kern, 2)) && offset+6 <= len(kern); i++ {
+		length, coverage := int(u16(kern, offset+2)), u16(kern, offset+4)
+		if coverage>>8 == 0 && coverage&1 != 0 && offset+14 <= len(kern) {
+			for j := 0; j < int(u16(kern, offset+6)); j++ {
+				pair := offset + 14 + j*6
+				if pair+6 > len(kern) {
+					break
+				}
+				value := i16(kern, pair+4)
+				for _, left := range chars[u16(kern, pair)] {
+					for _, right := range chars[u16(kern, pair+2)] {
+						pairs[[2]rune{left, right}] = value
+					}
+				}
+			}
+		}
+		if length <= 0 {
+			break
+		}
+		offset += length
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	return pairs
+}
+
+// familyName returns the family name from the naming table, preferring
+// the typographic family name and names in unicode
+func familyName(name []byte) string {
+	if len(name) < 6 {
+		return ""
+	}
+	family, score := "", 0
+	strings := int(u16(name, 4))
+	for i := 0; i < int(u16(name, 2)); i++ {
+		record := 6 + i*12
+		if record+12 > len(name) {
+			break
+		}
+		platform, id := u16(name, record), u16(name, record+6)
+		length, offset := int(u16(name, record+8)), strings+int(u16(name, record+10))
+		if offset+length > len(name) || (id != nameFamily && id != nameTypographicFamily) {
+			continue
+		}
+		s, value := 0, ""
+		switch platform {
+		case 0, 3:
+			units := make([]uint16, length/2)
+			for j := range units {
+				units[j] = u16(name, offset+j*2)
+			}
+			s, value = 2, string(utf16.Decode(units))
+		case 1:
+			s, value = 1, string(name[offset:offset+length])
+		}
+		if id == nameTypographicFamily {
+			s += 2
+		}
+		if s > score && value != "" {
+			family, score = value, s
+		}
+	}
+	return family
+}
+
+// weightVariant returns the font variant for a weight between 1 and 1000
+func weightVariant(weight uint16) data.FontVariant {
+	i := (int(weight)+50)/100 - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(weights) {
+		i = len(weights) - 1
+	}
+	return weights[i]
+}
+
+func u16(buf []byte, offset int) uint16 {
+	if offset < 0 || offset+2 > len(buf) {
+		return 0
+	}
+	return binary.BigEndian.Uint16(buf[offset:])
+}
+
+func i16(buf []byte, offset int) int16 {
+	return int16(u16(buf, offset))
+}
+
+func u32(buf []byte, offset int) uint32 {
+	if offset < 0 || offset+4 > len(buf) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(buf[offset:])
+}
@@ -0,0 +1,191 @@
+package font
+
+import (
+	"strings"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+var (
+	// Widths of characters 32 to 126 for the standard fonts, in
+	// thousandths of the font size
+	widthsHelvetica = []uint16{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556,
+		278, 278, 584, 584, 584, 556, 1015,
+		667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611,
+		278, 278, 278, 469, 556, 333,
+		556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500,
+		334, 260, 334, 584,
+	}
+	widthsHelveticaBold = []uint16{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556,
+		333, 333, 584, 584, 584, 611, 975,
+		722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611,
+		333, 278, 333, 584, 556, 333,
+		556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611, 611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500,
+		389, 280, 389, 584,
+	}
+	widthsTimes = []uint16{
+		250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500,
+		278, 278, 564, 564, 564, 444, 921,
+		722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722, 556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611,
+		333, 278, 333, 469, 500, 333,
+		444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500, 500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444,
+		480, 200, 480, 541,
+	}
+	widthsTimesBold = []uint16{
+		250, 333, 555, 500, 500, 1000, 833, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500,
+		333, 333, 570, 570, 570, 500, 930,
+		722, 667, 722, 722, 667, 611, 778, 778, 389, 500, 778, 667, 944, 722, 778, 611, 778, 722, 556, 667, 722, 722, 1000, 722, 722, 667,
+		333, 278, 333, 581, 500, 333,
+		500, 556, 444, 556, 444, 333, 500, 556, 278, 333, 556, 278, 833, 556, 500, 556, 556, 444, 389, 333, 556, 500, 722, 500, 500, 444,
+		394, 220, 394, 520,
+	}
+	widthsTimesItalic = []uint16{
+		250, 333, 420, 500, 500, 833, 778, 214, 333, 333, 500, 675, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500,
+		333, 333, 675, 675, 675, 500, 920,
+		611, 611, 667, 722, 611, 611, 722, 722, 333, 444, 667, 556, 833, 667, 722, 611, 722, 611, 500, 556, 722, 611, 833, 611, 556, 556,
+		389, 278, 389, 422, 500, 333,
+		500, 500, 444, 500, 444, 278, 500, 500, 278, 278, 444, 278, 722, 500, 500, 500, 500, 389, 389, 278, 500, 444, 667, 444, 444, 389,
+		400, 275, 400, 541,
+	}
+
+	// Standard fonts which are available in all PDF readers, where
+	// oblique and bold-italic variants use the widths of the nearest
+	// variant. Vertical metrics are those of the equivalent system fonts
+	standard = map[string]*Font{
+		"Helvetica":             newStandard("Helvetica", 0, widthsHelvetica, 556, 905, 212, 33, 718, 523),
+		"Helvetica-Bold":        newStandard("Helvetica", data.Bold, widthsHelveticaBold, 556, 905, 212, 33, 718, 532),
+		"Helvetica-Oblique":     newStandard("Helvetica", data.Oblique, widthsHelvetica, 556, 905, 212, 33, 718, 523),
+		"Helvetica-BoldOblique": newStandard("Helvetica", data.Bold|data.Oblique, widthsHelveticaBold, 556, 905, 212, 33, 718, 532),
+		"Times-Roman":           newStandard("Times", 0, widthsTimes, 500, 891, 216, 42, 662, 450),
+		"Times-Bold":            newStandard("Times", data.Bold, widthsTimesBold, 500, 891, 216, 42, 676, 461),
+		"Times-Italic":          newStandard("Times", data.Italic, widthsTimesItalic, 500, 891, 216, 42, 653, 441),
+		"Times-BoldItalic":      newStandard("Times", data.Bold|data.Italic, widthsTimesBold, 500, 891, 216, 42, 669, 462),
+		"Courier":               newStandard("Courier", 0, nil, 600, 833, 300, 0, 562, 426),
+		"Courier-Bold":          newStandard("Courier", data.Bold, nil, 600, 833, 300, 0, 562, 439),
+		"Courier-Oblique":       newStandard("Courier", data.Oblique, nil, 600, 833, 300, 0, 562, 426),
+		"Courier-BoldOblique":   newStandard("Courier", data.Bold|data.Oblique, nil, 600, 833, 300, 0, 562, 439),
+		"Symbol":                newStandard("Symbol", 0, nil, 600, 1010, 293, 0, 673, 500),
+		"ZapfDingbats":          newStandard("ZapfDingbats", 0, nil, 600, 820, 143, 0, 700, 500),
+	}
+
+	// Font families mapped onto standard fonts
+	families = map[string]string{
+		"helvetica":       "Helvetica",
+		"arial":           "Helvetica",
+		"verdana":         "Helvetica",
+		"sans-serif":      "Helvetica",
+		"times":           "Times",
+		"times new roman": "Times",
+		"georgia":         "Times",
+		"serif":           "Times",
+		"courier":         "Courier",
+		"courier new":     "Courier",
+		"monospace":       "Courier",
+		"symbol":          "Symbol",
+		"zapfdingbats":    "ZapfDingbats",
+	}
+)
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Standard returns the metrics for one of the standard PDF fonts by
+// name (for example, "Helvetica-Bold"), or nil if the name is not a
+// standard font
+func Standard(name string) data.Font {
+	if font, exists := standard[name]; exists {
+		return font
+	} else {
+		return nil
+	}
+}
+
+// Generic returns the metrics for a generic font family (serif,
+// sans-serif or monospace) or a common font family such as Arial,
+// using bundled metrics. Unknown families use the serif metrics
+func Generic(family string, variant data.FontVariant) data.Font {
+	name, _ := StandardName(family, variant)
+	return standard[name]
+}
+
+// StandardName returns the name of the standard font which best matches
+// a font family and variant, and false if the family is not a generic
+// or common font family, in which case the serif font is returned
+func StandardName(family string, variant data.FontVariant) (string, bool) {
+	family, exists := families[strings.ToLower(strings.Trim(strings.TrimSpace(family), "\"'"))]
+	if exists == false {
+		family = "Times"
+	}
+	bold := isBold(variant)
+	italic := isItalic(variant)
+	switch family {
+	case "Symbol", "ZapfDingbats":
+		return family, exists
+	case "Times":
+		switch {
+		case bold && italic:
+			return "Times-BoldItalic", exists
+		case bold:
+			return "Times-Bold", exists
+		case italic:
+			return "Times-Italic", exists
+		default:
+			return "Times-Roman", exists
+		}
+	default:
+		switch {
+		case bold && italic:
+			return family + "-BoldOblique", exists
+		case bold:
+			return family + "-Bold", exists
+		case italic:
+			return family + "-Oblique", exists
+		default:
+			return family, exists
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// newStandard returns a font with widths for characters 32 to 126
+// and vertical metrics in thousandths of the font size
+func newStandard(family string, variant data.FontVariant, widths []uint16, width uint16, ascent, descent, lineGap, capHeight, xHeight int16) *Font {
+	font := &Font{
+		family:    family,
+		variant:   variant,
+		units:     1000,
+		ascent:    ascent,
+		descent:   descent,
+		lineGap:   lineGap,
+		capHeight: capHeight,
+		xHeight:   xHeight,
+		widths:    make(map[rune]uint16, len(widths)),
+		width:     width,
+	}
+	for i, w := range widths {
+		font.widths[rune(32+i)] = w
+	}
+	return font
+}
+
+// isBold returns true if a variant has a weight of 600 or above
+func isBold(variant data.FontVariant) bool {
+	return variant&(data.SemiBold|data.Bold|data.ExtraBold|data.Black|data.Bolder) != 0
+}
+
+// isItalic returns true if a variant is italic or oblique
+func isItalic(variant data.FontVariant) bool {
+	return variant&(data.Italic|data.Oblique) != 0
+}
@@ -0,0 +1,545 @@
+package geom
+
+import (
+	"math"
+	"sort"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Operation is a boolean operation on two sets of polygons
+type Operation uint
+
+// vertex is a point with double precision, so that intersections
+// between edges are exact enough to join edges into polygons
+type vertex struct {
+	x, y float64
+}
+
+// boolEdge is an edge of a polygon in one of the two sets of polygons
+type boolEdge struct {
+	a, b  vertex
+	owner int // Zero for the subject and one for the clipping polygons
+	group int // Edges in the same group have the same end points
+}
+
+/////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	OpUnion Operation = iota
+	OpIntersection
+	OpDifference
+	OpXor
+)
+
+const (
+	// Distance within which a vertex is on an edge, relative to the
+	// extent of the polygons
+	booleanTolerance = 1e-6
+
+	// Maximum number of times edges are split
+	booleanPasses = 64
+)
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// Union returns the polygons covering the area inside either set of
+// polygons. Overlapping polygons within a single set are merged when
+// the other set is empty
+func Union(a, b [][]data.Point, rule data.FillRule) [][]data.Point {
+	return Boolean(OpUnion, a, b, rule)
+}
+
+// Intersection returns the polygons covering the area inside both sets
+// of polygons
+func Intersection(a, b [][]data.Point, rule data.FillRule) [][]data.Point {
+	return Boolean(OpIntersection, a, b, rule)
+}
+
+// Difference returns the polygons covering the area inside the first
+// set of polygons and outside the second set
+func Difference(a, b [][]data.Point, rule data.FillRule) [][]data.Point {
+	return Boolean(OpDifference, a, b, rule)
+}
+
+// Xor returns the polygons covering the area inside exactly one of
+// the two sets of polygons
+func Xor(a, b [][]data.Point, rule data.FillRule) [][]data.Point {
+	return Boolean(OpXor, a, b, rule)
+}
+
+// Boolean returns the result of an operation on two sets of polygons,
+// where the inside of each set is determined by the fill rule. The
+// resulting polygons do not overlap: outer boundaries are clockwise and
+// holes are anti-clockwise where the y axis points down, so that they
+// can be filled with either fill rule
+func Boolean(op Operation, a, b [][]data.Point, rule data.FillRule) [][]data.Point {
+	edges := boolEdges(a, b)
+	if len(edges) == 0 {
+		return [][]data.Point{}
+	}
+	edges = splitEdges(edges, boolTolerance(a, b))
+	return joinEdges(classifyEdges(op, edges, rule))
+}
+
+/////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (op Operation) String() string {
+	switch op {
+	case OpUnion:
+		return "OpUnion"
+	case OpIntersection:
+		return "OpIntersection"
+	case OpDifference:
+		return "OpDifference"
+	case OpXor:
+		return "OpXor"
+	default:
+		return "[?? Invalid Operation value]"
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// boolEdges returns the edges of both sets of polygons, ignoring edges
+// with zero length
+func boolEdges(a, b [][]data.Point) []boolEdge {
+	edges := []boolEdge{}
+	for owner, polys := range [][][]data.Point{a, b} {
+		for _, poly := range polys {
+			for i := range poly {
+				p0, p1 := poly[i], poly[(i+1)%len(poly)]
+				if p0 != p1 {
+					edges = append(edges, boolEdge{a: newVertex(p0), b: newVertex(p1), owner: owner})
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// boolTolerance returns the distance within which a vertex is on an edge
+func boolTolerance(a, b [][]data.Point) float64 {
+	var min, max vertex
+	first := true
+	for _, polys := range [][][]data.Point{a, b} {
+		for _, poly := range polys {
+			for _, pt := range poly {
+				v := newVertex(pt)
+				if first {
+					min, max, first = v, v, false
+				} else {
+					min = vertex{math.Min(min.x, v.x), math.Min(min.y, v.y)}
+					max = vertex{math.Max(max.x, v.x), math.Max(max.y, v.y)}
+				}
+			}
+		}
+	}
+	return math.Max(math.Max(max.x-min.x, max.y-min.y), 1) * booleanTolerance
+}
+
+// splitEdges returns edges split wherever they intersect or touch
+// another edge, so that edges only meet at their end points. Points
+// within the tolerance of each other are merged, which may cause new
+// intersections, so edges are split until no further splits are made
+func splitEdges(edges []boolEdge, tolerance float64) []boolEdge {
+	snap := newVertexSnap(tolerance)
+	for _, e := range edges {
+		snap.add(e.a)
+	}
+	for i := 0; i < booleanPasses; i++ {
+		splits, changed := edgeSplits(edges, snap, tolerance)
+		if changed == false {
+			break
+		}
+		result := make([]boolEdge, 0, len(edges))
+		for i, e := range edges {
+			pts := splits[i]
+			sort.Slice(pts, func(i, j int) bool {
+				return e.param(pts[i]) < e.param(pts[j])
+			})
+			a := e.a
+			for _, v := range append(pts, e.b) {
+				if v != a {
+					result = append(result, boolEdge{a: a, b: v, owner: e.owner})
+					a = v
+				}
+			}
+		}
+		edges = result
+	}
+
+	// Group edges with the same end points
+	groups := make(map[[4]float64]int)
+	for i, e := range edges {
+		key := e.key()
+		if group, exists := groups[key]; exists {
+			edges[i].group = group
+		} else {
+			groups[key] = len(groups)
+			edges[i].group = groups[key]
+		}
+	}
+
+	// Return split edges
+	return edges
+}
+
+// edgeSplits returns the points at which each edge is split, and false
+// if no edges are split
+func edgeSplits(edges []boolEdge, snap *vertexSnap, tolerance float64) ([][]vertex, bool) {
+	// Order edges by their left-most point so that only edges which
+	// overlap horizontally are compared
+	order := make([]int, len(edges))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return math.Min(edges[order[i]].a.x, edges[order[i]].b.x) < math.Min(edges[order[j]].a.x, edges[order[j]].b.x)
+	})
+
+	// Determine the points at which each edge is split
+	splits := make([][]vertex, len(edges))
+	changed := false
+	for i, ei := range order {
+		e := edges[ei]
+		maxx := math.Max(e.a.x, e.b.x) + tolerance
+		for _, fi := range order[i+1:] {
+			f := edges[fi]
+			if math.Min(f.a.x, f.b.x) > maxx {
+				break
+			}
+			if math.Min(e.a.y, e.b.y) > math.Max(f.a.y, f.b.y)+tolerance || math.Min(f.a.y, f.b.y) > math.Max(e.a.y, e.b.y)+tolerance {
+				continue
+			}
+			touches := false
+			for _, v := range []vertex{f.a, f.b} {
+				if e.touches(v, tolerance) {
+					splits[ei], touches = append(splits[ei], v), true
+				}
+			}
+			for _, v := range []vertex{e.a, e.b} {
+				if f.touches(v, tolerance) {
+					splits[fi], touches = append(splits[fi], v), true
+				}
+			}
+			if touches == false {
+				if v, ok := e.intersects(f, tolerance); ok {
+					v = snap.add(v)
+					if v != e.a && v != e.b {
+						splits[ei], touches = append(splits[ei], v), true
+					}
+					if v != f.a && v != f.b {
+						splits[fi], touches = append(splits[fi], v), true
+					}
+				}
+			}
+			changed = changed || touches
+		}
+	}
+	return splits, changed
+}
+
+// classifyEdges returns the edges which separate the inside of the
+// result of an operation from the outside, directed so that the inside
+// is on the right where the y axis points down
+func classifyEdges(op Operation, edges []boolEdge, rule data.FillRule) []boolEdge {
+	index := newEdgeIndex(edges)
+	result := []boolEdge{}
+	done := make(map[int]bool)
+	for _, e := range edges {
+		if done[e.group] {
+			continue
+		}
+		done[e.group] = true
+
+		// The winding number is determined on one side of the edge by
+		// casting a ray from the middle of the edge, ignoring edges in
+		// the same group. The winding number on the other side differs
+		// by the winding of the edges in the group
+		mid := vertex{(e.a.x + e.b.x) / 2, (e.a.y + e.b.y) / 2}
+		dx, dy := e.b.x-e.a.x, e.b.y-e.a.y
+		right := dy < 0 || (dy == 0 && dx > 0)
+		var inside [2][2]bool // Left and right of the edge for each set
+		for owner := 0; owner <= 1; owner++ {
+			winding, delta := 0, 0
+			for _, f := range index.row(mid.y) {
+				if f.owner == owner && f.group != e.group {
+					winding += f.winding(mid)
+				}
+			}
+			for _, f := range index.groups[e.group] {
+				if f.owner != owner {
+					continue
+				} else if f.a == e.a {
+					delta++
+				} else {
+					delta--
+				}
+			}
+			if right {
+				inside[owner] = [2]bool{fillRule(winding-delta, rule), fillRule(winding, rule)}
+			} else {
+				inside[owner] = [2]bool{fillRule(winding, rule), fillRule(winding+delta, rule)}
+			}
+		}
+
+		// Keep the edge when the result differs on either side
+		if l, r := op.apply(inside[0][0], inside[1][0]), op.apply(inside[0][1], inside[1][1]); l == r {
+			continue
+		} else if r {
+			result = append(result, boolEdge{a: e.a, b: e.b})
+		} else {
+			result = append(result, boolEdge{a: e.b, b: e.a})
+		}
+	}
+	return result
+}
+
+// joinEdges returns polygons by following directed edges from one end
+// point to the next, turning as far right as possible where more than
+// one edge starts at a point so that polygons which touch are separate
+func joinEdges(edges []boolEdge) [][]data.Point {
+	starts := make(map[vertex][]int)
+	for i, e := range edges {
+		starts[e.a] = append(starts[e.a], i)
+	}
+	used := make([]bool, len(edges))
+	result := [][]data.Point{}
+	for i := range edges {
+		if used[i] {
+			continue
+		}
+		ring := []vertex{edges[i].a}
+		used[i] = true
+		for e := edges[i]; e.b != edges[i].a; {
+			next, turn := -1, -math.MaxFloat64
+			for _, j := range starts[e.b] {
+				if used[j] {
+					continue
+				}
+				f := edges[j]
+				dx0, dy0 := e.b.x-e.a.x, e.b.y-e.a.y
+				dx1, dy1 := f.b.x-f.a.x, f.b.y-f.a.y
+				if angle := math.Atan2(dx0*dy1-dy0*dx1, dx0*dx1+dy0*dy1); angle > turn {
+					next, turn = j, angle
+				}
+			}
+			if next < 0 {
+				break
+			}
+			used[next] = true
+			ring = append(ring, e.b)
+			e = edges[next]
+		}
+		if poly := simplifyRing(ring); len(poly) >= 3 {
+			result = append(result, poly)
+		}
+	}
+	return result
+}
+
+// simplifyRing returns the points of a ring without points where the
+// ring continues in a straight line
+func simplifyRing(ring []vertex) []data.Point {
+	result := make([]data.Point, 0, len(ring))
+	for i, v := range ring {
+		prev, next := ring[(i+len(ring)-1)%len(ring)], ring[(i+1)%len(ring)]
+		dx0, dy0 := v.x-prev.x, v.y-prev.y
+		dx1, dy1 := next.x-v.x, next.y-v.y
+		if dx0*dy1-dy0*dx1 == 0 && dx0*dx1+dy0*dy1 > 0 {
+			continue
+		}
+		result = append(result, data.Point{float32(v.x), float32(v.y)})
+	}
+	if Area(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// apply returns whether a point is inside the result of an operation
+func (op Operation) apply(a, b bool) bool {
+	switch op {
+	case OpUnion:
+		return a || b
+	case OpIntersection:
+		return a && b
+	case OpDifference:
+		return a && !b
+	case OpXor:
+		return a != b
+	default:
+		return false
+	}
+}
+
+// fillRule returns true if a winding number is inside according to a
+// fill rule
+func fillRule(winding int, rule data.FillRule) bool {
+	if rule == data.EvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+/////////////////////////////////////////////////////////////////////
+// EDGE INDEX
+
+// edgeIndex divides edges into horizontal rows, so that only the edges
+// which span a row are tested when casting a ray, and into groups of
+// edges with the same end points
+type edgeIndex struct {
+	min, height float64
+	rows        [][]boolEdge
+	groups      map[int][]boolEdge
+}
+
+func newEdgeIndex(edges []boolEdge) *edgeIndex {
+	this := &edgeIndex{min: math.Inf(1), groups: make(map[int][]boolEdge)}
+	max := math.Inf(-1)
+	for _, e := range edges {
+		this.min = math.Min(this.min, math.Min(e.a.y, e.b.y))
+		max = math.Max(max, math.Max(e.a.y, e.b.y))
+		this.groups[e.group] = append(this.groups[e.group], e)
+	}
+	this.rows = make([][]boolEdge, int(math.Sqrt(float64(len(edges))))+1)
+	this.height = (max - this.min) / float64(len(this.rows))
+	for _, e := range edges {
+		for i, j := this.index(math.Min(e.a.y, e.b.y)), this.index(math.Max(e.a.y, e.b.y)); i <= j; i++ {
+			this.rows[i] = append(this.rows[i], e)
+		}
+	}
+	return this
+}
+
+// row returns the edges which may span a y co-ordinate
+func (this *edgeIndex) row(y float64) []boolEdge {
+	return this.rows[this.index(y)]
+}
+
+// index returns the row for a y co-ordinate
+func (this *edgeIndex) index(y float64) int {
+	if this.height <= 0 {
+		return 0
+	} else if i := int((y - this.min) / this.height); i < 0 {
+		return 0
+	} else if i >= len(this.rows) {
+		return len(this.rows) - 1
+	} else {
+		return i
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+// VERTEX SNAP
+
+// vertexSnap merges vertices which are within a distance of each other,
+// by dividing vertices into square cells
+type vertexSnap struct {
+	size  float64
+	cells map[[2]int64][]vertex
+}
+
+func newVertexSnap(size float64) *vertexSnap {
+	return &vertexSnap{size, make(map[[2]int64][]vertex)}
+}
+
+// add returns an existing vertex within the distance of a vertex, or
+// adds the vertex and returns it
+func (this *vertexSnap) add(v vertex) vertex {
+	x, y := int64(math.Floor(v.x/this.size)), int64(math.Floor(v.y/this.size))
+	for i := x - 1; i <= x+1; i++ {
+		for j := y - 1; j <= y+1; j++ {
+			for _, w := range this.cells[[2]int64{i, j}] {
+				if math.Hypot(v.x-w.x, v.y-w.y) <= this.size {
+					return w
+				}
+			}
+		}
+	}
+	this.cells[[2]int64{x, y}] = append(this.cells[[2]int64{x, y}], v)
+	return v
+}
+
+/////////////////////////////////////////////////////////////////////
+// EDGE METHODS
+
+func newVertex(pt data.Point) vertex {
+	return vertex{float64(pt.X), float64(pt.Y)}
+}
+
+// key returns the end points of an edge regardless of direction
+func (e boolEdge) key() [4]float64 {
+	if e.a.x < e.b.x || (e.a.x == e.b.x && e.a.y < e.b.y) {
+		return [4]float64{e.a.x, e.a.y, e.b.x, e.b.y}
+	}
+	return [4]float64{e.b.x, e.b.y, e.a.x, e.a.y}
+}
+
+// param returns the position of a point along an edge, between zero
+// and one
+func (e boolEdge) param(v vertex) float64 {
+	dx, dy := e.b.x-e.a.x, e.b.y-e.a.y
+	return ((v.x-e.a.x)*dx + (v.y-e.a.y)*dy) / (dx*dx + dy*dy)
+}
+
+// touches returns true if a vertex is on an edge, excluding the end
+// points of the edge
+func (e boolEdge) touches(v vertex, tolerance float64) bool {
+	if v == e.a || v == e.b {
+		return false
+	}
+	dx, dy := e.b.x-e.a.x, e.b.y-e.a.y
+	length := math.Hypot(dx, dy)
+	if math.Abs(dx*(v.y-e.a.y)-dy*(v.x-e.a.x))/length > tolerance {
+		return false
+	}
+	t := e.param(v) * length
+	return t > tolerance && t < length-tolerance
+}
+
+// intersects returns the point at which two edges cross, excluding
+// their end points
+func (e boolEdge) intersects(f boolEdge, tolerance float64) (vertex, bool) {
+	rx, ry := e.b.x-e.a.x, e.b.y-e.a.y
+	sx, sy := f.b.x-f.a.x, f.b.y-f.a.y
+	denom := rx*sy - ry*sx
+	if denom == 0 {
+		return vertex{}, false
+	}
+	qx, qy := f.a.x-e.a.x, f.a.y-e.a.y
+	t := (qx*sy - qy*sx) / denom
+	u := (qx*ry - qy*rx) / denom
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return vertex{}, false
+	}
+	v := vertex{e.a.x + t*rx, e.a.y + t*ry}
+	for _, end := range []vertex{e.a, e.b, f.a, f.b} {
+		if math.Hypot(v.x-end.x, v.y-end.y) <= tolerance {
+			return vertex{}, false
+		}
+	}
+	return v, true
+}
+
+// winding returns the contribution of an edge to the winding number
+// around a point, casting a ray in the direction of the x axis
+func (e boolEdge) winding(v vertex) int {
+	side := (e.b.x-e.a.x)*(v.y-e.a.y) - (v.x-e.a.x)*(e.b.y-e.a.y)
+	if e.a.y <= v.y {
+		if e.b.y > v.y && side > 0 {
+			return 1
+		}
+	} else if e.b.y <= v.y && side < 0 {
+		return -1
+	}
+	return 0
+}
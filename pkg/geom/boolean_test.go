@@ -0,0 +1,113 @@
+package geom_test
+
+import (
+	"testing"
+
+	data "github.com/djthorpe/data"
+	geom "github.com/djthorpe/data/pkg/geom"
+)
+
+func Test_Boolean_001(t *testing.T) {
+	a := [][]data.Point{{{0, 0}, {20, 0}, {20, 20}, {0, 20}}}
+	b := [][]data.Point{{{10, 10}, {30, 10}, {30, 30}, {10, 30}}}
+	tests := []struct {
+		op   geom.Operation
+		n    int
+		area float32
+	}{
+		{geom.OpUnion, 1, 700},
+		{geom.OpIntersection, 1, 100},
+		{geom.OpDifference, 1, 300},
+		{geom.OpXor, 2, 600},
+	}
+	for _, test := range tests {
+		result := geom.Boolean(test.op, a, b, data.NonZero)
+		if len(result) != test.n {
+			t.Error(test.op, ": Unexpected number of polygons, got: ", result)
+		}
+		area := float32(0)
+		for _, poly := range result {
+			area += geom.Area(poly)
+		}
+		if area != test.area {
+			t.Error(test.op, ": Unexpected area, got: ", area)
+		}
+	}
+}
+
+func Test_Boolean_002(t *testing.T) {
+	// Squares which share an edge are merged
+	a := [][]data.Point{{{0, 0}, {20, 0}, {20, 20}, {0, 20}}}
+	b := [][]data.Point{{{20, 0}, {40, 0}, {40, 20}, {20, 20}}}
+	if result := geom.Union(a, b, data.NonZero); len(result) != 1 || len(result[0]) != 4 || geom.Area(result[0]) != 800 {
+		t.Error("Unexpected result, got: ", result)
+	}
+
+	// Squares which touch at a corner remain separate
+	c := [][]data.Point{{{20, 20}, {40, 20}, {40, 40}, {20, 40}}}
+	if result := geom.Union(a, c, data.NonZero); len(result) != 2 {
+		t.Error("Unexpected result, got: ", result)
+	}
+	if result := geom.Intersection(a, c, data.NonZero); len(result) != 0 {
+		t.Error("Unexpected result, got: ", result)
+	}
+
+	// Direction of the polygons does not affect the result
+	d := [][]data.Point{{{0, 0}, {0, 20}, {20, 20}, {20, 0}}}
+	if result := geom.Intersection(d, [][]data.Point{{{10, 10}, {30, 10}, {30, 30}, {10, 30}}}, data.NonZero); len(result) != 1 || geom.Area(result[0]) != 100 {
+		t.Error("Unexpected result, got: ", result)
+	}
+}
+
+func Test_Boolean_003(t *testing.T) {
+	// A square with a hole with the even-odd rule, or without a hole
+	// with the non-zero rule
+	a := [][]data.Point{{{0, 0}, {30, 0}, {30, 30}, {0, 30}}, {{10, 10}, {20, 10}, {20, 20}, {10, 20}}}
+	if result := geom.Union(a, nil, data.EvenOdd); len(result) != 2 {
+		t.Error("Unexpected result, got: ", result)
+	} else if geom.Area(result[0]) != 900 || geom.Area(result[1]) != -100 {
+		t.Error("Unexpected result, got: ", result)
+	} else if geom.Contains(data.Point{15, 15}, data.NonZero, result...) || geom.Contains(data.Point{15, 15}, data.EvenOdd, result...) {
+		t.Error("Expected point in the hole to be outside")
+	}
+	if result := geom.Union(a, nil, data.NonZero); len(result) != 1 || geom.Area(result[0]) != 900 {
+		t.Error("Unexpected result, got: ", result)
+	}
+
+	// Cutting a hole through a square
+	b := [][]data.Point{{{10, 10}, {20, 10}, {20, 20}, {10, 20}}}
+	if result := geom.Difference(a[:1], b, data.NonZero); len(result) != 2 {
+		t.Error("Unexpected result, got: ", result)
+	} else if geom.Area(result[0])+geom.Area(result[1]) != 800 {
+		t.Error("Unexpected result, got: ", result)
+	}
+}
+
+func Test_Boolean_004(t *testing.T) {
+	// Compare the result with the operation on points in a grid, for
+	// self-intersecting polygons with both fill rules
+	a := [][]data.Point{{{0, 0}, {20, 20}, {20, 0}, {0, 20}}, {{5, 2}, {15, 2}, {10, 18}}}
+	b := [][]data.Point{{{10, -5}, {25, 10}, {10, 25}, {-5, 10}}, {{2, 10}, {10, 3}, {18, 10}, {10, 17}}}
+	for _, rule := range []data.FillRule{data.NonZero, data.EvenOdd} {
+		for op := geom.OpUnion; op <= geom.OpXor; op++ {
+			result := geom.Boolean(op, a, b, rule)
+			for x := float32(-4.9); x < 25; x += 1.3 {
+				for y := float32(-4.9); y < 25; y += 1.3 {
+					pt := data.Point{x, y}
+					ia, ib := geom.Contains(pt, rule, a...), geom.Contains(pt, rule, b...)
+					expected := map[geom.Operation]bool{
+						geom.OpUnion:        ia || ib,
+						geom.OpIntersection: ia && ib,
+						geom.OpDifference:   ia && !ib,
+						geom.OpXor:          ia != ib,
+					}[op]
+					if w := geom.Winding(pt, result...); w != 0 && w != 1 {
+						t.Error(op, rule, ": Unexpected winding at ", pt, ": ", w)
+					} else if (w == 1) != expected {
+						t.Error(op, rule, ": Unexpected result at ", pt)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package geom
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Matrix is a 2D affine transform in the form [a b c d e f] which maps
+// a point (x,y) onto (ax + cy + e, bx + dy + f), in the same order as
+// the SVG matrix() transform
+type Matrix [6]float32
+
+/////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+var (
+	IdentityMatrix = Matrix{1, 0, 0, 1, 0, 0}
+)
+
+var (
+	reTransform = regexp.MustCompile(`^\s*([a-zA-Z]+)\s*\(([^)]*)\)[\s,]*`)
+	reNumber    = regexp.MustCompile(`^[\s,]*([+-]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][+-]?[0-9]+)?)`)
+)
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Return a transform which translates by a point
+func TranslateMatrix(pt data.Point) Matrix {
+	return Matrix{1, 0, 0, 1, pt.X, pt.Y}
+}
+
+// Return a transform which scales by a size
+func ScaleMatrix(sz data.Size) Matrix {
+	return Matrix{sz.W, 0, 0, sz.H, 0, 0}
+}
+
+// Return a transform which rotates clockwise by an angle in degrees
+func RotateMatrix(deg float32) Matrix {
+	rad := float64(deg) * math.Pi / 180
+	sin, cos := float32(math.Sin(rad)), float32(math.Cos(rad))
+	return Matrix{cos, sin, -sin, cos, 0, 0}
+}
+
+// Return a transform which skews along the x axis by an angle in degrees
+func SkewXMatrix(deg float32) Matrix {
+	return Matrix{1, 0, float32(math.Tan(float64(deg) * math.Pi / 180)), 1, 0, 0}
+}
+
+// Return a transform which skews along the y axis by an angle in degrees
+func SkewYMatrix(deg float32) Matrix {
+	return Matrix{1, float32(math.Tan(float64(deg) * math.Pi / 180)), 0, 1, 0, 0}
+}
+
+// ParseTransform returns a transform from a list of SVG transform
+// functions such as "translate(10,10) rotate(45)". The functions matrix,
+// translate, scale, rotate, skewX and skewY are supported
+func ParseTransform(value string) (Matrix, error) {
+	m := IdentityMatrix
+	for value = strings.TrimSpace(value); value != ""; {
+		op := reTransform.FindStringSubmatch(value)
+		if op == nil {
+			return IdentityMatrix, data.ErrBadParameter.WithPrefix("Invalid transform: ", strconv.Quote(value))
+		}
+		value = value[len(op[0]):]
+		args, err := parseNumbers(op[2])
+		if err != nil {
+			return IdentityMatrix, data.ErrBadParameter.WithPrefix("Invalid transform: ", strconv.Quote(strings.TrimSpace(op[0])))
+		}
+		n, ok := transformFunction(strings.ToLower(op[1]), args)
+		if ok == false {
+			return IdentityMatrix, data.ErrBadParameter.WithPrefix("Invalid transform: ", strconv.Quote(strings.TrimSpace(op[0])))
+		}
+		m = m.Multiply(n)
+	}
+
+	// Return success
+	return m, nil
+}
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// Multiply returns the transform which applies n and then m
+func (m Matrix) Multiply(n Matrix) Matrix {
+	return Matrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+// Invert returns the inverse transform, or false if the transform
+// cannot be inverted
+func (m Matrix) Invert() (Matrix, bool) {
+	det := m.Determinant()
+	if det == 0 || f32.IsNaN(det) {
+		return IdentityMatrix, false
+	}
+	return Matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}, true
+}
+
+// Apply returns the transformed point
+func (m Matrix) Apply(pt data.Point) data.Point {
+	return data.Point{
+		X: m[0]*pt.X + m[2]*pt.Y + m[4],
+		Y: m[1]*pt.X + m[3]*pt.Y + m[5],
+	}
+}
+
+// ApplySize returns a size transformed as a vector, so that translation
+// is not applied and the result may be negative
+func (m Matrix) ApplySize(sz data.Size) data.Size {
+	return data.Size{
+		W: m[0]*sz.W + m[2]*sz.H,
+		H: m[1]*sz.W + m[3]*sz.H,
+	}
+}
+
+// Determinant returns the scaling of area by the transform, which is
+// negative when the transform is a reflection
+func (m Matrix) Determinant() float32 {
+	return m[0]*m[3] - m[1]*m[2]
+}
+
+// IsIdentity returns true if the transform has no effect
+func (m Matrix) IsIdentity() bool {
+	return m == IdentityMatrix
+}
+
+// Decompose returns the translation, rotation in degrees, scale and
+// skew along the x axis in degrees, which when applied in the order
+// translate, rotate, skewX and scale compose the transform. A reflection
+// results in a negative vertical scale
+func (m Matrix) Decompose() (data.Point, float32, data.Size, float32) {
+	translate := data.Point{m[4], m[5]}
+	sx := f32.Sqrt(m[0]*m[0] + m[1]*m[1])
+	if sx == 0 {
+		return translate, 0, data.Size{0, f32.Sqrt(m[2]*m[2] + m[3]*m[3])}, 0
+	}
+	rad := math.Atan2(float64(m[1]), float64(m[0]))
+	sin, cos := float32(math.Sin(rad)), float32(math.Cos(rad))
+	sy := cos*m[3] - sin*m[2]
+	if sy == 0 {
+		return translate, float32(rad * 180 / math.Pi), data.Size{sx, 0}, 0
+	}
+	skew := math.Atan(float64((cos*m[2] + sin*m[3]) / sy))
+	return translate, float32(rad * 180 / math.Pi), data.Size{sx, sy}, float32(skew * 180 / math.Pi)
+}
+
+/////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (m Matrix) String() string {
+	return "matrix(" + f32.Join(m[:], ",") + ")"
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// transformFunction returns the transform for a transform function
+// and arguments, or false if the arguments are invalid
+func transformFunction(name string, args []float32) (Matrix, bool) {
+	switch {
+	case name == "matrix" && len(args) == 6:
+		return Matrix{args[0], args[1], args[2], args[3], args[4], args[5]}, true
+	case name == "translate" && len(args) == 1:
+		return TranslateMatrix(data.Point{args[0], 0}), true
+	case name == "translate" && len(args) == 2:
+		return TranslateMatrix(data.Point{args[0], args[1]}), true
+	case name == "scale" && len(args) == 1:
+		return ScaleMatrix(data.Size{args[0], args[0]}), true
+	case name == "scale" && len(args) == 2:
+		return ScaleMatrix(data.Size{args[0], args[1]}), true
+	case name == "rotate" && len(args) == 1:
+		return RotateMatrix(args[0]), true
+	case name == "rotate" && len(args) == 3:
+		pt := data.Point{args[1], args[2]}
+		return TranslateMatrix(pt).Multiply(RotateMatrix(args[0])).Multiply(TranslateMatrix(data.Point{-pt.X, -pt.Y})), true
+	case name == "skewx" && len(args) == 1:
+		return SkewXMatrix(args[0]), true
+	case name == "skewy" && len(args) == 1:
+		return SkewYMatrix(args[0]), true
+	default:
+		return IdentityMatrix, false
+	}
+}
+
+// parseNumbers returns numbers separated by whitespace or commas
+func parseNumbers(value string) ([]float32, error) {
+	result := []float32{}
+	for value = strings.TrimSpace(value); value != ""; {
+		match := reNumber.FindStringSubmatch(value)
+		if match == nil {
+			return nil, data.ErrBadParameter.WithPrefix("Invalid number: ", strconv.Quote(value))
+		}
+		if v, err := strconv.ParseFloat(match[1], 32); err != nil {
+			return nil, data.ErrBadParameter.WithPrefix("Invalid number: ", strconv.Quote(match[1]))
+		} else {
+			result = append(result, float32(v))
+		}
+		value = strings.TrimSpace(value[len(match[0]):])
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package geom_test
+
+import (
+	"testing"
+
+	data "github.com/djthorpe/data"
+	f32 "github.com/djthorpe/data/pkg/f32"
+	geom "github.com/djthorpe/data/pkg/geom"
+)
+
+func Test_Matrix_001(t *testing.T) {
+	m := geom.TranslateMatrix(data.Point{10, 20}).Multiply(geom.ScaleMatrix(data.Size{2, 3}))
+	if pt := m.Apply(data.Point{1, 1}); pt != (data.Point{12, 23}) {
+		t.Error("Unexpected point, got: ", pt)
+	}
+	if sz := m.ApplySize(data.Size{1, 1}); sz != (data.Size{2, 3}) {
+		t.Error("Unexpected size, got: ", sz)
+	}
+	if str := m.String(); str != "matrix(2,0,0,3,10,20)" {
+		t.Error("Unexpected string, got: ", str)
+	}
+	if m.IsIdentity() || geom.IdentityMatrix.IsIdentity() == false {
+		t.Error("Unexpected return from IsIdentity")
+	}
+	if pt := geom.RotateMatrix(90).Apply(data.Point{1, 0}); pointEqual(pt, data.Point{0, 1}) == false {
+		t.Error("Unexpected point, got: ", pt)
+	}
+}
+
+func Test_Matrix_002(t *testing.T) {
+	m := geom.RotateMatrix(30).Multiply(geom.SkewYMatrix(10)).Multiply(geom.TranslateMatrix(data.Point{5, -5}))
+	n, ok := m.Invert()
+	if ok == false {
+		t.Fatal("Unexpected failure from Invert")
+	}
+	for _, pt := range []data.Point{{0, 0}, {10, 20}, {-3, 7}} {
+		if pt2 := n.Apply(m.Apply(pt)); pointEqual(pt, pt2) == false {
+			t.Error("Unexpected point, got: ", pt2, " expected: ", pt)
+		}
+	}
+	if _, ok := geom.ScaleMatrix(data.Size{0, 1}).Invert(); ok {
+		t.Error("Expected failure from Invert")
+	}
+}
+
+func Test_Matrix_003(t *testing.T) {
+	for _, m := range []geom.Matrix{
+		geom.IdentityMatrix,
+		geom.TranslateMatrix(data.Point{3, 4}).Multiply(geom.RotateMatrix(45)).Multiply(geom.SkewXMatrix(20)).Multiply(geom.ScaleMatrix(data.Size{2, 0.5})),
+		geom.RotateMatrix(-120).Multiply(geom.ScaleMatrix(data.Size{1, -1})),
+		geom.SkewYMatrix(15),
+	} {
+		translate, rotate, scale, skew := m.Decompose()
+		m2 := geom.TranslateMatrix(translate).Multiply(geom.RotateMatrix(rotate)).Multiply(geom.SkewXMatrix(skew)).Multiply(geom.ScaleMatrix(scale))
+		for i := range m {
+			if f32.Abs(m[i]-m2[i]) > 0.0001 {
+				t.Error("Unexpected decomposition of ", m, ", got: ", m2)
+				break
+			}
+		}
+	}
+	if translate, rotate, scale, skew := geom.TranslateMatrix(data.Point{3, 4}).Multiply(geom.RotateMatrix(45)).Decompose(); translate != (data.Point{3, 4}) || f32.Abs(rotate-45) > 0.0001 || f32.Abs(scale.W-1) > 0.0001 || f32.Abs(scale.H-1) > 0.0001 || f32.Abs(skew) > 0.0001 {
+		t.Error("Unexpected decomposition, got: ", translate, rotate, scale, skew)
+	}
+}
+
+func Test_Matrix_004(t *testing.T) {
+	tests := []struct {
+		value string
+		m     geom.Matrix
+	}{
+		{"", geom.IdentityMatrix},
+		{"matrix(1 2 3 4 5 6)", geom.Matrix{1, 2, 3, 4, 5, 6}},
+		{"translate(10)", geom.Matrix{1, 0, 0, 1, 10, 0}},
+		{"translate(10,-5) scale(2)", geom.Matrix{2, 0, 0, 2, 10, -5}},
+		{"scale(2 3), translate(1-1)", geom.Matrix{2, 0, 0, 3, 2, -3}},
+		{"rotate(90 10 10)", geom.Matrix{0, 1, -1, 0, 20, 0}},
+		{"skewX(45)", geom.Matrix{1, 0, 1, 1, 0, 0}},
+		{"skewY(45)", geom.Matrix{1, 1, 0, 1, 0, 0}},
+	}
+	for _, test := range tests {
+		if m, err := geom.ParseTransform(test.value); err != nil {
+			t.Error(test.value, ": ", err)
+		} else {
+			for i := range m {
+				if f32.Abs(m[i]-test.m[i]) > 0.0001 {
+					t.Error(test.value, ": unexpected matrix, got: ", m)
+					break
+				}
+			}
+		}
+	}
+	for _, value := range []string{"translate", "translate(1,2,3)", "rotate(1 2)", "scale(a)", "shear(10)", "scale(2) x"} {
+		if _, err := geom.ParseTransform(value); err == nil {
+			t.Error("Expected error for ", value)
+		}
+	}
+}
+
+// pointEqual returns true if two points are within a tolerance
+func pointEqual(a, b data.Point) bool {
+	return f32.Abs(a.X-b.X) < 0.0001 && f32.Abs(a.Y-b.Y) < 0.0001
+}
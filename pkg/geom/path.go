@@ -0,0 +1,236 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/djthorpe/data"
+	"github.com/djthorpe/data/pkg/f32"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+// arc is an elliptical arc in centre parameterization
+type arc struct {
+	cx, cy, rx, ry float64
+	sin, cos       float64 // Rotation of the ellipse
+	theta, delta   float64 // Start angle and sweep angle in radians
+}
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// FlattenQuadratic returns points along a quadratic curve from p0 to p2
+// with control point p1, where tolerance is the maximum distance between
+// the curve and the lines between the points. The start point p0 is not
+// included, so that the points can be appended to a polyline
+func FlattenQuadratic(p0, p1, p2 data.Point, tolerance float32) []data.Point {
+	dd := hypot(p0.X-2*p1.X+p2.X, p0.Y-2*p1.Y+p2.Y)
+	n := segments(0.25*dd, tolerance)
+	pts := make([]data.Point, 0, n)
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		u := 1 - t
+		pts = append(pts, data.Point{
+			u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+			u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+		})
+	}
+	return pts
+}
+
+// FlattenCubic returns points along a cubic curve from p0 to p3 with
+// control points p1 and p2, where tolerance is the maximum distance
+// between the curve and the lines between the points. The start point
+// p0 is not included
+func FlattenCubic(p0, p1, p2, p3 data.Point, tolerance float32) []data.Point {
+	dd := f32.Max(
+		hypot(p0.X-2*p1.X+p2.X, p0.Y-2*p1.Y+p2.Y),
+		hypot(p1.X-2*p2.X+p3.X, p1.Y-2*p2.Y+p3.Y),
+	)
+	n := segments(0.75*dd, tolerance)
+	pts := make([]data.Point, 0, n)
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		u := 1 - t
+		pts = append(pts, data.Point{
+			u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X,
+			u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y,
+		})
+	}
+	return pts
+}
+
+// FlattenArc returns points along an elliptical arc from p0 to p1 with
+// radii r, rotated by angle in degrees, where large and sweep select one
+// of the four possible arcs as for the SVG arc command. Tolerance is the
+// maximum distance between the arc and the lines between the points. The
+// start point p0 is not included, and an arc with zero radius is a line
+func FlattenArc(p0 data.Point, r data.Size, angle float32, large, sweep bool, p1 data.Point, tolerance float32) []data.Point {
+	if p0 == p1 {
+		return []data.Point{}
+	} else if r.W == 0 || r.H == 0 {
+		return []data.Point{p1}
+	}
+	arc := newArc(p0, r, angle, large, sweep, p1)
+
+	// The distance between an arc and its chord is r(1 - cos(step/2))
+	n := 1
+	if radius := math.Max(arc.rx, arc.ry); tolerance > 0 && float64(tolerance) < radius {
+		step := 2 * math.Acos(1-float64(tolerance)/radius)
+		n = int(math.Ceil(math.Abs(arc.delta) / step))
+	}
+	pts := make([]data.Point, 0, n)
+	for i := 1; i < n; i++ {
+		x, y, _, _ := arc.point(arc.theta + arc.delta*float64(i)/float64(n))
+		pts = append(pts, data.Point{float32(x), float32(y)})
+	}
+	return append(pts, p1)
+}
+
+// ArcToCubic returns cubic curves which approximate an elliptical arc
+// from p0 to p1, where each curve spans no more than a quarter of the
+// ellipse. Each curve is returned as two control points followed by the
+// end point. An arc with zero radius or identical end points returns no
+// curves
+// Ref: https://www.w3.org/TR/SVG/implnote.html#ArcImplementationNotes
+func ArcToCubic(p0 data.Point, r data.Size, angle float32, large, sweep bool, p1 data.Point) [][]data.Point {
+	if p0 == p1 || r.W == 0 || r.H == 0 {
+		return [][]data.Point{}
+	}
+	arc := newArc(p0, r, angle, large, sweep, p1)
+
+	// Split into curves of no more than 90 degrees
+	n := int(math.Ceil(math.Abs(arc.delta)/(math.Pi/2) - 1e-6))
+	if n < 1 {
+		n = 1
+	}
+	step := arc.delta / float64(n)
+	t := 4.0 / 3.0 * math.Tan(step/4)
+	result := make([][]data.Point, 0, n)
+	for i := 0; i < n; i++ {
+		x0, y0, dx0, dy0 := arc.point(arc.theta + float64(i)*step)
+		x1, y1, dx1, dy1 := arc.point(arc.theta + float64(i+1)*step)
+		end := data.Point{float32(x1), float32(y1)}
+		if i == n-1 {
+			end = p1
+		}
+		result = append(result, []data.Point{
+			{float32(x0 + t*dx0), float32(y0 + t*dy0)},
+			{float32(x1 - t*dx1), float32(y1 - t*dy1)},
+			end,
+		})
+	}
+	return result
+}
+
+// Length returns the total length of one or more polylines. The length
+// of a polygon includes the closing line only when the first point is
+// repeated at the end
+func Length(polylines ...[]data.Point) float32 {
+	length := float32(0)
+	for _, pts := range polylines {
+		for i := 1; i < len(pts); i++ {
+			length += hypot(pts[i].X-pts[i-1].X, pts[i].Y-pts[i-1].Y)
+		}
+	}
+	return length
+}
+
+// PointAtLength returns the point at a distance along one or more
+// polylines and the direction of the tangent at the point in degrees,
+// clockwise from the x axis where the y axis points down. Distance is
+// measured along each polyline in turn, without any distance between
+// the end of one polyline and the start of the next. Returns false if
+// the distance is beyond either end
+func PointAtLength(d float32, polylines ...[]data.Point) (data.Point, float32, bool) {
+	if d < 0 {
+		return data.ZeroPoint, 0, false
+	}
+	for _, pts := range polylines {
+		for i := 1; i < len(pts); i++ {
+			p0, p1 := pts[i-1], pts[i]
+			length := hypot(p1.X-p0.X, p1.Y-p0.Y)
+			if length == 0 {
+				continue
+			} else if d <= length {
+				angle := float32(math.Atan2(float64(p1.Y-p0.Y), float64(p1.X-p0.X)) * 180 / math.Pi)
+				return data.Point{p0.X + (p1.X-p0.X)*d/length, p0.Y + (p1.Y-p0.Y)*d/length}, angle, true
+			}
+			d -= length
+		}
+	}
+	return data.ZeroPoint, 0, false
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// newArc returns the centre parameterization of an elliptical arc from
+// p0 to p1, scaling up radii which are too small to reach p1
+func newArc(p0 data.Point, r data.Size, angle float32, large, sweep bool, p1 data.Point) *arc {
+	this := &arc{rx: math.Abs(float64(r.W)), ry: math.Abs(float64(r.H))}
+
+	// Compute the transformed mid-point
+	phi := float64(angle) * math.Pi / 180
+	this.sin, this.cos = math.Sin(phi), math.Cos(phi)
+	dx, dy := float64(p0.X-p1.X)/2, float64(p0.Y-p1.Y)/2
+	x1 := this.cos*dx + this.sin*dy
+	y1 := -this.sin*dx + this.cos*dy
+
+	// Scale up radii which are too small
+	if lambda := (x1*x1)/(this.rx*this.rx) + (y1*y1)/(this.ry*this.ry); lambda > 1 {
+		this.rx, this.ry = this.rx*math.Sqrt(lambda), this.ry*math.Sqrt(lambda)
+	}
+
+	// Compute the centre
+	rx, ry := this.rx, this.ry
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := math.Sqrt(math.Max(num, 0) / den)
+	if large == sweep {
+		k = -k
+	}
+	cx1, cy1 := k*rx*y1/ry, -k*ry*x1/rx
+	this.cx = this.cos*cx1 - this.sin*cy1 + float64(p0.X+p1.X)/2
+	this.cy = this.sin*cx1 + this.cos*cy1 + float64(p0.Y+p1.Y)/2
+
+	// Compute start and sweep angles
+	this.theta = math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	this.delta = math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - this.theta
+	if sweep && this.delta < 0 {
+		this.delta += 2 * math.Pi
+	} else if sweep == false && this.delta > 0 {
+		this.delta -= 2 * math.Pi
+	}
+
+	// Return the arc
+	return this
+}
+
+// point returns a point on the ellipse at an angle in radians, and the
+// derivative at the point
+func (this *arc) point(a float64) (float64, float64, float64, float64) {
+	sa, ca := math.Sin(a), math.Cos(a)
+	x := this.cx + this.rx*ca*this.cos - this.ry*sa*this.sin
+	y := this.cy + this.rx*ca*this.sin + this.ry*sa*this.cos
+	dx := -this.rx*sa*this.cos - this.ry*ca*this.sin
+	dy := -this.rx*sa*this.sin + this.ry*ca*this.cos
+	return x, y, dx, dy
+}
+
+// segments returns the number of lines which approximate a curve, where
+// dd is the maximum second difference of the control points
+func segments(dd, tolerance float32) int {
+	if tolerance <= 0 {
+		return 1
+	} else if n := int(f32.Ceil(f32.Sqrt(dd / tolerance))); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// hypot returns the length of the vector (x,y)
+func hypot(x, y float32) float32 {
+	return f32.Sqrt(x*x + y*y)
+}
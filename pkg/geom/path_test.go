@@ -0,0 +1,105 @@
+package geom_test
+
+import (
+	"math"
+	"testing"
+
+	data "github.com/djthorpe/data"
+	geom "github.com/djthorpe/data/pkg/geom"
+)
+
+func Test_Path_001(t *testing.T) {
+	// Points on curves are within the tolerance and end at the end point
+	p0, p1, p2, p3 := data.Point{0, 0}, data.Point{0, 100}, data.Point{100, 100}, data.Point{100, 0}
+	for _, tolerance := range []float32{1, 0.1, 0.01} {
+		quad := geom.FlattenQuadratic(p0, p1, p3, tolerance)
+		cubic := geom.FlattenCubic(p0, p1, p2, p3, tolerance)
+		if quad[len(quad)-1] != p3 || cubic[len(cubic)-1] != p3 {
+			t.Error("Unexpected end points")
+		}
+		if len(geom.FlattenQuadratic(p0, p1, p3, tolerance/10)) <= len(quad) {
+			t.Error("Expected more points with a smaller tolerance")
+		}
+		// The maximum height of the cubic curve is 75
+		max := float32(0)
+		for _, pt := range cubic {
+			if pt.Y > max {
+				max = pt.Y
+			}
+		}
+		if max < 75-tolerance || max > 75 {
+			t.Error("Unexpected maximum, got: ", max)
+		}
+	}
+	if pts := geom.FlattenQuadratic(p0, data.Point{50, 0}, p3, 1); len(pts) != 1 || pts[0] != p3 {
+		t.Error("Expected straight line, got: ", pts)
+	}
+}
+
+func Test_Path_002(t *testing.T) {
+	// A semi-circle with radius 50
+	p0, p1, r := data.Point{0, 50}, data.Point{100, 50}, data.Size{50, 50}
+	for _, tolerance := range []float32{1, 0.1, 0.01} {
+		pts := geom.FlattenArc(p0, r, 0, false, true, p1, tolerance)
+		if pts[len(pts)-1] != p1 {
+			t.Error("Unexpected end point, got: ", pts[len(pts)-1])
+		}
+		for _, pt := range pts {
+			if d := math.Hypot(float64(pt.X-50), float64(pt.Y-50)); d < 50-1e-3 || d > 50+1e-3 {
+				t.Error("Unexpected distance from centre, got: ", d)
+			}
+			if pt.Y > 50 {
+				t.Error("Expected arc above the centre, got: ", pt)
+			}
+		}
+		if length := geom.Length(append([]data.Point{p0}, pts...)); length > 50*math.Pi || length < 50*math.Pi-10*tolerance {
+			t.Error("Unexpected length, got: ", length)
+		}
+	}
+
+	// Zero radius is a line, radii which are too small are scaled up
+	if pts := geom.FlattenArc(p0, data.Size{0, 50}, 0, false, true, p1, 1); len(pts) != 1 || pts[0] != p1 {
+		t.Error("Expected line, got: ", pts)
+	}
+	if pts := geom.FlattenArc(p0, data.Size{10, 10}, 0, false, false, p1, 0.1); len(pts) < 2 {
+		t.Error("Expected arc, got: ", pts)
+	} else if length := geom.Length(append([]data.Point{p0}, pts...)); length < 156 || length > 157.1 {
+		t.Error("Unexpected length, got: ", length)
+	}
+
+	// Cubic curves end at the end point and span no more than 90 degrees
+	if curves := geom.ArcToCubic(p0, r, 0, true, true, p1); len(curves) != 2 || curves[1][2] != p1 {
+		t.Error("Unexpected curves, got: ", curves)
+	}
+	if curves := geom.ArcToCubic(p0, r, 0, true, true, p0); len(curves) != 0 {
+		t.Error("Unexpected curves, got: ", curves)
+	}
+}
+
+func Test_Path_003(t *testing.T) {
+	a := []data.Point{{0, 0}, {10, 0}, {10, 10}}
+	b := []data.Point{{20, 20}, {20, 20}, {10, 20}}
+	if length := geom.Length(a, b); length != 30 {
+		t.Error("Unexpected length, got: ", length)
+	}
+	tests := []struct {
+		d     float32
+		pt    data.Point
+		angle float32
+		ok    bool
+	}{
+		{0, data.Point{0, 0}, 0, true},
+		{5, data.Point{5, 0}, 0, true},
+		{15, data.Point{10, 5}, 90, true},
+		{25, data.Point{15, 20}, 180, true},
+		{30, data.Point{10, 20}, 180, true},
+		{31, data.ZeroPoint, 0, false},
+		{-1, data.ZeroPoint, 0, false},
+	}
+	for _, test := range tests {
+		pt, angle, ok := geom.PointAtLength(test.d, a, b)
+		if ok != test.ok || pt != test.pt || angle != test.angle {
+			t.Error("Unexpected point at ", test.d, ", got: ", pt, angle, ok)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package geom
+
+import (
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// METHODS
+
+// Winding returns the winding number of one or more polygons around a
+// point, which is the number of times the polygons wind clockwise around
+// the point less the number of times they wind anti-clockwise, where the
+// y axis points down. Polygons are closed implicitly
+func Winding(pt data.Point, polys ...[]data.Point) int {
+	winding := 0
+	for _, poly := range polys {
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+			if a.Y <= pt.Y {
+				if b.Y > pt.Y && side(a, b, pt) > 0 {
+					winding++
+				}
+			} else if b.Y <= pt.Y && side(a, b, pt) < 0 {
+				winding--
+			}
+		}
+	}
+	return winding
+}
+
+// Contains returns true if a point is inside one or more polygons which
+// are filled according to a fill rule
+func Contains(pt data.Point, rule data.FillRule, polys ...[]data.Point) bool {
+	winding := Winding(pt, polys...)
+	if rule == data.EvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// Area returns the signed area of a polygon, which is positive when the
+// polygon is clockwise where the y axis points down
+func Area(poly []data.Point) float32 {
+	var area float32
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// side returns a positive value when a point is to the left of the line
+// from a to b, negative when to the right, and zero when on the line
+func side(a, b, pt data.Point) float32 {
+	return (b.X-a.X)*(pt.Y-a.Y) - (pt.X-a.X)*(b.Y-a.Y)
+}
@@ -0,0 +1,48 @@
+package geom_test
+
+import (
+	"testing"
+
+	data "github.com/djthorpe/data"
+	geom "github.com/djthorpe/data/pkg/geom"
+)
+
+func Test_Polygon_001(t *testing.T) {
+	outer := []data.Point{{0, 0}, {30, 0}, {30, 30}, {0, 30}}
+	inner := []data.Point{{10, 10}, {20, 10}, {20, 20}, {10, 20}}
+	reversed := []data.Point{{10, 10}, {10, 20}, {20, 20}, {20, 10}}
+	if w := geom.Winding(data.Point{5, 5}, outer); w != 1 {
+		t.Error("Unexpected winding, got: ", w)
+	}
+	if w := geom.Winding(data.Point{15, 15}, outer, inner); w != 2 {
+		t.Error("Unexpected winding, got: ", w)
+	}
+	if w := geom.Winding(data.Point{15, 15}, outer, reversed); w != 0 {
+		t.Error("Unexpected winding, got: ", w)
+	}
+	if w := geom.Winding(data.Point{40, 15}, outer); w != 0 {
+		t.Error("Unexpected winding, got: ", w)
+	}
+	if geom.Contains(data.Point{15, 15}, data.NonZero, outer, inner) == false {
+		t.Error("Expected point inside with non-zero rule")
+	}
+	if geom.Contains(data.Point{15, 15}, data.EvenOdd, outer, inner) {
+		t.Error("Expected point outside with even-odd rule")
+	}
+	if geom.Contains(data.Point{5, 5}, data.EvenOdd, outer, inner) == false {
+		t.Error("Expected point inside with even-odd rule")
+	}
+}
+
+func Test_Polygon_002(t *testing.T) {
+	square := []data.Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	if a := geom.Area(square); a != 100 {
+		t.Error("Unexpected area, got: ", a)
+	}
+	if a := geom.Area([]data.Point{{0, 0}, {0, 10}, {10, 10}, {10, 0}}); a != -100 {
+		t.Error("Unexpected area, got: ", a)
+	}
+	if a := geom.Area(nil); a != 0 {
+		t.Error("Unexpected area, got: ", a)
+	}
+}
@@ -0,0 +1,487 @@
+package stylesheet
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/djthorpe/data"
+)
+
+/////////////////////////////////////////////////////////////////////
+// TYPES
+
+type StyleSheet struct {
+	rules    []*Rule
+	unparsed []unparsed
+}
+
+type Rule struct {
+	tag, class, id, state string
+	name, value           string
+	important             bool
+}
+
+// unparsed is CSS which is read but not parsed into rules, such as an
+// at-rule, which is written before the rule at an index
+type unparsed struct {
+	index int
+	css   string
+}
+
+/////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// NewStyleSheet returns an empty style sheet
+func NewStyleSheet() data.StyleSheet {
+	return new(StyleSheet)
+}
+
+/////////////////////////////////////////////////////////////////////
+// STYLESHEET METHODS
+
+// Read parses CSS from a data stream and appends the rules to the style
+// sheet. Each selector is a tag name or "*" followed by an optional id,
+// class and pseudo-class, and selectors can be separated by commas.
+// Comments are ignored. At-rules such as @import and @media, and rule
+// sets for other selectors, are skipped but retained when the style
+// sheet is written. Returns ErrBadParameter if the CSS cannot be parsed,
+// in which case no rules are appended
+func (this *StyleSheet) Read(r io.Reader) error {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	css := stripComments(string(buf))
+	rules := []*Rule{}
+	skipped := []unparsed{}
+	for {
+		css = strings.TrimSpace(css)
+		if css == "" {
+			break
+		}
+
+		// Skip at-rules, which end with a semi-colon or a block
+		if strings.HasPrefix(css, "@") {
+			if end := atRuleEnd(css); end < 0 {
+				return data.ErrBadParameter.WithPrefix("Read: Unterminated at-rule")
+			} else {
+				skipped = append(skipped, unparsed{len(this.rules) + len(rules), css[:end]})
+				css = css[end:]
+			}
+			continue
+		}
+
+		// Parse a rule set
+		open := strings.Index(css, "{")
+		if open < 0 {
+			return data.ErrBadParameter.WithPrefix("Read: Missing '{' after ", strconv.Quote(css))
+		}
+		close := blockEnd(css, open)
+		if close < 0 {
+			return data.ErrBadParameter.WithPrefix("Read: Missing '}' after ", strconv.Quote(css[:open]))
+		}
+		decls := css[open+1 : close]
+		if _, err := parseDeclarations(decls); err != nil {
+			return data.ErrBadParameter.WithPrefix("Read: ", err)
+		}
+		for _, selector := range strings.Split(css[:open], ",") {
+			if strings.TrimSpace(selector) == "" {
+				continue
+			} else if rules_, err := newRules(selector, decls, true); err != nil {
+				// Skip selectors which are not supported
+				skipped = append(skipped, unparsed{len(this.rules) + len(rules), strings.TrimSpace(selector) + " {" + decls + "}"})
+			} else {
+				rules = append(rules, rules_...)
+			}
+		}
+		css = css[close+1:]
+	}
+
+	// Append the rules
+	this.rules = append(this.rules, rules...)
+	this.unparsed = append(this.unparsed, skipped...)
+
+	// Return success
+	return nil
+}
+
+// Write outputs the rules as CSS, one line for each selector
+func (this *StyleSheet) Write(w io.Writer) error {
+	_, err := io.WriteString(w, this.String())
+	return err
+}
+
+// Rules returns rules in the order they were added. Where an argument
+// is not empty, only rules which are either not qualified by the
+// argument or qualified by the same value are returned. The class can
+// contain several classes separated by whitespace
+func (this *StyleSheet) Rules(tag, class, id, state string) []data.StyleRule {
+	classes := strings.Fields(class)
+	result := []data.StyleRule{}
+	for _, rule := range this.rules {
+		if tag != "" && rule.tag != "" && rule.tag != tag {
+			continue
+		}
+		if id != "" && rule.id != "" && rule.id != id {
+			continue
+		}
+		if state != "" && rule.state != "" && rule.state != state {
+			continue
+		}
+		if len(classes) != 0 && rule.class != "" && containsString(classes, rule.class) == false {
+			continue
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// Add appends rules for one or more selectors separated by commas, with
+// declarations "<name>:<value>; <name>:<value>". A value can end with
+// "!important"
+func (this *StyleSheet) Add(selector, declarations string) error {
+	if rules, err := newRules(selector, declarations, false); err != nil {
+		return data.ErrBadParameter.WithPrefix("Add: ", err)
+	} else {
+		this.rules = append(this.rules, rules...)
+	}
+
+	// Return success
+	return nil
+}
+
+// Unparsed returns the at-rules and rule sets which were read but not
+// parsed into rules
+func (this *StyleSheet) Unparsed() []string {
+	result := make([]string, 0, len(this.unparsed))
+	for _, css := range this.unparsed {
+		result = append(result, css.css)
+	}
+	return result
+}
+
+/////////////////////////////////////////////////////////////////////
+// RULE METHODS
+
+func (this *Rule) Tag() string {
+	return this.tag
+}
+
+func (this *Rule) Class() string {
+	return this.class
+}
+
+func (this *Rule) Id() string {
+	return this.id
+}
+
+func (this *Rule) State() string {
+	return this.state
+}
+
+func (this *Rule) Name() string {
+	return this.name
+}
+
+func (this *Rule) Value() interface{} {
+	return this.value
+}
+
+func (this *Rule) Important() bool {
+	return this.important
+}
+
+// Selector returns the selector for the rule in CSS syntax
+func (this *Rule) Selector() string {
+	selector := this.tag
+	if this.id != "" {
+		selector += "#" + this.id
+	}
+	if this.class != "" {
+		selector += "." + this.class
+	}
+	if this.state != "" {
+		selector += ":" + this.state
+	}
+	if selector == "" {
+		return "*"
+	}
+	return selector
+}
+
+/////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *StyleSheet) String() string {
+	var b strings.Builder
+	for i, rule := range this.rules {
+		// Unparsed CSS is written in the order it was read
+		unparsed := this.writeUnparsed(&b, i)
+
+		// Rules with the same selector are written in one block
+		selector := rule.Selector()
+		if i == 0 || unparsed || this.rules[i-1].Selector() != selector {
+			b.WriteString(selector + " {")
+		}
+		b.WriteString(" " + rule.name + ": " + rule.value)
+		if rule.important {
+			b.WriteString(" !important")
+		}
+		b.WriteString(";")
+		if i == len(this.rules)-1 || this.hasUnparsed(i+1) || this.rules[i+1].Selector() != selector {
+			b.WriteString(" }\n")
+		}
+	}
+	this.writeUnparsed(&b, len(this.rules))
+	return b.String()
+}
+
+func (this *Rule) String() string {
+	str := "<rule"
+	str += fmt.Sprintf(" selector=%q", this.Selector())
+	str += fmt.Sprintf(" %v=%q", this.name, this.value)
+	if this.important {
+		str += " important"
+	}
+	return str + ">"
+}
+
+/////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// hasUnparsed returns true if there is unparsed CSS before the rule at
+// an index
+func (this *StyleSheet) hasUnparsed(index int) bool {
+	for _, css := range this.unparsed {
+		if css.index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// writeUnparsed writes the unparsed CSS before the rule at an index,
+// one line each, and returns true if any CSS was written
+func (this *StyleSheet) writeUnparsed(b *strings.Builder, index int) bool {
+	result := false
+	for _, css := range this.unparsed {
+		if css.index == index {
+			b.WriteString(css.css + "\n")
+			result = true
+		}
+	}
+	return result
+}
+
+// newRules returns a rule for each combination of selector and
+// declaration. When empty is true, a rule set without declarations
+// is allowed
+func newRules(selectors, declarations string, empty bool) ([]*Rule, error) {
+	decls, err := parseDeclarations(declarations)
+	if err != nil {
+		return nil, err
+	} else if len(decls) == 0 && empty == false {
+		return nil, fmt.Errorf("No declarations for %q", strings.TrimSpace(selectors))
+	}
+	rules := []*Rule{}
+	for _, selector := range strings.Split(selectors, ",") {
+		if proto, err := parseSelector(selector); err != nil {
+			return nil, err
+		} else {
+			for _, decl := range decls {
+				rule := *proto
+				rule.name, rule.value, rule.important = decl.name, decl.value, decl.important
+				rules = append(rules, &rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// parseSelector returns a rule without a declaration from a simple
+// selector, which is a tag or "*" followed by an optional id, class
+// and pseudo-class in any order
+func parseSelector(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("Empty selector")
+	}
+	rule := new(Rule)
+	i := identEnd(value, 0)
+	if i == 0 && strings.HasPrefix(value, "*") {
+		i = 1
+	} else {
+		rule.tag = value[:i]
+	}
+	for i < len(value) {
+		prefix := value[i]
+		j := identEnd(value, i+1)
+		if j == i+1 {
+			return nil, fmt.Errorf("Unsupported selector %q", value)
+		}
+		var field *string
+		switch prefix {
+		case '#':
+			field = &rule.id
+		case '.':
+			field = &rule.class
+		case ':':
+			field = &rule.state
+		default:
+			return nil, fmt.Errorf("Unsupported selector %q", value)
+		}
+		if *field != "" {
+			return nil, fmt.Errorf("Unsupported selector %q", value)
+		}
+		*field = value[i+1 : j]
+		i = j
+	}
+	return rule, nil
+}
+
+type declaration struct {
+	name, value string
+	important   bool
+}
+
+// parseDeclarations returns the declarations separated by semi-colons,
+// where semi-colons within quotes or parentheses do not separate
+// declarations
+func parseDeclarations(value string) ([]declaration, error) {
+	result := []declaration{}
+	for _, decl := range splitOutside(value, ';') {
+		if decl = strings.TrimSpace(decl); decl == "" {
+			continue
+		}
+		kv := strings.SplitN(decl, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid declaration %q", decl)
+		}
+		name := strings.ToLower(strings.TrimSpace(kv[0]))
+		value := strings.TrimSpace(kv[1])
+		important := false
+		if i := strings.LastIndex(value, "!"); i >= 0 && strings.EqualFold(strings.TrimSpace(value[i+1:]), "important") {
+			value, important = strings.TrimSpace(value[:i]), true
+		}
+		if name == "" || identEnd(name, 0) != len(name) {
+			return nil, fmt.Errorf("Invalid property %q", name)
+		} else if value == "" {
+			return nil, fmt.Errorf("Missing value for %q", name)
+		}
+		result = append(result, declaration{name, value, important})
+	}
+	return result, nil
+}
+
+// splitOutside splits a string by a separator which is not within
+// quotes or parentheses
+func splitOutside(value string, sep byte) []string {
+	result := []string{}
+	depth, quote, start := 0, byte(0), 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == sep && depth == 0:
+			result = append(result, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, value[start:])
+}
+
+// blockEnd returns the index of the brace which closes the block
+// opened at index open, or -1 if the block is not closed
+func blockEnd(value string, open int) int {
+	depth, quote := 0, byte(0)
+	for i := open; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// atRuleEnd returns the index after an at-rule, which ends with either
+// a semi-colon or a block, or -1 if the at-rule is not terminated
+func atRuleEnd(value string) int {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case ';':
+			return i + 1
+		case '{':
+			if end := blockEnd(value, i); end >= 0 {
+				return end + 1
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// stripComments removes comments from CSS
+func stripComments(value string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "/*")
+		if start < 0 {
+			break
+		}
+		b.WriteString(value[:start])
+		if end := strings.Index(value[start+2:], "*/"); end < 0 {
+			value = ""
+		} else {
+			value = " " + value[start+2+end+2:]
+		}
+	}
+	b.WriteString(value)
+	return b.String()
+}
+
+// identEnd returns the index after an identifier which starts at index
+// i, which is i when there is no identifier
+func identEnd(value string, i int) int {
+	for ; i < len(value); i++ {
+		c := value[i]
+		if c == '-' || c == '_' || c >= 0x80 || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			continue
+		}
+		break
+	}
+	return i
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package stylesheet_test
+
+import (
+	"strings"
+	"testing"
+
+	stylesheet "github.com/djthorpe/data/pkg/stylesheet"
+)
+
+func Test_StyleSheet_001(t *testing.T) {
+	s := stylesheet.NewStyleSheet()
+	if s == nil {
+		t.Fatal("Unexpected nil style sheet")
+	}
+	if err := s.Add("line.majorx", "stroke: red; stroke-width: 2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("rect#border:hover, *", "fill: url(#grad) !important"); err != nil {
+		t.Fatal(err)
+	}
+	if rules := s.Rules("", "", "", ""); len(rules) != 4 {
+		t.Fatal("Unexpected rules, got: ", rules)
+	} else if rules[2].Tag() != "rect" || rules[2].Id() != "border" || rules[2].State() != "hover" || rules[2].Class() != "" {
+		t.Error("Unexpected selector, got: ", rules[2])
+	} else if rules[2].Name() != "fill" || rules[2].Value() != "url(#grad)" || rules[2].Important() == false {
+		t.Error("Unexpected declaration, got: ", rules[2])
+	} else if rules[3].Tag() != "" || rules[3].Class() != "" {
+		t.Error("Unexpected selector, got: ", rules[3])
+	}
+
+	// Match rules by tag and class
+	if rules := s.Rules("line", "minorx majorx", "", ""); len(rules) != 3 {
+		t.Error("Unexpected rules, got: ", rules)
+	} else if rules[0].Name() != "stroke" || rules[1].Value() != "2" {
+		t.Error("Unexpected rules, got: ", rules)
+	}
+	if rules := s.Rules("line", "minorx", "", ""); len(rules) != 1 {
+		t.Error("Unexpected rules, got: ", rules)
+	}
+	if rules := s.Rules("rect", "", "other", ""); len(rules) != 1 {
+		t.Error("Unexpected rules, got: ", rules)
+	}
+}
+
+func Test_StyleSheet_002(t *testing.T) {
+	s := stylesheet.NewStyleSheet()
+	for _, selector := range []string{"", "g line", "g > line", "line.a.b", "a[href]", "p::before", "#", "line,"} {
+		if err := s.Add(selector, "fill: red"); err == nil {
+			t.Error("Expected error for selector: ", selector)
+		}
+	}
+	for _, decls := range []string{"", "fill", "fill:", ": red", "fill red; stroke: blue"} {
+		if err := s.Add("rect", decls); err == nil {
+			t.Error("Expected error for declarations: ", decls)
+		}
+	}
+	if rules := s.Rules("", "", "", ""); len(rules) != 0 {
+		t.Error("Unexpected rules, got: ", rules)
+	}
+}
+
+func Test_StyleSheet_003(t *testing.T) {
+	css := `
+		@import url("other.css");
+		/* Graph paper */
+		.majorx, .majory { stroke: #ccc; stroke-width: 0.5 }
+		@media print { .minorx { display: none } }
+		text { font-family: "Helvetica; Arial"; fill: black !IMPORTANT; }
+		rect:hover {}
+	`
+	s := stylesheet.NewStyleSheet()
+	if err := s.Read(strings.NewReader(css)); err != nil {
+		t.Fatal(err)
+	}
+	rules := s.Rules("", "", "", "")
+	if len(rules) != 6 {
+		t.Fatal("Unexpected rules, got: ", rules)
+	} else if rules[4].Value() != `"Helvetica; Arial"` {
+		t.Error("Unexpected value, got: ", rules[4].Value())
+	} else if rules[5].Value() != "black" || rules[5].Important() == false {
+		t.Error("Unexpected value, got: ", rules[5])
+	}
+
+	// Write and read back
+	b := new(strings.Builder)
+	if err := s.Write(b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); strings.Contains(str, ".majorx { stroke: #ccc; stroke-width: 0.5; }\n") == false {
+		t.Error("Unexpected output, got: ", str)
+	}
+	s2 := stylesheet.NewStyleSheet()
+	if err := s2.Read(strings.NewReader(b.String())); err != nil {
+		t.Fatal(err)
+	} else if rules2 := s2.Rules("", "", "", ""); len(rules2) != len(rules) {
+		t.Error("Unexpected rules, got: ", rules2)
+	} else {
+		for i := range rules {
+			if rules[i].Name() != rules2[i].Name() || rules[i].Value() != rules2[i].Value() || rules[i].Important() != rules2[i].Important() {
+				t.Error("Unexpected rule, got: ", rules2[i])
+			}
+		}
+	}
+}
+
+func Test_StyleSheet_004(t *testing.T) {
+	for _, css := range []string{"rect { fill: red", "rect fill: red }", "@import url(x.css)", "rect { fill }", "g rect { fill red }"} {
+		s := stylesheet.NewStyleSheet()
+		if err := s.Read(strings.NewReader(css)); err == nil {
+			t.Error("Expected error for: ", css)
+		} else if rules := s.Rules("", "", "", ""); len(rules) != 0 {
+			t.Error("Unexpected rules, got: ", rules)
+		}
+	}
+}
+
+func Test_StyleSheet_005(t *testing.T) {
+	// Rule sets with unsupported selectors are skipped, and written in
+	// the order they were read
+	css := `rect { fill: red } .a .b, line { stroke: blue } @media print { rect { fill: none } } g > rect { fill: green } circle { r: 1 }`
+	s := stylesheet.NewStyleSheet()
+	if err := s.Read(strings.NewReader(css)); err != nil {
+		t.Fatal(err)
+	}
+	if rules := s.Rules("", "", "", ""); len(rules) != 3 {
+		t.Fatal("Unexpected rules, got: ", rules)
+	} else if rules[1].Tag() != "line" {
+		t.Error("Unexpected rule, got: ", rules[1])
+	}
+	if unparsed := s.(*stylesheet.StyleSheet).Unparsed(); len(unparsed) != 3 {
+		t.Error("Unexpected unparsed CSS, got: ", unparsed)
+	}
+	b := new(strings.Builder)
+	if err := s.Write(b); err != nil {
+		t.Fatal(err)
+	} else if str := b.String(); str != "rect { fill: red; }\n.a .b { stroke: blue }\nline { stroke: blue; }\n@media print { rect { fill: none } }\ng > rect { fill: green }\ncircle { r: 1; }\n" {
+		t.Error("Unexpected output, got: ", str)
+	}
+}
@@ -33,4 +33,7 @@ type StyleRule interface {
 	// Return the style name and value
 	Name() string
 	Value() interface{}
+
+	// Return true if the value was declared with "!important"
+	Important() bool
 }